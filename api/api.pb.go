@@ -9,7 +9,9 @@ package api
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -83,10 +85,13 @@ func (x *Upstream) GetLogin() string {
 }
 
 type AddUpstreamRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Upstream      *Upstream              `protobuf:"bytes,1,req,name=upstream" json:"upstream,omitempty"`
-	Password      *string                `protobuf:"bytes,3,req,name=password" json:"password,omitempty"`
-	Script        *string                `protobuf:"bytes,4,opt,name=script" json:"script,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Upstream *Upstream              `protobuf:"bytes,1,req,name=upstream" json:"upstream,omitempty"`
+	Password *string                `protobuf:"bytes,3,req,name=password" json:"password,omitempty"`
+	Script   *string                `protobuf:"bytes,4,opt,name=script" json:"script,omitempty"`
+	// "text" for a command with %LOGIN% and %PASSWORD% in it, "steps" or
+	// "starlark".
+	ScriptType    *string `protobuf:"bytes,5,opt,name=script_type,json=scriptType" json:"script_type,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -142,6 +147,13 @@ func (x *AddUpstreamRequest) GetScript() string {
 	return ""
 }
 
+func (x *AddUpstreamRequest) GetScriptType() string {
+	if x != nil && x.ScriptType != nil {
+		return *x.ScriptType
+	}
+	return ""
+}
+
 type EditUpstreamRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
@@ -151,6 +163,7 @@ type EditUpstreamRequest struct {
 	Address       *string                `protobuf:"bytes,5,opt,name=address" json:"address,omitempty"`
 	Login         *string                `protobuf:"bytes,6,opt,name=login" json:"login,omitempty"`
 	Script        *string                `protobuf:"bytes,7,opt,name=script" json:"script,omitempty"`
+	ScriptType    *string                `protobuf:"bytes,8,opt,name=script_type,json=scriptType" json:"script_type,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -234,6 +247,13 @@ func (x *EditUpstreamRequest) GetScript() string {
 	return ""
 }
 
+func (x *EditUpstreamRequest) GetScriptType() string {
+	if x != nil && x.ScriptType != nil {
+		return *x.ScriptType
+	}
+	return ""
+}
+
 type ListUpstreamsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Upstreams     []*Upstream            `protobuf:"bytes,1,rep,name=upstreams" json:"upstreams,omitempty"`
@@ -278,33 +298,2892 @@ func (x *ListUpstreamsResponse) GetUpstreams() []*Upstream {
 	return nil
 }
 
-var File_api_proto protoreflect.FileDescriptor
+type TailRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	Follow        *bool                  `protobuf:"varint,2,opt,name=follow" json:"follow,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_api_proto_rawDesc = "" +
-	"\n" +
-	"\tapi.proto\x1a\x1bgoogle/protobuf/empty.proto\"N\n" +
-	"\bUpstream\x12\x12\n" +
-	"\x04name\x18\x01 \x02(\tR\x04name\x12\x18\n" +
-	"\aaddress\x18\x02 \x02(\tR\aaddress\x12\x14\n" +
-	"\x05login\x18\x03 \x02(\tR\x05login\"o\n" +
-	"\x12AddUpstreamRequest\x12%\n" +
-	"\bupstream\x18\x01 \x02(\v2\t.UpstreamR\bupstream\x12\x1a\n" +
-	"\bpassword\x18\x03 \x02(\tR\bpassword\x12\x16\n" +
-	"\x06script\x18\x04 \x01(\tR\x06script\"\xcb\x01\n" +
-	"\x13EditUpstreamRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x02(\tR\x04name\x12\x1a\n" +
-	"\bpassword\x18\x02 \x02(\tR\bpassword\x12\x19\n" +
-	"\bnew_name\x18\x03 \x01(\tR\anewName\x12!\n" +
-	"\fnew_password\x18\x04 \x01(\tR\vnewPassword\x12\x18\n" +
-	"\aaddress\x18\x05 \x01(\tR\aaddress\x12\x14\n" +
-	"\x05login\x18\x06 \x01(\tR\x05login\x12\x16\n" +
-	"\x06script\x18\a \x01(\tR\x06script\"@\n" +
-	"\x15ListUpstreamsResponse\x12'\n" +
-	"\tupstreams\x18\x01 \x03(\v2\t.UpstreamR\tupstreams2\xcc\x01\n" +
-	"\tUpstreams\x12<\n" +
-	"\vAddUpstream\x12\x13.AddUpstreamRequest\x1a\x16.google.protobuf.Empty\"\x00\x12>\n" +
-	"\fEditUpstream\x12\x14.EditUpstreamRequest\x1a\x16.google.protobuf.Empty\"\x00\x12A\n" +
-	"\rListUpstreams\x12\x16.google.protobuf.Empty\x1a\x16.ListUpstreamsResponse\"\x00B\x1cZ\x1agithub.com/stesla/iris/api"
+func (x *TailRequest) Reset() {
+	*x = TailRequest{}
+	mi := &file_api_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TailRequest) ProtoMessage() {}
+
+func (x *TailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TailRequest.ProtoReflect.Descriptor instead.
+func (*TailRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TailRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *TailRequest) GetFollow() bool {
+	if x != nil && x.Follow != nil {
+		return *x.Follow
+	}
+	return false
+}
+
+type LogData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,req,name=data" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogData) Reset() {
+	*x = LogData{}
+	mi := &file_api_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogData) ProtoMessage() {}
+
+func (x *LogData) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogData.ProtoReflect.Descriptor instead.
+func (*LogData) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LogData) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Scene struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,req,name=upstream" json:"upstream,omitempty"`
+	Name          *string                `protobuf:"bytes,2,req,name=name" json:"name,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,3,req,name=started_at,json=startedAt" json:"started_at,omitempty"`
+	StoppedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=stopped_at,json=stoppedAt" json:"stopped_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Scene) Reset() {
+	*x = Scene{}
+	mi := &file_api_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Scene) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Scene) ProtoMessage() {}
+
+func (x *Scene) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Scene.ProtoReflect.Descriptor instead.
+func (*Scene) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Scene) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *Scene) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *Scene) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Scene) GetStoppedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StoppedAt
+	}
+	return nil
+}
+
+type ListScenesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,opt,name=upstream" json:"upstream,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScenesRequest) Reset() {
+	*x = ListScenesRequest{}
+	mi := &file_api_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScenesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScenesRequest) ProtoMessage() {}
+
+func (x *ListScenesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScenesRequest.ProtoReflect.Descriptor instead.
+func (*ListScenesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListScenesRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+type ListScenesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scenes        []*Scene               `protobuf:"bytes,1,rep,name=scenes" json:"scenes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScenesResponse) Reset() {
+	*x = ListScenesResponse{}
+	mi := &file_api_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScenesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScenesResponse) ProtoMessage() {}
+
+func (x *ListScenesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScenesResponse.ProtoReflect.Descriptor instead.
+func (*ListScenesResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListScenesResponse) GetScenes() []*Scene {
+	if x != nil {
+		return x.Scenes
+	}
+	return nil
+}
+
+type Trigger struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *int64                 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Upstream      *string                `protobuf:"bytes,2,req,name=upstream" json:"upstream,omitempty"`
+	Pattern       *string                `protobuf:"bytes,3,req,name=pattern" json:"pattern,omitempty"`
+	Action        *string                `protobuf:"bytes,4,req,name=action" json:"action,omitempty"`
+	Argument      *string                `protobuf:"bytes,5,opt,name=argument" json:"argument,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trigger) Reset() {
+	*x = Trigger{}
+	mi := &file_api_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trigger) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trigger) ProtoMessage() {}
+
+func (x *Trigger) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trigger.ProtoReflect.Descriptor instead.
+func (*Trigger) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Trigger) GetId() int64 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+func (x *Trigger) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *Trigger) GetPattern() string {
+	if x != nil && x.Pattern != nil {
+		return *x.Pattern
+	}
+	return ""
+}
+
+func (x *Trigger) GetAction() string {
+	if x != nil && x.Action != nil {
+		return *x.Action
+	}
+	return ""
+}
+
+func (x *Trigger) GetArgument() string {
+	if x != nil && x.Argument != nil {
+		return *x.Argument
+	}
+	return ""
+}
+
+type RemoveTriggerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *int64                 `protobuf:"varint,1,req,name=id" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveTriggerRequest) Reset() {
+	*x = RemoveTriggerRequest{}
+	mi := &file_api_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveTriggerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveTriggerRequest) ProtoMessage() {}
+
+func (x *RemoveTriggerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveTriggerRequest.ProtoReflect.Descriptor instead.
+func (*RemoveTriggerRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RemoveTriggerRequest) GetId() int64 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+type ListTriggersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,opt,name=upstream" json:"upstream,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTriggersRequest) Reset() {
+	*x = ListTriggersRequest{}
+	mi := &file_api_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTriggersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTriggersRequest) ProtoMessage() {}
+
+func (x *ListTriggersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTriggersRequest.ProtoReflect.Descriptor instead.
+func (*ListTriggersRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListTriggersRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+type ListTriggersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Triggers      []*Trigger             `protobuf:"bytes,1,rep,name=triggers" json:"triggers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTriggersResponse) Reset() {
+	*x = ListTriggersResponse{}
+	mi := &file_api_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTriggersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTriggersResponse) ProtoMessage() {}
+
+func (x *ListTriggersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTriggersResponse.ProtoReflect.Descriptor instead.
+func (*ListTriggersResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListTriggersResponse) GetTriggers() []*Trigger {
+	if x != nil {
+		return x.Triggers
+	}
+	return nil
+}
+
+// An alias without an upstream applies to every upstream that does not have
+// its own alias with the same name.
+type Alias struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *int64                 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Upstream      *string                `protobuf:"bytes,2,opt,name=upstream" json:"upstream,omitempty"`
+	Name          *string                `protobuf:"bytes,3,req,name=name" json:"name,omitempty"`
+	Expansion     *string                `protobuf:"bytes,4,req,name=expansion" json:"expansion,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Alias) Reset() {
+	*x = Alias{}
+	mi := &file_api_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alias) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alias) ProtoMessage() {}
+
+func (x *Alias) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alias.ProtoReflect.Descriptor instead.
+func (*Alias) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Alias) GetId() int64 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+func (x *Alias) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *Alias) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *Alias) GetExpansion() string {
+	if x != nil && x.Expansion != nil {
+		return *x.Expansion
+	}
+	return ""
+}
+
+type RemoveAliasRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *int64                 `protobuf:"varint,1,req,name=id" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveAliasRequest) Reset() {
+	*x = RemoveAliasRequest{}
+	mi := &file_api_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveAliasRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveAliasRequest) ProtoMessage() {}
+
+func (x *RemoveAliasRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveAliasRequest.ProtoReflect.Descriptor instead.
+func (*RemoveAliasRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RemoveAliasRequest) GetId() int64 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+type ListAliasesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,opt,name=upstream" json:"upstream,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAliasesRequest) Reset() {
+	*x = ListAliasesRequest{}
+	mi := &file_api_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAliasesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAliasesRequest) ProtoMessage() {}
+
+func (x *ListAliasesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAliasesRequest.ProtoReflect.Descriptor instead.
+func (*ListAliasesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListAliasesRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+type ListAliasesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Aliases       []*Alias               `protobuf:"bytes,1,rep,name=aliases" json:"aliases,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAliasesResponse) Reset() {
+	*x = ListAliasesResponse{}
+	mi := &file_api_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAliasesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAliasesResponse) ProtoMessage() {}
+
+func (x *ListAliasesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAliasesResponse.ProtoReflect.Descriptor instead.
+func (*ListAliasesResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListAliasesResponse) GetAliases() []*Alias {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+type Timer struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       *int64                 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Upstream *string                `protobuf:"bytes,2,req,name=upstream" json:"upstream,omitempty"`
+	Schedule *string                `protobuf:"bytes,3,req,name=schedule" json:"schedule,omitempty"`
+	Command  *string                `protobuf:"bytes,4,req,name=command" json:"command,omitempty"`
+	Enabled  *bool                  `protobuf:"varint,5,opt,name=enabled,def=1" json:"enabled,omitempty"`
+	// When the timer will next run, if its upstream is connected.
+	NextRun *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=next_run,json=nextRun" json:"next_run,omitempty"`
+	// Whether command is a Starlark script rather than a command.
+	Script        *bool `protobuf:"varint,7,opt,name=script" json:"script,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+// Default values for Timer fields.
+const (
+	Default_Timer_Enabled = bool(true)
+)
+
+func (x *Timer) Reset() {
+	*x = Timer{}
+	mi := &file_api_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Timer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Timer) ProtoMessage() {}
+
+func (x *Timer) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Timer.ProtoReflect.Descriptor instead.
+func (*Timer) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Timer) GetId() int64 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+func (x *Timer) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *Timer) GetSchedule() string {
+	if x != nil && x.Schedule != nil {
+		return *x.Schedule
+	}
+	return ""
+}
+
+func (x *Timer) GetCommand() string {
+	if x != nil && x.Command != nil {
+		return *x.Command
+	}
+	return ""
+}
+
+func (x *Timer) GetEnabled() bool {
+	if x != nil && x.Enabled != nil {
+		return *x.Enabled
+	}
+	return Default_Timer_Enabled
+}
+
+func (x *Timer) GetNextRun() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRun
+	}
+	return nil
+}
+
+func (x *Timer) GetScript() bool {
+	if x != nil && x.Script != nil {
+		return *x.Script
+	}
+	return false
+}
+
+type RemoveTimerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *int64                 `protobuf:"varint,1,req,name=id" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveTimerRequest) Reset() {
+	*x = RemoveTimerRequest{}
+	mi := &file_api_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveTimerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveTimerRequest) ProtoMessage() {}
+
+func (x *RemoveTimerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveTimerRequest.ProtoReflect.Descriptor instead.
+func (*RemoveTimerRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RemoveTimerRequest) GetId() int64 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+type SetTimerEnabledRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *int64                 `protobuf:"varint,1,req,name=id" json:"id,omitempty"`
+	Enabled       *bool                  `protobuf:"varint,2,req,name=enabled" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetTimerEnabledRequest) Reset() {
+	*x = SetTimerEnabledRequest{}
+	mi := &file_api_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetTimerEnabledRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTimerEnabledRequest) ProtoMessage() {}
+
+func (x *SetTimerEnabledRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTimerEnabledRequest.ProtoReflect.Descriptor instead.
+func (*SetTimerEnabledRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SetTimerEnabledRequest) GetId() int64 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+func (x *SetTimerEnabledRequest) GetEnabled() bool {
+	if x != nil && x.Enabled != nil {
+		return *x.Enabled
+	}
+	return false
+}
+
+type ListTimersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,opt,name=upstream" json:"upstream,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTimersRequest) Reset() {
+	*x = ListTimersRequest{}
+	mi := &file_api_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTimersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTimersRequest) ProtoMessage() {}
+
+func (x *ListTimersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTimersRequest.ProtoReflect.Descriptor instead.
+func (*ListTimersRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListTimersRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+type ListTimersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timers        []*Timer               `protobuf:"bytes,1,rep,name=timers" json:"timers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTimersResponse) Reset() {
+	*x = ListTimersResponse{}
+	mi := &file_api_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTimersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTimersResponse) ProtoMessage() {}
+
+func (x *ListTimersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTimersResponse.ProtoReflect.Descriptor instead.
+func (*ListTimersResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListTimersResponse) GetTimers() []*Timer {
+	if x != nil {
+		return x.Timers
+	}
+	return nil
+}
+
+type Rewrite struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       *int64                 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Upstream *string                `protobuf:"bytes,2,req,name=upstream" json:"upstream,omitempty"`
+	Pattern  *string                `protobuf:"bytes,3,req,name=pattern" json:"pattern,omitempty"`
+	Action   *string                `protobuf:"bytes,4,req,name=action" json:"action,omitempty"`
+	Argument *string                `protobuf:"bytes,5,opt,name=argument" json:"argument,omitempty"`
+	// Whether the rewrite applies to the log as well as to clients.
+	Log           *bool `protobuf:"varint,6,opt,name=log" json:"log,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rewrite) Reset() {
+	*x = Rewrite{}
+	mi := &file_api_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rewrite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rewrite) ProtoMessage() {}
+
+func (x *Rewrite) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rewrite.ProtoReflect.Descriptor instead.
+func (*Rewrite) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Rewrite) GetId() int64 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+func (x *Rewrite) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *Rewrite) GetPattern() string {
+	if x != nil && x.Pattern != nil {
+		return *x.Pattern
+	}
+	return ""
+}
+
+func (x *Rewrite) GetAction() string {
+	if x != nil && x.Action != nil {
+		return *x.Action
+	}
+	return ""
+}
+
+func (x *Rewrite) GetArgument() string {
+	if x != nil && x.Argument != nil {
+		return *x.Argument
+	}
+	return ""
+}
+
+func (x *Rewrite) GetLog() bool {
+	if x != nil && x.Log != nil {
+		return *x.Log
+	}
+	return false
+}
+
+type RemoveRewriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *int64                 `protobuf:"varint,1,req,name=id" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveRewriteRequest) Reset() {
+	*x = RemoveRewriteRequest{}
+	mi := &file_api_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveRewriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveRewriteRequest) ProtoMessage() {}
+
+func (x *RemoveRewriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveRewriteRequest.ProtoReflect.Descriptor instead.
+func (*RemoveRewriteRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RemoveRewriteRequest) GetId() int64 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+type ListRewritesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,opt,name=upstream" json:"upstream,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRewritesRequest) Reset() {
+	*x = ListRewritesRequest{}
+	mi := &file_api_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRewritesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRewritesRequest) ProtoMessage() {}
+
+func (x *ListRewritesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRewritesRequest.ProtoReflect.Descriptor instead.
+func (*ListRewritesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListRewritesRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+type ListRewritesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rewrites      []*Rewrite             `protobuf:"bytes,1,rep,name=rewrites" json:"rewrites,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRewritesResponse) Reset() {
+	*x = ListRewritesResponse{}
+	mi := &file_api_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRewritesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRewritesResponse) ProtoMessage() {}
+
+func (x *ListRewritesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRewritesResponse.ProtoReflect.Descriptor instead.
+func (*ListRewritesResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListRewritesResponse) GetRewrites() []*Rewrite {
+	if x != nil {
+		return x.Rewrites
+	}
+	return nil
+}
+
+type Notification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,req,name=upstream" json:"upstream,omitempty"`
+	Text          *string                `protobuf:"bytes,2,req,name=text" json:"text,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,3,req,name=time" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Notification) Reset() {
+	*x = Notification{}
+	mi := &file_api_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Notification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Notification) ProtoMessage() {}
+
+func (x *Notification) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Notification.ProtoReflect.Descriptor instead.
+func (*Notification) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Notification) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *Notification) GetText() string {
+	if x != nil && x.Text != nil {
+		return *x.Text
+	}
+	return ""
+}
+
+func (x *Notification) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type WatchNotificationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,opt,name=upstream" json:"upstream,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchNotificationsRequest) Reset() {
+	*x = WatchNotificationsRequest{}
+	mi := &file_api_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchNotificationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchNotificationsRequest) ProtoMessage() {}
+
+func (x *WatchNotificationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchNotificationsRequest.ProtoReflect.Descriptor instead.
+func (*WatchNotificationsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WatchNotificationsRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+type AddUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	Password      *string                `protobuf:"bytes,2,req,name=password" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddUserRequest) Reset() {
+	*x = AddUserRequest{}
+	mi := &file_api_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddUserRequest) ProtoMessage() {}
+
+func (x *AddUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddUserRequest.ProtoReflect.Descriptor instead.
+func (*AddUserRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *AddUserRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *AddUserRequest) GetPassword() string {
+	if x != nil && x.Password != nil {
+		return *x.Password
+	}
+	return ""
+}
+
+type RemoveUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveUserRequest) Reset() {
+	*x = RemoveUserRequest{}
+	mi := &file_api_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveUserRequest) ProtoMessage() {}
+
+func (x *RemoveUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveUserRequest.ProtoReflect.Descriptor instead.
+func (*RemoveUserRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RemoveUserRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+// GrantRequest gives a user a role on an upstream, which is "owner",
+// "read-write" or "read-only", replacing any role they had on it.
+type GrantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *string                `protobuf:"bytes,1,req,name=user" json:"user,omitempty"`
+	Upstream      *string                `protobuf:"bytes,2,req,name=upstream" json:"upstream,omitempty"`
+	Role          *string                `protobuf:"bytes,3,req,name=role" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GrantRequest) Reset() {
+	*x = GrantRequest{}
+	mi := &file_api_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GrantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrantRequest) ProtoMessage() {}
+
+func (x *GrantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrantRequest.ProtoReflect.Descriptor instead.
+func (*GrantRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GrantRequest) GetUser() string {
+	if x != nil && x.User != nil {
+		return *x.User
+	}
+	return ""
+}
+
+func (x *GrantRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *GrantRequest) GetRole() string {
+	if x != nil && x.Role != nil {
+		return *x.Role
+	}
+	return ""
+}
+
+type RevokeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *string                `protobuf:"bytes,1,req,name=user" json:"user,omitempty"`
+	Upstream      *string                `protobuf:"bytes,2,req,name=upstream" json:"upstream,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeRequest) Reset() {
+	*x = RevokeRequest{}
+	mi := &file_api_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeRequest) ProtoMessage() {}
+
+func (x *RevokeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeRequest.ProtoReflect.Descriptor instead.
+func (*RevokeRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RevokeRequest) GetUser() string {
+	if x != nil && x.User != nil {
+		return *x.User
+	}
+	return ""
+}
+
+func (x *RevokeRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+type ListUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersRequest) Reset() {
+	*x = ListUsersRequest{}
+	mi := &file_api_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersRequest) ProtoMessage() {}
+
+func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersRequest.ProtoReflect.Descriptor instead.
+func (*ListUsersRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListUsersRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+type ListUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*User                `protobuf:"bytes,1,rep,name=users" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersResponse) Reset() {
+	*x = ListUsersResponse{}
+	mi := &file_api_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersResponse) ProtoMessage() {}
+
+func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersResponse.ProtoReflect.Descriptor instead.
+func (*ListUsersResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListUsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	Grants        []*UserGrant           `protobuf:"bytes,2,rep,name=grants" json:"grants,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_api_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *User) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *User) GetGrants() []*UserGrant {
+	if x != nil {
+		return x.Grants
+	}
+	return nil
+}
+
+func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type UserGrant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,req,name=upstream" json:"upstream,omitempty"`
+	Role          *string                `protobuf:"bytes,2,req,name=role" json:"role,omitempty"`
+	GrantedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=granted_at,json=grantedAt" json:"granted_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserGrant) Reset() {
+	*x = UserGrant{}
+	mi := &file_api_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserGrant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserGrant) ProtoMessage() {}
+
+func (x *UserGrant) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserGrant.ProtoReflect.Descriptor instead.
+func (*UserGrant) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *UserGrant) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *UserGrant) GetRole() string {
+	if x != nil && x.Role != nil {
+		return *x.Role
+	}
+	return ""
+}
+
+func (x *UserGrant) GetGrantedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GrantedAt
+	}
+	return nil
+}
+
+type CreateTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTokenRequest) Reset() {
+	*x = CreateTokenRequest{}
+	mi := &file_api_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTokenRequest) ProtoMessage() {}
+
+func (x *CreateTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTokenRequest.ProtoReflect.Descriptor instead.
+func (*CreateTokenRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *CreateTokenRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+type CreateTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         *string                `protobuf:"bytes,1,req,name=token" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTokenResponse) Reset() {
+	*x = CreateTokenResponse{}
+	mi := &file_api_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTokenResponse) ProtoMessage() {}
+
+func (x *CreateTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTokenResponse.ProtoReflect.Descriptor instead.
+func (*CreateTokenResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *CreateTokenResponse) GetToken() string {
+	if x != nil && x.Token != nil {
+		return *x.Token
+	}
+	return ""
+}
+
+type RevokeTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeTokenRequest) Reset() {
+	*x = RevokeTokenRequest{}
+	mi := &file_api_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeTokenRequest) ProtoMessage() {}
+
+func (x *RevokeTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeTokenRequest.ProtoReflect.Descriptor instead.
+func (*RevokeTokenRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *RevokeTokenRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+type ListTokensResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*Token               `protobuf:"bytes,1,rep,name=tokens" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTokensResponse) Reset() {
+	*x = ListTokensResponse{}
+	mi := &file_api_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTokensResponse) ProtoMessage() {}
+
+func (x *ListTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTokensResponse.ProtoReflect.Descriptor instead.
+func (*ListTokensResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ListTokensResponse) GetTokens() []*Token {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+type Token struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	mi := &file_api_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *Token) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *Token) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListAuditEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=since" json:"since,omitempty"`
+	Upstream      *string                `protobuf:"bytes,2,opt,name=upstream" json:"upstream,omitempty"`
+	Kind          *string                `protobuf:"bytes,3,opt,name=kind" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsRequest) Reset() {
+	*x = ListAuditEventsRequest{}
+	mi := &file_api_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsRequest) ProtoMessage() {}
+
+func (x *ListAuditEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ListAuditEventsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ListAuditEventsRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetKind() string {
+	if x != nil && x.Kind != nil {
+		return *x.Kind
+	}
+	return ""
+}
+
+type ListAuditEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*AuditEvent          `protobuf:"bytes,1,rep,name=events" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsResponse) Reset() {
+	*x = ListAuditEventsResponse{}
+	mi := &file_api_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsResponse) ProtoMessage() {}
+
+func (x *ListAuditEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ListAuditEventsResponse) GetEvents() []*AuditEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// AuditEvent records something done to iris or through it. For clients of the
+// API, user is who made the call, such as "token:laptop" or "local:alice".
+type AuditEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,req,name=time" json:"time,omitempty"`
+	Kind          *string                `protobuf:"bytes,2,req,name=kind" json:"kind,omitempty"`
+	User          *string                `protobuf:"bytes,3,opt,name=user" json:"user,omitempty"`
+	Remote        *string                `protobuf:"bytes,4,opt,name=remote" json:"remote,omitempty"`
+	Upstream      *string                `protobuf:"bytes,5,opt,name=upstream" json:"upstream,omitempty"`
+	Detail        *string                `protobuf:"bytes,6,opt,name=detail" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEvent) Reset() {
+	*x = AuditEvent{}
+	mi := &file_api_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEvent) ProtoMessage() {}
+
+func (x *AuditEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEvent.ProtoReflect.Descriptor instead.
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *AuditEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *AuditEvent) GetKind() string {
+	if x != nil && x.Kind != nil {
+		return *x.Kind
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetUser() string {
+	if x != nil && x.User != nil {
+		return *x.User
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetRemote() string {
+	if x != nil && x.Remote != nil {
+		return *x.Remote
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetDetail() string {
+	if x != nil && x.Detail != nil {
+		return *x.Detail
+	}
+	return ""
+}
+
+type StatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,opt,name=upstream" json:"upstream,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusRequest) Reset() {
+	*x = StatusRequest{}
+	mi := &file_api_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusRequest) ProtoMessage() {}
+
+func (x *StatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *StatusRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+type StatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstreams     []*UpstreamStatus      `protobuf:"bytes,1,rep,name=upstreams" json:"upstreams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_api_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *StatusResponse) GetUpstreams() []*UpstreamStatus {
+	if x != nil {
+		return x.Upstreams
+	}
+	return nil
+}
+
+type UpstreamStatus struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Key         *string                `protobuf:"bytes,1,req,name=key" json:"key,omitempty"`
+	Address     *string                `protobuf:"bytes,2,opt,name=address" json:"address,omitempty"`
+	ConnectedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=connected_at,json=connectedAt" json:"connected_at,omitempty"`
+	// idle is how long it has been since the game sent anything.
+	Idle          *durationpb.Duration `protobuf:"bytes,4,opt,name=idle" json:"idle,omitempty"`
+	Charset       *string              `protobuf:"bytes,5,opt,name=charset" json:"charset,omitempty"`
+	Options       []*OptionStatus      `protobuf:"bytes,6,rep,name=options" json:"options,omitempty"`
+	HistoryPath   *string              `protobuf:"bytes,7,opt,name=history_path,json=historyPath" json:"history_path,omitempty"`
+	HistorySize   *int64               `protobuf:"varint,8,opt,name=history_size,json=historySize" json:"history_size,omitempty"`
+	Clients       []*ClientStatus      `protobuf:"bytes,9,rep,name=clients" json:"clients,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpstreamStatus) Reset() {
+	*x = UpstreamStatus{}
+	mi := &file_api_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpstreamStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpstreamStatus) ProtoMessage() {}
+
+func (x *UpstreamStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpstreamStatus.ProtoReflect.Descriptor instead.
+func (*UpstreamStatus) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *UpstreamStatus) GetKey() string {
+	if x != nil && x.Key != nil {
+		return *x.Key
+	}
+	return ""
+}
+
+func (x *UpstreamStatus) GetAddress() string {
+	if x != nil && x.Address != nil {
+		return *x.Address
+	}
+	return ""
+}
+
+func (x *UpstreamStatus) GetConnectedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ConnectedAt
+	}
+	return nil
+}
+
+func (x *UpstreamStatus) GetIdle() *durationpb.Duration {
+	if x != nil {
+		return x.Idle
+	}
+	return nil
+}
+
+func (x *UpstreamStatus) GetCharset() string {
+	if x != nil && x.Charset != nil {
+		return *x.Charset
+	}
+	return ""
+}
+
+func (x *UpstreamStatus) GetOptions() []*OptionStatus {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *UpstreamStatus) GetHistoryPath() string {
+	if x != nil && x.HistoryPath != nil {
+		return *x.HistoryPath
+	}
+	return ""
+}
+
+func (x *UpstreamStatus) GetHistorySize() int64 {
+	if x != nil && x.HistorySize != nil {
+		return *x.HistorySize
+	}
+	return 0
+}
+
+func (x *UpstreamStatus) GetClients() []*ClientStatus {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+type ClientStatus struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Address     *string                `protobuf:"bytes,1,req,name=address" json:"address,omitempty"`
+	User        *string                `protobuf:"bytes,2,opt,name=user" json:"user,omitempty"`
+	Role        *string                `protobuf:"bytes,3,opt,name=role" json:"role,omitempty"`
+	Spectating  *bool                  `protobuf:"varint,4,opt,name=spectating" json:"spectating,omitempty"`
+	ConnectedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=connected_at,json=connectedAt" json:"connected_at,omitempty"`
+	// idle is how long it has been since the client sent anything.
+	Idle          *durationpb.Duration `protobuf:"bytes,6,opt,name=idle" json:"idle,omitempty"`
+	Charset       *string              `protobuf:"bytes,7,opt,name=charset" json:"charset,omitempty"`
+	Options       []*OptionStatus      `protobuf:"bytes,8,rep,name=options" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientStatus) Reset() {
+	*x = ClientStatus{}
+	mi := &file_api_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientStatus) ProtoMessage() {}
+
+func (x *ClientStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientStatus.ProtoReflect.Descriptor instead.
+func (*ClientStatus) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ClientStatus) GetAddress() string {
+	if x != nil && x.Address != nil {
+		return *x.Address
+	}
+	return ""
+}
+
+func (x *ClientStatus) GetUser() string {
+	if x != nil && x.User != nil {
+		return *x.User
+	}
+	return ""
+}
+
+func (x *ClientStatus) GetRole() string {
+	if x != nil && x.Role != nil {
+		return *x.Role
+	}
+	return ""
+}
+
+func (x *ClientStatus) GetSpectating() bool {
+	if x != nil && x.Spectating != nil {
+		return *x.Spectating
+	}
+	return false
+}
+
+func (x *ClientStatus) GetConnectedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ConnectedAt
+	}
+	return nil
+}
+
+func (x *ClientStatus) GetIdle() *durationpb.Duration {
+	if x != nil {
+		return x.Idle
+	}
+	return nil
+}
+
+func (x *ClientStatus) GetCharset() string {
+	if x != nil && x.Charset != nil {
+		return *x.Charset
+	}
+	return ""
+}
+
+func (x *ClientStatus) GetOptions() []*OptionStatus {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+// OptionStatus is a telnet option that has been negotiated, and whether it is
+// enabled for iris (us) and for the other side (them).
+type OptionStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	Us            *bool                  `protobuf:"varint,2,req,name=us" json:"us,omitempty"`
+	Them          *bool                  `protobuf:"varint,3,req,name=them" json:"them,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptionStatus) Reset() {
+	*x = OptionStatus{}
+	mi := &file_api_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptionStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptionStatus) ProtoMessage() {}
+
+func (x *OptionStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptionStatus.ProtoReflect.Descriptor instead.
+func (*OptionStatus) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *OptionStatus) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *OptionStatus) GetUs() bool {
+	if x != nil && x.Us != nil {
+		return *x.Us
+	}
+	return false
+}
+
+func (x *OptionStatus) GetThem() bool {
+	if x != nil && x.Them != nil {
+		return *x.Them
+	}
+	return false
+}
+
+type SendRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upstream      *string                `protobuf:"bytes,1,req,name=upstream" json:"upstream,omitempty"`
+	Text          *string                `protobuf:"bytes,2,req,name=text" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendRequest) Reset() {
+	*x = SendRequest{}
+	mi := &file_api_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendRequest) ProtoMessage() {}
+
+func (x *SendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendRequest.ProtoReflect.Descriptor instead.
+func (*SendRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *SendRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *SendRequest) GetText() string {
+	if x != nil && x.Text != nil {
+		return *x.Text
+	}
+	return ""
+}
+
+type ExpectRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Upstream *string                `protobuf:"bytes,1,req,name=upstream" json:"upstream,omitempty"`
+	// pattern is a regular expression, matched against lines without ANSI
+	// escape codes or the newline.
+	Pattern *string `protobuf:"bytes,2,req,name=pattern" json:"pattern,omitempty"`
+	// timeout is 10 seconds if it is not set.
+	Timeout *durationpb.Duration `protobuf:"bytes,3,opt,name=timeout" json:"timeout,omitempty"`
+	// send is sent once Expect is waiting, so that the reply to it cannot
+	// arrive too soon to be seen.
+	Send          *string `protobuf:"bytes,4,opt,name=send" json:"send,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpectRequest) Reset() {
+	*x = ExpectRequest{}
+	mi := &file_api_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpectRequest) ProtoMessage() {}
+
+func (x *ExpectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpectRequest.ProtoReflect.Descriptor instead.
+func (*ExpectRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ExpectRequest) GetUpstream() string {
+	if x != nil && x.Upstream != nil {
+		return *x.Upstream
+	}
+	return ""
+}
+
+func (x *ExpectRequest) GetPattern() string {
+	if x != nil && x.Pattern != nil {
+		return *x.Pattern
+	}
+	return ""
+}
+
+func (x *ExpectRequest) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *ExpectRequest) GetSend() string {
+	if x != nil && x.Send != nil {
+		return *x.Send
+	}
+	return ""
+}
+
+type ExpectResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Line  *string                `protobuf:"bytes,1,req,name=line" json:"line,omitempty"`
+	// groups are the text of each group in the pattern, which is empty for
+	// those that did not take part in the match.
+	Groups        []string `protobuf:"bytes,2,rep,name=groups" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpectResponse) Reset() {
+	*x = ExpectResponse{}
+	mi := &file_api_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpectResponse) ProtoMessage() {}
+
+func (x *ExpectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpectResponse.ProtoReflect.Descriptor instead.
+func (*ExpectResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *ExpectResponse) GetLine() string {
+	if x != nil && x.Line != nil {
+		return *x.Line
+	}
+	return ""
+}
+
+func (x *ExpectResponse) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+var File_api_proto protoreflect.FileDescriptor
+
+const file_api_proto_rawDesc = "" +
+	"\n" +
+	"\tapi.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"N\n" +
+	"\bUpstream\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\x12\x18\n" +
+	"\aaddress\x18\x02 \x02(\tR\aaddress\x12\x14\n" +
+	"\x05login\x18\x03 \x02(\tR\x05login\"\x90\x01\n" +
+	"\x12AddUpstreamRequest\x12%\n" +
+	"\bupstream\x18\x01 \x02(\v2\t.UpstreamR\bupstream\x12\x1a\n" +
+	"\bpassword\x18\x03 \x02(\tR\bpassword\x12\x16\n" +
+	"\x06script\x18\x04 \x01(\tR\x06script\x12\x1f\n" +
+	"\vscript_type\x18\x05 \x01(\tR\n" +
+	"scriptType\"\xec\x01\n" +
+	"\x13EditUpstreamRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\x12\x1a\n" +
+	"\bpassword\x18\x02 \x02(\tR\bpassword\x12\x19\n" +
+	"\bnew_name\x18\x03 \x01(\tR\anewName\x12!\n" +
+	"\fnew_password\x18\x04 \x01(\tR\vnewPassword\x12\x18\n" +
+	"\aaddress\x18\x05 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05login\x18\x06 \x01(\tR\x05login\x12\x16\n" +
+	"\x06script\x18\a \x01(\tR\x06script\x12\x1f\n" +
+	"\vscript_type\x18\b \x01(\tR\n" +
+	"scriptType\"@\n" +
+	"\x15ListUpstreamsResponse\x12'\n" +
+	"\tupstreams\x18\x01 \x03(\v2\t.UpstreamR\tupstreams\"9\n" +
+	"\vTailRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\x12\x16\n" +
+	"\x06follow\x18\x02 \x01(\bR\x06follow\"\x1d\n" +
+	"\aLogData\x12\x12\n" +
+	"\x04data\x18\x01 \x02(\fR\x04data\"\xad\x01\n" +
+	"\x05Scene\x12\x1a\n" +
+	"\bupstream\x18\x01 \x02(\tR\bupstream\x12\x12\n" +
+	"\x04name\x18\x02 \x02(\tR\x04name\x129\n" +
+	"\n" +
+	"started_at\x18\x03 \x02(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x129\n" +
+	"\n" +
+	"stopped_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstoppedAt\"/\n" +
+	"\x11ListScenesRequest\x12\x1a\n" +
+	"\bupstream\x18\x01 \x01(\tR\bupstream\"4\n" +
+	"\x12ListScenesResponse\x12\x1e\n" +
+	"\x06scenes\x18\x01 \x03(\v2\x06.SceneR\x06scenes\"\x83\x01\n" +
+	"\aTrigger\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bupstream\x18\x02 \x02(\tR\bupstream\x12\x18\n" +
+	"\apattern\x18\x03 \x02(\tR\apattern\x12\x16\n" +
+	"\x06action\x18\x04 \x02(\tR\x06action\x12\x1a\n" +
+	"\bargument\x18\x05 \x01(\tR\bargument\"&\n" +
+	"\x14RemoveTriggerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x02(\x03R\x02id\"1\n" +
+	"\x13ListTriggersRequest\x12\x1a\n" +
+	"\bupstream\x18\x01 \x01(\tR\bupstream\"<\n" +
+	"\x14ListTriggersResponse\x12$\n" +
+	"\btriggers\x18\x01 \x03(\v2\b.TriggerR\btriggers\"e\n" +
+	"\x05Alias\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bupstream\x18\x02 \x01(\tR\bupstream\x12\x12\n" +
+	"\x04name\x18\x03 \x02(\tR\x04name\x12\x1c\n" +
+	"\texpansion\x18\x04 \x02(\tR\texpansion\"$\n" +
+	"\x12RemoveAliasRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x02(\x03R\x02id\"0\n" +
+	"\x12ListAliasesRequest\x12\x1a\n" +
+	"\bupstream\x18\x01 \x01(\tR\bupstream\"7\n" +
+	"\x13ListAliasesResponse\x12 \n" +
+	"\aaliases\x18\x01 \x03(\v2\x06.AliasR\aaliases\"\xd8\x01\n" +
+	"\x05Timer\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bupstream\x18\x02 \x02(\tR\bupstream\x12\x1a\n" +
+	"\bschedule\x18\x03 \x02(\tR\bschedule\x12\x18\n" +
+	"\acommand\x18\x04 \x02(\tR\acommand\x12\x1e\n" +
+	"\aenabled\x18\x05 \x01(\b:\x04trueR\aenabled\x125\n" +
+	"\bnext_run\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\anextRun\x12\x16\n" +
+	"\x06script\x18\a \x01(\bR\x06script\"$\n" +
+	"\x12RemoveTimerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x02(\x03R\x02id\"B\n" +
+	"\x16SetTimerEnabledRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x02(\x03R\x02id\x12\x18\n" +
+	"\aenabled\x18\x02 \x02(\bR\aenabled\"/\n" +
+	"\x11ListTimersRequest\x12\x1a\n" +
+	"\bupstream\x18\x01 \x01(\tR\bupstream\"4\n" +
+	"\x12ListTimersResponse\x12\x1e\n" +
+	"\x06timers\x18\x01 \x03(\v2\x06.TimerR\x06timers\"\x95\x01\n" +
+	"\aRewrite\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bupstream\x18\x02 \x02(\tR\bupstream\x12\x18\n" +
+	"\apattern\x18\x03 \x02(\tR\apattern\x12\x16\n" +
+	"\x06action\x18\x04 \x02(\tR\x06action\x12\x1a\n" +
+	"\bargument\x18\x05 \x01(\tR\bargument\x12\x10\n" +
+	"\x03log\x18\x06 \x01(\bR\x03log\"&\n" +
+	"\x14RemoveRewriteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x02(\x03R\x02id\"1\n" +
+	"\x13ListRewritesRequest\x12\x1a\n" +
+	"\bupstream\x18\x01 \x01(\tR\bupstream\"<\n" +
+	"\x14ListRewritesResponse\x12$\n" +
+	"\brewrites\x18\x01 \x03(\v2\b.RewriteR\brewrites\"n\n" +
+	"\fNotification\x12\x1a\n" +
+	"\bupstream\x18\x01 \x02(\tR\bupstream\x12\x12\n" +
+	"\x04text\x18\x02 \x02(\tR\x04text\x12.\n" +
+	"\x04time\x18\x03 \x02(\v2\x1a.google.protobuf.TimestampR\x04time\"7\n" +
+	"\x19WatchNotificationsRequest\x12\x1a\n" +
+	"\bupstream\x18\x01 \x01(\tR\bupstream\"@\n" +
+	"\x0eAddUserRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\x12\x1a\n" +
+	"\bpassword\x18\x02 \x02(\tR\bpassword\"'\n" +
+	"\x11RemoveUserRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\"R\n" +
+	"\fGrantRequest\x12\x12\n" +
+	"\x04user\x18\x01 \x02(\tR\x04user\x12\x1a\n" +
+	"\bupstream\x18\x02 \x02(\tR\bupstream\x12\x12\n" +
+	"\x04role\x18\x03 \x02(\tR\x04role\"?\n" +
+	"\rRevokeRequest\x12\x12\n" +
+	"\x04user\x18\x01 \x02(\tR\x04user\x12\x1a\n" +
+	"\bupstream\x18\x02 \x02(\tR\bupstream\"&\n" +
+	"\x10ListUsersRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"0\n" +
+	"\x11ListUsersResponse\x12\x1b\n" +
+	"\x05users\x18\x01 \x03(\v2\x05.UserR\x05users\"y\n" +
+	"\x04User\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\x12\"\n" +
+	"\x06grants\x18\x02 \x03(\v2\n" +
+	".UserGrantR\x06grants\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"v\n" +
+	"\tUserGrant\x12\x1a\n" +
+	"\bupstream\x18\x01 \x02(\tR\bupstream\x12\x12\n" +
+	"\x04role\x18\x02 \x02(\tR\x04role\x129\n" +
+	"\n" +
+	"granted_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tgrantedAt\"(\n" +
+	"\x12CreateTokenRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\"+\n" +
+	"\x13CreateTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x02(\tR\x05token\"(\n" +
+	"\x12RevokeTokenRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\"4\n" +
+	"\x12ListTokensResponse\x12\x1e\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x06.TokenR\x06tokens\"V\n" +
+	"\x05Token\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"z\n" +
+	"\x16ListAuditEventsRequest\x120\n" +
+	"\x05since\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x12\x1a\n" +
+	"\bupstream\x18\x02 \x01(\tR\bupstream\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\">\n" +
+	"\x17ListAuditEventsResponse\x12#\n" +
+	"\x06events\x18\x01 \x03(\v2\v.AuditEventR\x06events\"\xb0\x01\n" +
+	"\n" +
+	"AuditEvent\x12.\n" +
+	"\x04time\x18\x01 \x02(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x12\n" +
+	"\x04kind\x18\x02 \x02(\tR\x04kind\x12\x12\n" +
+	"\x04user\x18\x03 \x01(\tR\x04user\x12\x16\n" +
+	"\x06remote\x18\x04 \x01(\tR\x06remote\x12\x1a\n" +
+	"\bupstream\x18\x05 \x01(\tR\bupstream\x12\x16\n" +
+	"\x06detail\x18\x06 \x01(\tR\x06detail\"+\n" +
+	"\rStatusRequest\x12\x1a\n" +
+	"\bupstream\x18\x01 \x01(\tR\bupstream\"?\n" +
+	"\x0eStatusResponse\x12-\n" +
+	"\tupstreams\x18\x01 \x03(\v2\x0f.UpstreamStatusR\tupstreams\"\xdc\x02\n" +
+	"\x0eUpstreamStatus\x12\x10\n" +
+	"\x03key\x18\x01 \x02(\tR\x03key\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12=\n" +
+	"\fconnected_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vconnectedAt\x12-\n" +
+	"\x04idle\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x04idle\x12\x18\n" +
+	"\acharset\x18\x05 \x01(\tR\acharset\x12'\n" +
+	"\aoptions\x18\x06 \x03(\v2\r.OptionStatusR\aoptions\x12!\n" +
+	"\fhistory_path\x18\a \x01(\tR\vhistoryPath\x12!\n" +
+	"\fhistory_size\x18\b \x01(\x03R\vhistorySize\x12'\n" +
+	"\aclients\x18\t \x03(\v2\r.ClientStatusR\aclients\"\xa1\x02\n" +
+	"\fClientStatus\x12\x18\n" +
+	"\aaddress\x18\x01 \x02(\tR\aaddress\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\x12\x1e\n" +
+	"\n" +
+	"spectating\x18\x04 \x01(\bR\n" +
+	"spectating\x12=\n" +
+	"\fconnected_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vconnectedAt\x12-\n" +
+	"\x04idle\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\x04idle\x12\x18\n" +
+	"\acharset\x18\a \x01(\tR\acharset\x12'\n" +
+	"\aoptions\x18\b \x03(\v2\r.OptionStatusR\aoptions\"F\n" +
+	"\fOptionStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x02(\tR\x04name\x12\x0e\n" +
+	"\x02us\x18\x02 \x02(\bR\x02us\x12\x12\n" +
+	"\x04them\x18\x03 \x02(\bR\x04them\"=\n" +
+	"\vSendRequest\x12\x1a\n" +
+	"\bupstream\x18\x01 \x02(\tR\bupstream\x12\x12\n" +
+	"\x04text\x18\x02 \x02(\tR\x04text\"\x8e\x01\n" +
+	"\rExpectRequest\x12\x1a\n" +
+	"\bupstream\x18\x01 \x02(\tR\bupstream\x12\x18\n" +
+	"\apattern\x18\x02 \x02(\tR\apattern\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12\x12\n" +
+	"\x04send\x18\x04 \x01(\tR\x04send\"<\n" +
+	"\x0eExpectResponse\x12\x12\n" +
+	"\x04line\x18\x01 \x02(\tR\x04line\x12\x16\n" +
+	"\x06groups\x18\x02 \x03(\tR\x06groups2\xcc\x01\n" +
+	"\tUpstreams\x12<\n" +
+	"\vAddUpstream\x12\x13.AddUpstreamRequest\x1a\x16.google.protobuf.Empty\"\x00\x12>\n" +
+	"\fEditUpstream\x12\x14.EditUpstreamRequest\x1a\x16.google.protobuf.Empty\"\x00\x12A\n" +
+	"\rListUpstreams\x12\x16.google.protobuf.Empty\x1a\x16.ListUpstreamsResponse\"\x002\x91\x01\n" +
+	"\x04Logs\x12\"\n" +
+	"\x04Tail\x12\f.TailRequest\x1a\b.LogData\"\x000\x01\x12,\n" +
+	"\bAddScene\x12\x06.Scene\x1a\x16.google.protobuf.Empty\"\x00\x127\n" +
+	"\n" +
+	"ListScenes\x12\x12.ListScenesRequest\x1a\x13.ListScenesResponse\"\x002\xaf\x01\n" +
+	"\bTriggers\x12\"\n" +
+	"\n" +
+	"AddTrigger\x12\b.Trigger\x1a\b.Trigger\"\x00\x12@\n" +
+	"\rRemoveTrigger\x12\x15.RemoveTriggerRequest\x1a\x16.google.protobuf.Empty\"\x00\x12=\n" +
+	"\fListTriggers\x12\x14.ListTriggersRequest\x1a\x15.ListTriggersResponse\"\x002\xa1\x01\n" +
+	"\aAliases\x12\x1c\n" +
+	"\bAddAlias\x12\x06.Alias\x1a\x06.Alias\"\x00\x12<\n" +
+	"\vRemoveAlias\x12\x13.RemoveAliasRequest\x1a\x16.google.protobuf.Empty\"\x00\x12:\n" +
+	"\vListAliases\x12\x13.ListAliasesRequest\x1a\x14.ListAliasesResponse\"\x002\xe3\x01\n" +
+	"\x06Timers\x12\x1c\n" +
+	"\bAddTimer\x12\x06.Timer\x1a\x06.Timer\"\x00\x12<\n" +
+	"\vRemoveTimer\x12\x13.RemoveTimerRequest\x1a\x16.google.protobuf.Empty\"\x00\x12D\n" +
+	"\x0fSetTimerEnabled\x12\x17.SetTimerEnabledRequest\x1a\x16.google.protobuf.Empty\"\x00\x127\n" +
+	"\n" +
+	"ListTimers\x12\x12.ListTimersRequest\x1a\x13.ListTimersResponse\"\x002\xaf\x01\n" +
+	"\bRewrites\x12\"\n" +
+	"\n" +
+	"AddRewrite\x12\b.Rewrite\x1a\b.Rewrite\"\x00\x12@\n" +
+	"\rRemoveRewrite\x12\x15.RemoveRewriteRequest\x1a\x16.google.protobuf.Empty\"\x00\x12=\n" +
+	"\fListRewrites\x12\x14.ListRewritesRequest\x1a\x15.ListRewritesResponse\"\x002G\n" +
+	"\rNotifications\x126\n" +
+	"\x05Watch\x12\x1a.WatchNotificationsRequest\x1a\r.Notification\"\x000\x012\x95\x02\n" +
+	"\x05Users\x124\n" +
+	"\aAddUser\x12\x0f.AddUserRequest\x1a\x16.google.protobuf.Empty\"\x00\x12:\n" +
+	"\n" +
+	"RemoveUser\x12\x12.RemoveUserRequest\x1a\x16.google.protobuf.Empty\"\x00\x120\n" +
+	"\x05Grant\x12\r.GrantRequest\x1a\x16.google.protobuf.Empty\"\x00\x122\n" +
+	"\x06Revoke\x12\x0e.RevokeRequest\x1a\x16.google.protobuf.Empty\"\x00\x124\n" +
+	"\tListUsers\x12\x11.ListUsersRequest\x1a\x12.ListUsersResponse\"\x002\xbf\x01\n" +
+	"\x06Tokens\x12:\n" +
+	"\vCreateToken\x12\x13.CreateTokenRequest\x1a\x14.CreateTokenResponse\"\x00\x12<\n" +
+	"\vRevokeToken\x12\x13.RevokeTokenRequest\x1a\x16.google.protobuf.Empty\"\x00\x12;\n" +
+	"\n" +
+	"ListTokens\x12\x16.google.protobuf.Empty\x1a\x13.ListTokensResponse\"\x002O\n" +
+	"\x05Audit\x12F\n" +
+	"\x0fListAuditEvents\x12\x17.ListAuditEventsRequest\x1a\x18.ListAuditEventsResponse\"\x0025\n" +
+	"\x06Status\x12+\n" +
+	"\x06Status\x12\x0e.StatusRequest\x1a\x0f.StatusResponse\"\x002g\n" +
+	"\bSessions\x12.\n" +
+	"\x04Send\x12\f.SendRequest\x1a\x16.google.protobuf.Empty\"\x00\x12+\n" +
+	"\x06Expect\x12\x0e.ExpectRequest\x1a\x0f.ExpectResponse\"\x00B\x1cZ\x1agithub.com/stesla/iris/api"
 
 var (
 	file_api_proto_rawDescOnce sync.Once
@@ -318,28 +3197,163 @@ func file_api_proto_rawDescGZIP() []byte {
 	return file_api_proto_rawDescData
 }
 
-var file_api_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_api_proto_msgTypes = make([]protoimpl.MessageInfo, 52)
 var file_api_proto_goTypes = []any{
-	(*Upstream)(nil),              // 0: Upstream
-	(*AddUpstreamRequest)(nil),    // 1: AddUpstreamRequest
-	(*EditUpstreamRequest)(nil),   // 2: EditUpstreamRequest
-	(*ListUpstreamsResponse)(nil), // 3: ListUpstreamsResponse
-	(*emptypb.Empty)(nil),         // 4: google.protobuf.Empty
+	(*Upstream)(nil),                  // 0: Upstream
+	(*AddUpstreamRequest)(nil),        // 1: AddUpstreamRequest
+	(*EditUpstreamRequest)(nil),       // 2: EditUpstreamRequest
+	(*ListUpstreamsResponse)(nil),     // 3: ListUpstreamsResponse
+	(*TailRequest)(nil),               // 4: TailRequest
+	(*LogData)(nil),                   // 5: LogData
+	(*Scene)(nil),                     // 6: Scene
+	(*ListScenesRequest)(nil),         // 7: ListScenesRequest
+	(*ListScenesResponse)(nil),        // 8: ListScenesResponse
+	(*Trigger)(nil),                   // 9: Trigger
+	(*RemoveTriggerRequest)(nil),      // 10: RemoveTriggerRequest
+	(*ListTriggersRequest)(nil),       // 11: ListTriggersRequest
+	(*ListTriggersResponse)(nil),      // 12: ListTriggersResponse
+	(*Alias)(nil),                     // 13: Alias
+	(*RemoveAliasRequest)(nil),        // 14: RemoveAliasRequest
+	(*ListAliasesRequest)(nil),        // 15: ListAliasesRequest
+	(*ListAliasesResponse)(nil),       // 16: ListAliasesResponse
+	(*Timer)(nil),                     // 17: Timer
+	(*RemoveTimerRequest)(nil),        // 18: RemoveTimerRequest
+	(*SetTimerEnabledRequest)(nil),    // 19: SetTimerEnabledRequest
+	(*ListTimersRequest)(nil),         // 20: ListTimersRequest
+	(*ListTimersResponse)(nil),        // 21: ListTimersResponse
+	(*Rewrite)(nil),                   // 22: Rewrite
+	(*RemoveRewriteRequest)(nil),      // 23: RemoveRewriteRequest
+	(*ListRewritesRequest)(nil),       // 24: ListRewritesRequest
+	(*ListRewritesResponse)(nil),      // 25: ListRewritesResponse
+	(*Notification)(nil),              // 26: Notification
+	(*WatchNotificationsRequest)(nil), // 27: WatchNotificationsRequest
+	(*AddUserRequest)(nil),            // 28: AddUserRequest
+	(*RemoveUserRequest)(nil),         // 29: RemoveUserRequest
+	(*GrantRequest)(nil),              // 30: GrantRequest
+	(*RevokeRequest)(nil),             // 31: RevokeRequest
+	(*ListUsersRequest)(nil),          // 32: ListUsersRequest
+	(*ListUsersResponse)(nil),         // 33: ListUsersResponse
+	(*User)(nil),                      // 34: User
+	(*UserGrant)(nil),                 // 35: UserGrant
+	(*CreateTokenRequest)(nil),        // 36: CreateTokenRequest
+	(*CreateTokenResponse)(nil),       // 37: CreateTokenResponse
+	(*RevokeTokenRequest)(nil),        // 38: RevokeTokenRequest
+	(*ListTokensResponse)(nil),        // 39: ListTokensResponse
+	(*Token)(nil),                     // 40: Token
+	(*ListAuditEventsRequest)(nil),    // 41: ListAuditEventsRequest
+	(*ListAuditEventsResponse)(nil),   // 42: ListAuditEventsResponse
+	(*AuditEvent)(nil),                // 43: AuditEvent
+	(*StatusRequest)(nil),             // 44: StatusRequest
+	(*StatusResponse)(nil),            // 45: StatusResponse
+	(*UpstreamStatus)(nil),            // 46: UpstreamStatus
+	(*ClientStatus)(nil),              // 47: ClientStatus
+	(*OptionStatus)(nil),              // 48: OptionStatus
+	(*SendRequest)(nil),               // 49: SendRequest
+	(*ExpectRequest)(nil),             // 50: ExpectRequest
+	(*ExpectResponse)(nil),            // 51: ExpectResponse
+	(*timestamppb.Timestamp)(nil),     // 52: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),       // 53: google.protobuf.Duration
+	(*emptypb.Empty)(nil),             // 54: google.protobuf.Empty
 }
 var file_api_proto_depIdxs = []int32{
-	0, // 0: AddUpstreamRequest.upstream:type_name -> Upstream
-	0, // 1: ListUpstreamsResponse.upstreams:type_name -> Upstream
-	1, // 2: Upstreams.AddUpstream:input_type -> AddUpstreamRequest
-	2, // 3: Upstreams.EditUpstream:input_type -> EditUpstreamRequest
-	4, // 4: Upstreams.ListUpstreams:input_type -> google.protobuf.Empty
-	4, // 5: Upstreams.AddUpstream:output_type -> google.protobuf.Empty
-	4, // 6: Upstreams.EditUpstream:output_type -> google.protobuf.Empty
-	3, // 7: Upstreams.ListUpstreams:output_type -> ListUpstreamsResponse
-	5, // [5:8] is the sub-list for method output_type
-	2, // [2:5] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	0,  // 0: AddUpstreamRequest.upstream:type_name -> Upstream
+	0,  // 1: ListUpstreamsResponse.upstreams:type_name -> Upstream
+	52, // 2: Scene.started_at:type_name -> google.protobuf.Timestamp
+	52, // 3: Scene.stopped_at:type_name -> google.protobuf.Timestamp
+	6,  // 4: ListScenesResponse.scenes:type_name -> Scene
+	9,  // 5: ListTriggersResponse.triggers:type_name -> Trigger
+	13, // 6: ListAliasesResponse.aliases:type_name -> Alias
+	52, // 7: Timer.next_run:type_name -> google.protobuf.Timestamp
+	17, // 8: ListTimersResponse.timers:type_name -> Timer
+	22, // 9: ListRewritesResponse.rewrites:type_name -> Rewrite
+	52, // 10: Notification.time:type_name -> google.protobuf.Timestamp
+	34, // 11: ListUsersResponse.users:type_name -> User
+	35, // 12: User.grants:type_name -> UserGrant
+	52, // 13: User.created_at:type_name -> google.protobuf.Timestamp
+	52, // 14: UserGrant.granted_at:type_name -> google.protobuf.Timestamp
+	40, // 15: ListTokensResponse.tokens:type_name -> Token
+	52, // 16: Token.created_at:type_name -> google.protobuf.Timestamp
+	52, // 17: ListAuditEventsRequest.since:type_name -> google.protobuf.Timestamp
+	43, // 18: ListAuditEventsResponse.events:type_name -> AuditEvent
+	52, // 19: AuditEvent.time:type_name -> google.protobuf.Timestamp
+	46, // 20: StatusResponse.upstreams:type_name -> UpstreamStatus
+	52, // 21: UpstreamStatus.connected_at:type_name -> google.protobuf.Timestamp
+	53, // 22: UpstreamStatus.idle:type_name -> google.protobuf.Duration
+	48, // 23: UpstreamStatus.options:type_name -> OptionStatus
+	47, // 24: UpstreamStatus.clients:type_name -> ClientStatus
+	52, // 25: ClientStatus.connected_at:type_name -> google.protobuf.Timestamp
+	53, // 26: ClientStatus.idle:type_name -> google.protobuf.Duration
+	48, // 27: ClientStatus.options:type_name -> OptionStatus
+	53, // 28: ExpectRequest.timeout:type_name -> google.protobuf.Duration
+	1,  // 29: Upstreams.AddUpstream:input_type -> AddUpstreamRequest
+	2,  // 30: Upstreams.EditUpstream:input_type -> EditUpstreamRequest
+	54, // 31: Upstreams.ListUpstreams:input_type -> google.protobuf.Empty
+	4,  // 32: Logs.Tail:input_type -> TailRequest
+	6,  // 33: Logs.AddScene:input_type -> Scene
+	7,  // 34: Logs.ListScenes:input_type -> ListScenesRequest
+	9,  // 35: Triggers.AddTrigger:input_type -> Trigger
+	10, // 36: Triggers.RemoveTrigger:input_type -> RemoveTriggerRequest
+	11, // 37: Triggers.ListTriggers:input_type -> ListTriggersRequest
+	13, // 38: Aliases.AddAlias:input_type -> Alias
+	14, // 39: Aliases.RemoveAlias:input_type -> RemoveAliasRequest
+	15, // 40: Aliases.ListAliases:input_type -> ListAliasesRequest
+	17, // 41: Timers.AddTimer:input_type -> Timer
+	18, // 42: Timers.RemoveTimer:input_type -> RemoveTimerRequest
+	19, // 43: Timers.SetTimerEnabled:input_type -> SetTimerEnabledRequest
+	20, // 44: Timers.ListTimers:input_type -> ListTimersRequest
+	22, // 45: Rewrites.AddRewrite:input_type -> Rewrite
+	23, // 46: Rewrites.RemoveRewrite:input_type -> RemoveRewriteRequest
+	24, // 47: Rewrites.ListRewrites:input_type -> ListRewritesRequest
+	27, // 48: Notifications.Watch:input_type -> WatchNotificationsRequest
+	28, // 49: Users.AddUser:input_type -> AddUserRequest
+	29, // 50: Users.RemoveUser:input_type -> RemoveUserRequest
+	30, // 51: Users.Grant:input_type -> GrantRequest
+	31, // 52: Users.Revoke:input_type -> RevokeRequest
+	32, // 53: Users.ListUsers:input_type -> ListUsersRequest
+	36, // 54: Tokens.CreateToken:input_type -> CreateTokenRequest
+	38, // 55: Tokens.RevokeToken:input_type -> RevokeTokenRequest
+	54, // 56: Tokens.ListTokens:input_type -> google.protobuf.Empty
+	41, // 57: Audit.ListAuditEvents:input_type -> ListAuditEventsRequest
+	44, // 58: Status.Status:input_type -> StatusRequest
+	49, // 59: Sessions.Send:input_type -> SendRequest
+	50, // 60: Sessions.Expect:input_type -> ExpectRequest
+	54, // 61: Upstreams.AddUpstream:output_type -> google.protobuf.Empty
+	54, // 62: Upstreams.EditUpstream:output_type -> google.protobuf.Empty
+	3,  // 63: Upstreams.ListUpstreams:output_type -> ListUpstreamsResponse
+	5,  // 64: Logs.Tail:output_type -> LogData
+	54, // 65: Logs.AddScene:output_type -> google.protobuf.Empty
+	8,  // 66: Logs.ListScenes:output_type -> ListScenesResponse
+	9,  // 67: Triggers.AddTrigger:output_type -> Trigger
+	54, // 68: Triggers.RemoveTrigger:output_type -> google.protobuf.Empty
+	12, // 69: Triggers.ListTriggers:output_type -> ListTriggersResponse
+	13, // 70: Aliases.AddAlias:output_type -> Alias
+	54, // 71: Aliases.RemoveAlias:output_type -> google.protobuf.Empty
+	16, // 72: Aliases.ListAliases:output_type -> ListAliasesResponse
+	17, // 73: Timers.AddTimer:output_type -> Timer
+	54, // 74: Timers.RemoveTimer:output_type -> google.protobuf.Empty
+	54, // 75: Timers.SetTimerEnabled:output_type -> google.protobuf.Empty
+	21, // 76: Timers.ListTimers:output_type -> ListTimersResponse
+	22, // 77: Rewrites.AddRewrite:output_type -> Rewrite
+	54, // 78: Rewrites.RemoveRewrite:output_type -> google.protobuf.Empty
+	25, // 79: Rewrites.ListRewrites:output_type -> ListRewritesResponse
+	26, // 80: Notifications.Watch:output_type -> Notification
+	54, // 81: Users.AddUser:output_type -> google.protobuf.Empty
+	54, // 82: Users.RemoveUser:output_type -> google.protobuf.Empty
+	54, // 83: Users.Grant:output_type -> google.protobuf.Empty
+	54, // 84: Users.Revoke:output_type -> google.protobuf.Empty
+	33, // 85: Users.ListUsers:output_type -> ListUsersResponse
+	37, // 86: Tokens.CreateToken:output_type -> CreateTokenResponse
+	54, // 87: Tokens.RevokeToken:output_type -> google.protobuf.Empty
+	39, // 88: Tokens.ListTokens:output_type -> ListTokensResponse
+	42, // 89: Audit.ListAuditEvents:output_type -> ListAuditEventsResponse
+	45, // 90: Status.Status:output_type -> StatusResponse
+	54, // 91: Sessions.Send:output_type -> google.protobuf.Empty
+	51, // 92: Sessions.Expect:output_type -> ExpectResponse
+	61, // [61:93] is the sub-list for method output_type
+	29, // [29:61] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_init() }
@@ -353,9 +3367,9 @@ func file_api_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_rawDesc), len(file_api_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   52,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   12,
 		},
 		GoTypes:           file_api_proto_goTypes,
 		DependencyIndexes: file_api_proto_depIdxs,
@@ -0,0 +1,123 @@
+package alias
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+)
+
+var (
+	pkgcmd = &cobra.Command{
+		Use:   "alias [OPTIONS] COMMAND",
+		Short: "commands for managing aliases",
+		Long: `Aliases expand commands typed by any client before they are sent upstream.
+The expansion is split on alias.separator (";" by default) into several
+commands, and in each of them $1 through $9 are replaced with the arguments to
+the alias, $* with all of them, and $$ with $.
+
+An alias without an upstream applies to every upstream that does not have its
+own alias with the same name.`,
+	}
+	upstream string
+)
+
+func init() {
+	addCmd := &cobra.Command{
+		Use:   "add NAME EXPANSION",
+		Short: "add an alias, or replace its expansion",
+		Args:  cobra.ExactArgs(2),
+		RunE:  Add,
+	}
+	addCmd.Flags().StringVarP(&upstream, "upstream", "u", "", "only use the alias for this upstream")
+	pkgcmd.AddCommand(addCmd)
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "list [UPSTREAM]",
+		Short: "list aliases",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  List,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "remove ID",
+		Short: "remove an alias",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Remove,
+	})
+}
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(pkgcmd)
+}
+
+func Add(cmd *cobra.Command, args []string) error {
+	req := &api.Alias{
+		Name:      &args[0],
+		Expansion: &args[1],
+	}
+	if upstream != "" {
+		req.Upstream = &upstream
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	a, err := api.NewAliasesClient(conn).AddAlias(ctx, req)
+	if err != nil {
+		return err
+	}
+	fmt.Println(a.GetId())
+	return nil
+}
+
+func List(cmd *cobra.Command, args []string) error {
+	req := &api.ListAliasesRequest{}
+	if len(args) > 0 {
+		req.Upstream = &args[0]
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.NewAliasesClient(conn).ListAliases(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, a := range resp.Aliases {
+		upstream := a.GetUpstream()
+		if upstream == "" {
+			upstream = "*"
+		}
+		fmt.Printf("%d\t%s\t%s\t%s\n", a.GetId(), upstream, a.GetName(), a.GetExpansion())
+	}
+	return nil
+}
+
+func Remove(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid alias id: %s", args[0])
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewAliasesClient(conn).RemoveAlias(ctx, &api.RemoveAliasRequest{Id: &id})
+	return err
+}
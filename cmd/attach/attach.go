@@ -0,0 +1,181 @@
+package attach
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+	"golang.org/x/text/encoding/unicode"
+
+	"github.com/stesla/iris/internal/telnet"
+)
+
+var (
+	addr             string
+	user             string
+	password         string
+	upstreamPassword string
+	spectate         bool
+	savePassword     bool
+)
+
+// keyringService is the service iris stores passwords under in the system
+// keyring.
+const keyringService = "iris"
+
+func AddToCommand(cmd *cobra.Command) {
+	attachCmd := &cobra.Command{
+		Use:   "attach UPSTREAM",
+		Short: "attach to an upstream from this terminal",
+		Long: `Attach connects to the proxy and attaches to an upstream, as any other client
+would, so that a session can be used from a shell without a MU client.
+
+The password comes from --password, the system keyring, or a prompt, in that
+order, and --save-password stores it in the keyring for next time. Passwords
+are kept under the upstream's name, or USER@UPSTREAM for a user's own. An owner
+connecting the upstream gives its password with --upstream-password, or it is
+read from the keyring.
+
+Type a line and press enter to send it. Ctrl-U erases the line, and Ctrl-] or
+Ctrl-D on an empty line detaches.`,
+		Args: cobra.ExactArgs(1),
+		RunE: Attach,
+	}
+	attachCmd.Flags().StringVar(&addr, "addr", "", "the proxy's address (default: the addr setting, on localhost)")
+	attachCmd.Flags().StringVarP(&user, "user", "u", "", "the user to attach as")
+	attachCmd.Flags().StringVar(&password, "password", "", "the password, which other users on this host may be able to see")
+	attachCmd.Flags().StringVar(&upstreamPassword, "upstream-password", "", "the upstream's password, for an owner to connect it")
+	attachCmd.Flags().BoolVar(&spectate, "spectate", false, "watch without sending input")
+	attachCmd.Flags().BoolVar(&savePassword, "save-password", false, "store the password in the system keyring")
+	cmd.AddCommand(attachCmd)
+}
+
+// handshake is the first line a client sends the proxy.
+type handshake struct {
+	Name             string `json:"name"`
+	User             string `json:"user,omitempty"`
+	Password         string `json:"password"`
+	UpstreamPassword string `json:"upstream_password,omitempty"`
+	Mode             string `json:"mode,omitempty"`
+}
+
+func Attach(cmd *cobra.Command, args []string) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return errors.New("attach needs a terminal")
+	}
+	hs, err := credentials(args[0])
+	if err != nil {
+		return err
+	}
+	tcp, err := net.Dial("tcp", proxyAddr())
+	if err != nil {
+		return err
+	}
+	s := newSession(tcp)
+	defer s.Close()
+
+	line, err := json.Marshal(hs)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return s.runTerminal()
+}
+
+func proxyAddr() string {
+	if addr != "" {
+		return addr
+	}
+	host, port, err := net.SplitHostPort(viper.GetString("addr"))
+	if err != nil || host == "" {
+		return net.JoinHostPort("localhost", port)
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func credentials(name string) (hs handshake, err error) {
+	hs = handshake{Name: name, User: user, UpstreamPassword: upstreamPassword}
+	if spectate {
+		hs.Mode = "spectator"
+	}
+	key := name
+	if user != "" {
+		key = user + "@" + name
+		if hs.UpstreamPassword == "" {
+			hs.UpstreamPassword, _ = keyring.Get(keyringService, name)
+		}
+	}
+	if hs.Password, err = lookupPassword(key); err != nil {
+		return
+	}
+	if savePassword {
+		if err = keyring.Set(keyringService, key, hs.Password); err != nil {
+			err = fmt.Errorf("error saving password: %w", err)
+		}
+	}
+	return
+}
+
+func lookupPassword(key string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+	if !savePassword {
+		if pw, err := keyring.Get(keyringService, key); err == nil {
+			return pw, nil
+		}
+	}
+	fmt.Fprintf(os.Stderr, "password for %s: ", key)
+	pw, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return string(pw), err
+}
+
+// session is the client's side of the telnet connection to the proxy.
+type session struct {
+	telnet.Conn
+	charset telnet.CharsetHandler
+	naws    telnet.NAWSHandler
+}
+
+func newSession(tcp net.Conn) *session {
+	s := &session{Conn: telnet.Wrap(context.Background(), tcp)}
+	s.RegisterHandler(&telnet.TransmitBinaryHandler{})
+	s.RegisterHandler(&s.charset)
+	s.RegisterHandler(&s.naws)
+	for _, opt := range []byte{telnet.SuppressGoAhead, telnet.EndOfRecord} {
+		s.GetOption(opt).Allow(true, true)
+	}
+	s.GetOption(telnet.Echo).AllowThem(true)
+	s.GetOption(telnet.NAWS).EnableUs(s.Context())
+	telnet.TopicOption.Subscribe(s, s.handleOption)
+	telnet.TopicCharsetAccepted.Subscribe(s, s.handleCharsetAccepted)
+	return s
+}
+
+func (s *session) handleOption(_ context.Context, opt telnet.OptionData) error {
+	if opt.Option() == telnet.Charset && opt.ResolvedUs && opt.EnabledForUs() {
+		s.charset.RequestEncoding(unicode.UTF8)
+	}
+	return nil
+}
+
+func (s *session) handleCharsetAccepted(context.Context, telnet.CharsetData) error {
+	s.GetOption(telnet.TransmitBinary).EnableBoth(s.Context())
+	return nil
+}
+
+// echoing reports whether what is typed should be shown, which it is unless
+// the server has said it will echo it, usually to hide a password.
+func (s *session) echoing() bool {
+	return !s.GetOption(telnet.Echo).EnabledForThem()
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package attach
+
+// watchResize does nothing where there is no SIGWINCH. The size sent when
+// attaching is all the game gets.
+func watchResize(func()) (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package attach
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchResize calls resize whenever the terminal changes size, until stop is
+// called.
+func watchResize(resize func()) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	go func() {
+		for range ch {
+			resize()
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,182 @@
+package attach
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"github.com/stesla/iris/internal/telnet"
+)
+
+// Keys the line editor handles.
+const (
+	keyInterrupt = 0x03
+	keyEOF       = 0x04
+	keyBackspace = 0x08
+	keyKillLine  = 0x15
+	keyEscape    = 0x1b
+	keyDetach    = 0x1d
+	keyDelete    = 0x7f
+)
+
+// terminal puts the terminal in raw mode and edits a line of input, so that
+// output from the game can be shown without mixing it up with what is being
+// typed.
+type terminal struct {
+	*session
+	mux  sync.Mutex
+	line []rune
+	// shown is how many runes of line are on the screen.
+	shown int
+}
+
+func (s *session) runTerminal() error {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	t := &terminal{session: s}
+	t.resize()
+	stop := watchResize(t.resize)
+	defer stop()
+
+	done := make(chan error, 1)
+	go func() { done <- t.readInput() }()
+	go func() { done <- t.writeOutput() }()
+	err = <-done
+	fmt.Fprint(os.Stdout, "\r\n")
+	return err
+}
+
+func (t *terminal) resize() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return
+	}
+	t.naws.SetSize(telnet.WindowSize{Width: uint16(width), Height: uint16(height)})
+}
+
+func (t *terminal) writeOutput() error {
+	buf := make([]byte, 4096)
+	for {
+		n, err := t.Read(buf)
+		if n > 0 {
+			t.mux.Lock()
+			t.hideLine()
+			os.Stdout.Write(bytes.ReplaceAll(buf[:n], []byte{'\n'}, []byte{'\r', '\n'}))
+			t.showLine()
+			t.mux.Unlock()
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func (t *terminal) readInput() error {
+	in := bufio.NewReader(os.Stdin)
+	for {
+		r, _, err := in.ReadRune()
+		if err != nil {
+			return err
+		}
+		switch r {
+		case keyDetach:
+			return nil
+		case keyEOF:
+			if t.empty() {
+				return nil
+			}
+		case keyEscape:
+			skipEscape(in)
+		case '\r', '\n':
+			if err := t.send(); err != nil {
+				return err
+			}
+		default:
+			t.edit(r)
+		}
+	}
+}
+
+// skipEscape discards the rest of an escape sequence, such as an arrow key.
+func skipEscape(in *bufio.Reader) {
+	if b, err := in.ReadByte(); err != nil || b != '[' {
+		return
+	}
+	for {
+		b, err := in.ReadByte()
+		if err != nil || (b >= 0x40 && b <= 0x7e) {
+			return
+		}
+	}
+}
+
+func (t *terminal) empty() bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return len(t.line) == 0
+}
+
+func (t *terminal) edit(r rune) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.hideLine()
+	switch r {
+	case keyBackspace, keyDelete:
+		if len(t.line) > 0 {
+			t.line = t.line[:len(t.line)-1]
+		}
+	case keyKillLine, keyInterrupt:
+		t.line = nil
+	default:
+		if r >= ' ' && r != utf8.RuneError {
+			t.line = append(t.line, r)
+		}
+	}
+	t.showLine()
+}
+
+func (t *terminal) send() error {
+	t.mux.Lock()
+	line := string(t.line)
+	t.hideLine()
+	t.line = nil
+	if t.echoing() {
+		fmt.Fprint(os.Stdout, line)
+	}
+	fmt.Fprint(os.Stdout, "\r\n")
+	t.mux.Unlock()
+	_, err := io.WriteString(t, line+"\n")
+	return err
+}
+
+// hideLine erases the line being typed, leaving the cursor where it started.
+func (t *terminal) hideLine() {
+	if t.shown > 0 {
+		fmt.Fprintf(os.Stdout, "\x1b[%dD\x1b[K", t.shown)
+		t.shown = 0
+	}
+}
+
+func (t *terminal) showLine() {
+	if t.echoing() {
+		fmt.Fprint(os.Stdout, string(t.line))
+		t.shown = len(t.line)
+	} else if len(t.line) > 0 {
+		fmt.Fprint(os.Stdout, strings.Repeat("*", len(t.line)))
+		t.shown = len(t.line)
+	}
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+	"github.com/stesla/iris/internal/logs"
+)
+
+var (
+	pkgcmd = &cobra.Command{
+		Use:   "audit COMMAND",
+		Short: "commands for reading the audit log",
+		Long: `The audit log records who did what, and from where:
+
+  login, login-failed       a client authenticating to an upstream
+  attach, detach            a client joining or leaving an upstream
+  connect, disconnect       an upstream's connection to its game
+  upstream-add, upstream-edit, user-add, user-remove, grant, revoke,
+  token-create, token-revoke
+                            changes made through the API, where the user is
+                            the token, certificate or local user that made
+                            them
+  send                      text sent to an upstream through the API, which
+                            is not recorded`,
+	}
+	since    string
+	upstream string
+	kind     string
+)
+
+func init() {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "list audit events",
+		Args:  cobra.NoArgs,
+		RunE:  List,
+	}
+	listCmd.Flags().StringVar(&since, "since", "24h", "how long ago (e.g. 1h), or a date or time (e.g. 2026-10-01), to list from")
+	listCmd.Flags().StringVar(&upstream, "upstream", "", "list only the events for an upstream")
+	listCmd.Flags().StringVar(&kind, "kind", "", "list only the events of a kind")
+	pkgcmd.AddCommand(listCmd)
+}
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(pkgcmd)
+}
+
+func List(cmd *cobra.Command, args []string) error {
+	req := &api.ListAuditEventsRequest{}
+	if since != "" {
+		t, err := parseSince(since)
+		if err != nil {
+			return err
+		}
+		req.Since = timestamppb.New(t)
+	}
+	if upstream != "" {
+		req.Upstream = &upstream
+	}
+	if kind != "" {
+		req.Kind = &kind
+	}
+
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.NewAuditClient(conn).ListAuditEvents(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, e := range resp.Events {
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Time.AsTime().Local().Format(time.DateTime),
+			e.GetKind(), orDash(e.GetUser()), orDash(e.GetRemote()), orDash(e.GetUpstream()), e.GetDetail())
+	}
+	return nil
+}
+
+func parseSince(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.ParseInLocation(logs.DateFormat, s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateTime, s, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, fmt.Errorf("invalid --since: %q", s)
+	}
+	return t, nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
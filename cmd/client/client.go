@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Dial connects to the server's local socket if grpc.client.addr starts with
+// "unix:", and otherwise to its TCP listener over TLS, authenticating with
+// grpc.client.token or a client certificate.
+func Dial() (*grpc.ClientConn, error) {
+	addr := viper.GetString("grpc.client.addr")
+	if strings.HasPrefix(addr, "unix:") {
+		return grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	config, err := tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(config))}
+	if token := viper.GetString("grpc.client.token"); token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	return grpc.NewClient(addr, opts...)
+}
+
+func tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName: viper.GetString("grpc.client.tls.server_name"),
+		MinVersion: tls.VersionTLS12,
+	}
+	if caFile := viper.GetString("grpc.client.tls.ca"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", caFile)
+		}
+	}
+	certFile, keyFile := viper.GetString("grpc.client.tls.cert"), viper.GetString("grpc.client.tls.key")
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (bearerToken) RequireTransportSecurity() bool {
+	return true
+}
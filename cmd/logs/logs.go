@@ -0,0 +1,274 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+	"github.com/stesla/iris/internal/logs"
+)
+
+var (
+	pkgcmd = &cobra.Command{
+		Use:   "logs [OPTIONS] COMMAND",
+		Short: "commands for reading session logs",
+	}
+	follow bool
+	format string
+	from   string
+	scene  string
+	to     string
+)
+
+func init() {
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "list upstreams with logs and their date ranges",
+		Args:  cobra.NoArgs,
+		RunE:  List,
+	})
+
+	tailCmd := &cobra.Command{
+		Use:   "tail NAME",
+		Short: "print the history of a connected upstream",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Tail,
+	}
+	tailCmd.Flags().BoolVarP(&follow, "follow", "f", false, "keep printing output as it arrives")
+	pkgcmd.AddCommand(tailCmd)
+
+	exportCmd := &cobra.Command{
+		Use:   "export NAME",
+		Short: "export the logs of an upstream as a single transcript",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Export,
+	}
+	exportCmd.Flags().StringVar(&format, "format", string(logs.FormatText), "output format (html, txt, json)")
+	exportCmd.Flags().StringVar(&from, "from", "", "start date or time (e.g. 2026-10-01 or 2026-10-01T20:00:00-05:00)")
+	exportCmd.Flags().StringVar(&to, "to", "", "end date or time, dates are inclusive")
+	exportCmd.Flags().StringVar(&scene, "scene", "", "export only the named scene")
+	exportCmd.MarkFlagsMutuallyExclusive("scene", "from")
+	exportCmd.MarkFlagsMutuallyExclusive("scene", "to")
+	pkgcmd.AddCommand(exportCmd)
+
+	sceneCmd := &cobra.Command{
+		Use:   "scene COMMAND",
+		Short: "commands for managing scenes",
+	}
+	sceneCmd.AddCommand(&cobra.Command{
+		Use:   "list [UPSTREAM]",
+		Short: "list recorded scenes",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  ListScenes,
+	})
+	sceneAddCmd := &cobra.Command{
+		Use:   "add UPSTREAM NAME",
+		Short: "mark a scene in existing logs",
+		Long: `Mark a scene in existing logs.
+
+Scenes that iris records are exported between the separators it writes
+where they start and stop. A scene added here has no separators, and only
+the separators in a log, where it was opened or closed or a scene or
+command was recorded, carry a time. So a scene that starts or ends between
+them is cut at an estimate, by how far through the bytes between them
+each line is, so give it a little room.`,
+		Args: cobra.ExactArgs(2),
+		RunE: AddScene,
+	}
+	sceneAddCmd.Flags().StringVar(&from, "from", "", "start time of the scene")
+	sceneAddCmd.Flags().StringVar(&to, "to", "", "end time of the scene")
+	sceneAddCmd.MarkFlagRequired("from")
+	sceneCmd.AddCommand(sceneAddCmd)
+	pkgcmd.AddCommand(sceneCmd)
+}
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(pkgcmd)
+}
+
+func List(cmd *cobra.Command, args []string) error {
+	index, err := logs.Index(viper.GetString("log.dir"))
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(index))
+	for name := range index {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		files := index[name]
+		first, last := files[0].Date, files[len(files)-1].Date
+		fmt.Printf("%s\t%s\t%s\n", name, first.Format(logs.DateFormat), last.Format(logs.DateFormat))
+	}
+	return nil
+}
+
+func Tail(cmd *cobra.Command, args []string) error {
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := api.NewLogsClient(conn).Tail(ctx, &api.TailRequest{
+		Name:   &args[0],
+		Follow: &follow,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		data, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		os.Stdout.Write(data.Data)
+	}
+}
+
+func Export(cmd *cobra.Command, args []string) error {
+	f, err := logs.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+	start, end, err := exportRange(args[0])
+	if err != nil {
+		return err
+	}
+	index, err := logs.Index(viper.GetString("log.dir"))
+	if err != nil {
+		return err
+	}
+	files, found := index[args[0]]
+	if !found {
+		return fmt.Errorf("no logs for upstream: %s", args[0])
+	}
+	e := logs.NewExporter(os.Stdout, f, args[0])
+	if scene != "" {
+		return logs.ExportScene(e, files, scene, start, end)
+	}
+	return logs.Export(e, files, start, end)
+}
+
+func exportRange(upstream string) (start, end time.Time, err error) {
+	if scene == "" {
+		if start, err = parseTime(from, false); err != nil {
+			return
+		}
+		end, err = parseTime(to, true)
+		return
+	}
+	scenes, err := listScenes(upstream)
+	if err != nil {
+		return
+	}
+	for _, s := range slices.Backward(scenes) {
+		if s.GetName() == scene {
+			start = s.StartedAt.AsTime()
+			if s.StoppedAt != nil {
+				end = s.StoppedAt.AsTime()
+			}
+			return
+		}
+	}
+	err = fmt.Errorf("no scene named %q for upstream: %s", scene, upstream)
+	return
+}
+
+func ListScenes(cmd *cobra.Command, args []string) error {
+	var upstream string
+	if len(args) > 0 {
+		upstream = args[0]
+	}
+	scenes, err := listScenes(upstream)
+	if err != nil {
+		return err
+	}
+	for _, s := range scenes {
+		stopped := "-"
+		if s.StoppedAt != nil {
+			stopped = s.StoppedAt.AsTime().Local().Format(logs.TimeFormat)
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\n", s.GetUpstream(), s.GetName(),
+			s.StartedAt.AsTime().Local().Format(logs.TimeFormat), stopped)
+	}
+	return nil
+}
+
+func listScenes(upstream string) ([]*api.Scene, error) {
+	conn, err := client.Dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	req := &api.ListScenesRequest{}
+	if upstream != "" {
+		req.Upstream = &upstream
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.NewLogsClient(conn).ListScenes(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Scenes, nil
+}
+
+func AddScene(cmd *cobra.Command, args []string) error {
+	start, err := parseTime(from, false)
+	if err != nil {
+		return err
+	}
+	end, err := parseTime(to, true)
+	if err != nil {
+		return err
+	}
+	req := &api.Scene{
+		Upstream:  &args[0],
+		Name:      &args[1],
+		StartedAt: timestamppb.New(start),
+	}
+	if !end.IsZero() {
+		req.StoppedAt = timestamppb.New(end)
+	}
+
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewLogsClient(conn).AddScene(ctx, req)
+	return err
+}
+
+func parseTime(s string, end bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(logs.DateFormat, s, time.Local); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+)
+
+var pkgcmd = &cobra.Command{
+	Use:   "notify COMMAND",
+	Short: "commands for notifications",
+	Long: `Notifications are raised by triggers with the notify action, so the rules
+for what to notify about are an upstream's notify triggers (see iris trigger).
+While nobody is attached to an upstream, they are also posted as JSON to
+notify.webhook, except during notify.quiet_hours (e.g. "22:00-07:00"), and
+shown to the next client to attach. The same text from the same upstream is
+only delivered once within notify.dedup.
+
+An upstream can have its own quiet hours or dedup in notify.upstreams:
+
+  notify:
+    quiet_hours: "22:00-07:00"
+    upstreams:
+      - upstream: work
+        quiet_hours: "18:00-09:00"
+        dedup: 1m`,
+}
+
+func init() {
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "watch [UPSTREAM]",
+		Short: "print notifications as they are raised",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  Watch,
+	})
+}
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(pkgcmd)
+}
+
+func Watch(cmd *cobra.Command, args []string) error {
+	req := &api.WatchNotificationsRequest{}
+	if len(args) > 0 {
+		req.Upstream = &args[0]
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := api.NewNotificationsClient(conn).Watch(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		n, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fmt.Printf("%s\t%s\t%s\n", n.Time.AsTime().Local().Format(time.DateTime), n.GetUpstream(), n.GetText())
+	}
+}
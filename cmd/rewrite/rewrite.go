@@ -0,0 +1,130 @@
+package rewrite
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+)
+
+var (
+	pkgcmd = &cobra.Command{
+		Use:   "rewrite [OPTIONS] COMMAND",
+		Short: "commands for managing rewrite rules",
+		Long: `Rewrite rules change the lines from an upstream before clients see them,
+and with --log, before they are written to the log as well. Patterns are
+matched against the text without ANSI codes. The actions are:
+
+  gag                      hide the line
+  substitute REPLACEMENT   replace each match, in which $1 or ${name} refer
+                           to capture groups
+  highlight [STYLE]        highlight each match, e.g. "bold", "red",
+                           "white on blue"
+
+Rules without --log also apply to the history replayed to clients, so a gag
+can hide channel spam from clients while keeping it in the log.`,
+	}
+	log bool
+)
+
+func init() {
+	addCmd := &cobra.Command{
+		Use:   "add UPSTREAM PATTERN ACTION [ARGUMENT]",
+		Short: "add a rewrite rule to an upstream",
+		Args:  cobra.RangeArgs(3, 4),
+		RunE:  Add,
+	}
+	addCmd.Flags().BoolVar(&log, "log", false, "apply the rule to the log as well")
+	pkgcmd.AddCommand(addCmd)
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "list [UPSTREAM]",
+		Short: "list rewrite rules",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  List,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "remove ID",
+		Short: "remove a rewrite rule",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Remove,
+	})
+}
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(pkgcmd)
+}
+
+func Add(cmd *cobra.Command, args []string) error {
+	req := &api.Rewrite{
+		Upstream: &args[0],
+		Pattern:  &args[1],
+		Action:   &args[2],
+		Log:      &log,
+	}
+	if len(args) > 3 {
+		req.Argument = &args[3]
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	r, err := api.NewRewritesClient(conn).AddRewrite(ctx, req)
+	if err != nil {
+		return err
+	}
+	fmt.Println(r.GetId())
+	return nil
+}
+
+func List(cmd *cobra.Command, args []string) error {
+	req := &api.ListRewritesRequest{}
+	if len(args) > 0 {
+		req.Upstream = &args[0]
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.NewRewritesClient(conn).ListRewrites(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, r := range resp.Rewrites {
+		target := "clients"
+		if r.GetLog() {
+			target = "clients+log"
+		}
+		fmt.Printf("%d\t%s\t%q\t%s\t%s\t%s\n", r.GetId(), r.GetUpstream(), r.GetPattern(), r.GetAction(), target, r.GetArgument())
+	}
+	return nil
+}
+
+func Remove(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid rewrite id: %s", args[0])
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewRewritesClient(conn).RemoveRewrite(ctx, &api.RemoveRewriteRequest{Id: &id})
+	return err
+}
@@ -6,8 +6,20 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/stesla/iris/cmd/alias"
+	"github.com/stesla/iris/cmd/attach"
+	"github.com/stesla/iris/cmd/audit"
+	"github.com/stesla/iris/cmd/logs"
+	"github.com/stesla/iris/cmd/notify"
+	"github.com/stesla/iris/cmd/rewrite"
 	"github.com/stesla/iris/cmd/serve"
+	"github.com/stesla/iris/cmd/sessions"
+	"github.com/stesla/iris/cmd/status"
+	"github.com/stesla/iris/cmd/timer"
+	"github.com/stesla/iris/cmd/token"
+	"github.com/stesla/iris/cmd/trigger"
 	"github.com/stesla/iris/cmd/upstream"
+	"github.com/stesla/iris/cmd/user"
 )
 
 var (
@@ -25,14 +37,60 @@ func init() {
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
 	viper.SetDefault("addr", ":4042")
+	viper.SetDefault("alias.separator", ";")
+	viper.SetDefault("auth.allow", []string{})
+	viper.SetDefault("auth.deny", []string{})
+	viper.SetDefault("auth.lockout.initial", "30s")
+	viper.SetDefault("auth.lockout.max", "1h")
+	viper.SetDefault("auth.lockout.threshold", 5)
+	viper.SetDefault("auth.lockout.upstream_delay", "5s")
+	viper.SetDefault("auth.lockout.upstream_threshold", 20)
+	viper.SetDefault("auth.max_pending", 32)
+	viper.SetDefault("auth.require_user", false)
+	viper.SetDefault("auth.timeout", "30s")
+	viper.SetDefault("command.prefix", "/iris")
 	viper.SetDefault("db", "./iris.db")
-	viper.SetDefault("grpc.client.addr", "localhost:40042")
-	viper.SetDefault("grpc.server.addr", ":40042")
+	viper.SetDefault("grpc.client.addr", "unix:iris.sock")
+	viper.SetDefault("grpc.client.tls.ca", "")
+	viper.SetDefault("grpc.client.tls.cert", "")
+	viper.SetDefault("grpc.client.tls.key", "")
+	viper.SetDefault("grpc.client.tls.server_name", "")
+	viper.SetDefault("grpc.client.token", "")
+	viper.SetDefault("grpc.server.addr", "")
+	viper.SetDefault("grpc.server.socket", "iris.sock")
+	viper.SetDefault("grpc.server.tls.cert", "")
+	viper.SetDefault("grpc.server.tls.client_ca", "")
+	viper.SetDefault("grpc.server.tls.key", "")
+	viper.SetDefault("input.floor", false)
+	viper.SetDefault("input.floor_idle", "2m")
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.dir", "./logs")
+	viper.SetDefault("log.history_size", 20*1024)
+	viper.SetDefault("log.input", true)
+	viper.SetDefault("log.input_redact", []string{`(?i)^\s*(connect|co|cd|ch|create|cr)\s`})
+	viper.SetDefault("metrics.addr", "")
+	viper.SetDefault("notify.dedup", "5m")
+	viper.SetDefault("notify.quiet_hours", "")
+	viper.SetDefault("notify.upstreams", []any{})
+	viper.SetDefault("notify.webhook", "")
+	viper.SetDefault("prompt.delay", "250ms")
+	viper.SetDefault("record.dir", "")
+	viper.SetDefault("spectator.notice", true)
 
+	alias.AddToCommand(rootCmd)
+	attach.AddToCommand(rootCmd)
+	audit.AddToCommand(rootCmd)
+	logs.AddToCommand(rootCmd)
+	notify.AddToCommand(rootCmd)
+	rewrite.AddToCommand(rootCmd)
 	serve.AddToCommand(rootCmd)
+	sessions.AddToCommand(rootCmd)
+	status.AddToCommand(rootCmd)
+	timer.AddToCommand(rootCmd)
+	token.AddToCommand(rootCmd)
+	trigger.AddToCommand(rootCmd)
 	upstream.AddToCommand(rootCmd)
+	user.AddToCommand(rootCmd)
 }
 
 func initConfig() {
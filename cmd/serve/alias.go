@@ -0,0 +1,78 @@
+package serve
+
+import (
+	"github.com/stesla/iris/internal/alias"
+)
+
+func (p *SessionPool) loadAliases(key string) (*alias.Set, error) {
+	// Aliases for every upstream come first, so that the upstream's own
+	// aliases replace them.
+	rows, err := p.db.Query(
+		"SELECT id, upstream, name, expansion FROM aliases WHERE upstream IN ('', ?) ORDER BY upstream != '', id",
+		key,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var aliases []alias.Alias
+	for rows.Next() {
+		var a alias.Alias
+		if err := rows.Scan(&a.ID, &a.Upstream, &a.Name, &a.Expansion); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return alias.NewSet(aliases, p.config().aliasSeparator), nil
+}
+
+// ReloadAliases reads the aliases for a connected upstream from the database
+// again, or for every connected upstream if key is empty.
+func (p *SessionPool) ReloadAliases(key string) error {
+	if key != "" {
+		s, found := p.upstreamWithKey(key)
+		if !found {
+			return nil
+		}
+		return s.loadAliases()
+	}
+	p.Lock()
+	streams := make([]*upstream, 0, len(p.streams))
+	for _, s := range p.streams {
+		if s.ready.Load() {
+			streams = append(streams, s)
+		}
+	}
+	p.Unlock()
+	for _, s := range streams {
+		if err := s.loadAliases(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *upstream) loadAliases() error {
+	aliases, err := s.pool.loadAliases(s.key)
+	if err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.aliases = aliases
+	return nil
+}
+
+// expandAlias returns the commands to send upstream for a line of input.
+func (s *upstream) expandAlias(line string) []string {
+	s.mux.Lock()
+	aliases := s.aliases
+	s.mux.Unlock()
+	if commands, ok := aliases.Expand(line); ok {
+		return commands
+	}
+	return []string{line}
+}
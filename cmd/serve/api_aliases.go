@@ -0,0 +1,82 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/alias"
+)
+
+type aliasesServer struct {
+	api.UnimplementedAliasesServer
+	db       *sql.DB
+	sessions *SessionPool
+}
+
+// AddAlias adds an alias, or replaces the expansion of the alias with the
+// same name for the same upstream.
+func (s *aliasesServer) AddAlias(_ context.Context, r *api.Alias) (*api.Alias, error) {
+	a := alias.Alias{
+		Upstream:  r.GetUpstream(),
+		Name:      r.GetName(),
+		Expansion: r.GetExpansion(),
+	}
+	if err := alias.Validate(a); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var id int64
+	row := s.db.QueryRow(
+		`INSERT INTO aliases (upstream, name, expansion) VALUES (?, ?, ?)
+		ON CONFLICT (upstream, name) DO UPDATE SET expansion=excluded.expansion
+		RETURNING id`,
+		a.Upstream, a.Name, a.Expansion,
+	)
+	if err := row.Scan(&id); err != nil {
+		return nil, err
+	}
+	r.Id = &id
+	return r, s.sessions.ReloadAliases(a.Upstream)
+}
+
+func (s *aliasesServer) RemoveAlias(_ context.Context, r *api.RemoveAliasRequest) (*emptypb.Empty, error) {
+	var upstream string
+	row := s.db.QueryRow("SELECT upstream FROM aliases WHERE id=?", r.GetId())
+	if err := row.Scan(&upstream); err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "no alias with id: %d", r.GetId())
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec("DELETE FROM aliases WHERE id=?", r.GetId()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, s.sessions.ReloadAliases(upstream)
+}
+
+func (s *aliasesServer) ListAliases(_ context.Context, r *api.ListAliasesRequest) (*api.ListAliasesResponse, error) {
+	query := "SELECT id, upstream, name, expansion FROM aliases"
+	args := []any{}
+	if r.Upstream != nil {
+		query += " WHERE upstream=?"
+		args = append(args, *r.Upstream)
+	}
+	query += " ORDER BY upstream, name"
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := &api.ListAliasesResponse{}
+	for rows.Next() {
+		a := &api.Alias{}
+		if err := rows.Scan(&a.Id, &a.Upstream, &a.Name, &a.Expansion); err != nil {
+			return nil, err
+		}
+		result.Aliases = append(result.Aliases, a)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,50 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stesla/iris/api"
+)
+
+type auditServer struct {
+	api.UnimplementedAuditServer
+	db *sql.DB
+}
+
+func (s *auditServer) ListAuditEvents(_ context.Context, r *api.ListAuditEventsRequest) (*api.ListAuditEventsResponse, error) {
+	query := "SELECT time, kind, user, remote, upstream, detail FROM audit_events WHERE 1=1"
+	args := []any{}
+	if r.Since != nil {
+		query += " AND time >= ?"
+		args = append(args, r.Since.AsTime().UTC())
+	}
+	if r.Upstream != nil {
+		query += " AND upstream=?"
+		args = append(args, r.GetUpstream())
+	}
+	if r.Kind != nil {
+		query += " AND kind=?"
+		args = append(args, r.GetKind())
+	}
+	query += " ORDER BY time, id"
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := &api.ListAuditEventsResponse{}
+	for rows.Next() {
+		var t time.Time
+		e := &api.AuditEvent{Kind: new(string), User: new(string), Remote: new(string), Upstream: new(string), Detail: new(string)}
+		if err := rows.Scan(&t, e.Kind, e.User, e.Remote, e.Upstream, e.Detail); err != nil {
+			return nil, err
+		}
+		e.Time = timestamppb.New(t)
+		result.Events = append(result.Events, e)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,90 @@
+package serve
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stesla/iris/api"
+)
+
+func TestListAuditEvents(t *testing.T) {
+	resetConfig(t)
+	db := migratedDB(t)
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	// Inserted out of order, with two at the same time, to check the sort.
+	events := []struct {
+		at     time.Duration
+		kind   string
+		detail string
+		game   bool
+	}{
+		{2 * time.Minute, auditAttach, "third", true},
+		{0, auditLogin, "first", true},
+		{time.Minute, auditLogin, "second", false},
+		{2 * time.Minute, auditDetach, "fourth", true},
+		{3 * time.Minute, auditLogin, "fifth", true},
+	}
+	for _, e := range events {
+		upstream := "other"
+		if e.game {
+			upstream = "game"
+		}
+		_, err := db.Exec("INSERT INTO audit_events (time, kind, upstream, detail) VALUES (?, ?, ?, ?)",
+			start.Add(e.at), e.kind, upstream, e.detail)
+		require.NoError(t, err)
+	}
+	s := &auditServer{db: db}
+
+	// Since is compared in UTC, whatever zone it was given in.
+	est := time.FixedZone("EST", -5*60*60)
+	var tests = []struct {
+		name     string
+		request  *api.ListAuditEventsRequest
+		expected []string
+	}{
+		{"all", &api.ListAuditEventsRequest{}, []string{"first", "second", "third", "fourth", "fifth"}},
+		{"since", &api.ListAuditEventsRequest{Since: timestamppb.New(start.Add(2 * time.Minute).In(est))}, []string{"third", "fourth", "fifth"}},
+		{"upstream", &api.ListAuditEventsRequest{Upstream: proto.String("other")}, []string{"second"}},
+		{"kind", &api.ListAuditEventsRequest{Kind: proto.String(auditLogin)}, []string{"first", "second", "fifth"}},
+		{"all filters", &api.ListAuditEventsRequest{
+			Since:    timestamppb.New(start.Add(time.Second)),
+			Upstream: proto.String("game"),
+			Kind:     proto.String(auditLogin),
+		}, []string{"fifth"}},
+		{"nothing matches", &api.ListAuditEventsRequest{Kind: proto.String(auditRevoke)}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := s.ListAuditEvents(context.Background(), test.request)
+			require.NoError(t, err)
+			var details []string
+			for _, e := range resp.GetEvents() {
+				details = append(details, e.GetDetail())
+			}
+			require.Equal(t, test.expected, details)
+		})
+	}
+}
+
+func TestAuditRecordsCaller(t *testing.T) {
+	resetConfig(t)
+	db := migratedDB(t)
+	ctx := context.WithValue(context.Background(), callerKey{}, "token:laptop")
+	before := time.Now()
+	auditCall(ctx, db, auditGrant, "game", "alice read-only")
+
+	resp, err := (&auditServer{db: db}).ListAuditEvents(context.Background(), &api.ListAuditEventsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetEvents(), 1)
+	e := resp.GetEvents()[0]
+	require.Equal(t, auditGrant, e.GetKind())
+	require.Equal(t, "token:laptop", e.GetUser())
+	require.Equal(t, "game", e.GetUpstream())
+	require.Equal(t, "alice read-only", e.GetDetail())
+	require.WithinRange(t, e.GetTime().AsTime(), before.Add(-time.Second), time.Now().Add(time.Second))
+}
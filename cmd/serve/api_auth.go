@@ -0,0 +1,176 @@
+package serve
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/stesla/iris/internal/auth"
+)
+
+// serverTLSConfig returns the TLS configuration for the API's TCP listener.
+func serverTLSConfig() (*tls.Config, error) {
+	certFile, keyFile := viper.GetString("grpc.server.tls.cert"), viper.GetString("grpc.server.tls.key")
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("grpc.server.addr requires grpc.server.tls.cert and grpc.server.tls.key")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if caFile := viper.GetString("grpc.server.tls.client_ca"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", caFile)
+		}
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config, nil
+}
+
+// socketCredentials identifies the clients on the local socket by the user
+// running them.
+type socketCredentials struct {
+	credentials.TransportCredentials
+}
+
+func newSocketCredentials() credentials.TransportCredentials {
+	return socketCredentials{insecure.NewCredentials()}
+}
+
+func (c socketCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	uid, err := auth.PeerUID(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, socketInfo{
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+		uid:            uid,
+	}, nil
+}
+
+func (c socketCredentials) Clone() credentials.TransportCredentials {
+	return socketCredentials{c.TransportCredentials.Clone()}
+}
+
+type socketInfo struct {
+	credentials.CommonAuthInfo
+	uid int
+}
+
+func (socketInfo) AuthType() string {
+	return "unix"
+}
+
+// apiAuth identifies who is calling the API, and rejects the calls over TCP
+// that have neither a verified client certificate nor a valid token.
+type apiAuth struct {
+	db *sql.DB
+}
+
+type callerKey struct{}
+
+// apiCaller returns who made an API call, such as "token:laptop".
+func apiCaller(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// apiRemote returns where an API call came from.
+func apiRemote(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		if _, ok := p.AuthInfo.(socketInfo); ok {
+			return "local"
+		}
+		return p.Addr.String()
+	}
+	return ""
+}
+
+func (a *apiAuth) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *apiAuth) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, callerStream{ss, ctx})
+}
+
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s callerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (a *apiAuth) authenticate(ctx context.Context, method string) (context.Context, error) {
+	caller, err := a.caller(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Str("method", method).Msg("api call rejected")
+		return nil, err
+	}
+	logger.Debug().Str("caller", caller).Str("method", method).Msg("api call")
+	return context.WithValue(ctx, callerKey{}, caller), nil
+}
+
+func (a *apiAuth) caller(ctx context.Context) (string, error) {
+	if p, ok := peer.FromContext(ctx); ok {
+		switch info := p.AuthInfo.(type) {
+		case socketInfo:
+			if u, err := user.LookupId(strconv.Itoa(info.uid)); err == nil {
+				return "local:" + u.Username, nil
+			}
+			return "local:" + strconv.Itoa(info.uid), nil
+		case credentials.TLSInfo:
+			if len(info.State.VerifiedChains) > 0 {
+				cert := info.State.VerifiedChains[0][0]
+				return "cert:" + cert.Subject.CommonName, nil
+			}
+		}
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		token, found := strings.CutPrefix(v, "Bearer ")
+		if !found {
+			continue
+		}
+		var name string
+		row := a.db.QueryRowContext(ctx, "SELECT name FROM tokens WHERE hash=?", auth.HashToken(token))
+		if err := row.Scan(&name); err == nil {
+			return "token:" + name, nil
+		} else if err != sql.ErrNoRows {
+			return "", err
+		}
+	}
+	return "", status.Error(codes.Unauthenticated, "a valid token or client certificate is required")
+}
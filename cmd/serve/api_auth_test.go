@@ -0,0 +1,156 @@
+package serve
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/stesla/iris/api"
+)
+
+// testCert is a certificate for the tests, and the key it was signed with.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	tls  tls.Certificate
+}
+
+// newTestCert issues a certificate for name, signed by parent, or by itself if
+// parent is nil.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, tls: tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}}
+}
+
+// writePEM writes the certificate and its key to dir, and returns their paths.
+func (c *testCert) writePEM(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	certFile = filepath.Join(dir, c.cert.Subject.CommonName+".crt")
+	keyFile = filepath.Join(dir, c.cert.Subject.CommonName+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0600))
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+	return
+}
+
+// serveTestAPI serves the tokens API on l, behind the same interceptors as
+// the real listeners.
+func serveTestAPI(t *testing.T, db *sql.DB, l net.Listener, creds credentials.TransportCredentials) {
+	a := &apiAuth{db: db}
+	s := grpc.NewServer(grpc.Creds(creds), grpc.UnaryInterceptor(a.unary), grpc.StreamInterceptor(a.stream))
+	api.RegisterTokensServer(s, &tokensServer{db: db})
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+}
+
+func lastAuditUser(t *testing.T, db *sql.DB) string {
+	t.Helper()
+	var user string
+	require.NoError(t, db.QueryRow("SELECT user FROM audit_events ORDER BY id DESC LIMIT 1").Scan(&user))
+	return user
+}
+
+func TestAPIAuthOverTCP(t *testing.T) {
+	resetConfig(t)
+	db := migratedDB(t)
+	dir := t.TempDir()
+	ca := newTestCert(t, "iris-ca", nil)
+	server := newTestCert(t, "bufnet", ca)
+	caFile, _ := ca.writePEM(t, dir)
+	certFile, keyFile := server.writePEM(t, dir)
+	viper.Set("grpc.server.tls.cert", certFile)
+	viper.Set("grpc.server.tls.key", keyFile)
+	viper.Set("grpc.server.tls.client_ca", caFile)
+	config, err := serverTLSConfig()
+	require.NoError(t, err)
+
+	l := bufconn.Listen(1 << 16)
+	serveTestAPI(t, db, l, credentials.NewTLS(config))
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	dial := func(certs ...tls.Certificate) api.TokensClient {
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+			grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+				RootCAs:      roots,
+				ServerName:   "bufnet",
+				Certificates: certs,
+			})),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return api.NewTokensClient(conn)
+	}
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	anonymous := dial()
+	_, err = anonymous.ListTokens(context.Background(), &emptypb.Empty{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = anonymous.ListTokens(withToken("not-a-token"), &emptypb.Empty{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	client := dial(newTestCert(t, "alice", ca).tls)
+	resp, err := client.CreateToken(context.Background(), &api.CreateTokenRequest{Name: proto.String("laptop")})
+	require.NoError(t, err)
+	require.Equal(t, "cert:alice", lastAuditUser(t, db))
+
+	_, err = anonymous.CreateToken(withToken(resp.GetToken()), &api.CreateTokenRequest{Name: proto.String("phone")})
+	require.NoError(t, err)
+	require.Equal(t, "token:laptop", lastAuditUser(t, db))
+
+	_, err = client.RevokeToken(context.Background(), &api.RevokeTokenRequest{Name: proto.String("laptop")})
+	require.NoError(t, err)
+	_, err = anonymous.ListTokens(withToken(resp.GetToken()), &emptypb.Empty{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// A certificate from anyone else is not enough.
+	_, err = dial(newTestCert(t, "mallory", nil).tls).ListTokens(context.Background(), &emptypb.Empty{})
+	require.Error(t, err)
+}
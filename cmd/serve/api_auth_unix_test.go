@@ -0,0 +1,37 @@
+//go:build linux || darwin
+
+package serve
+
+import (
+	"context"
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/auth"
+)
+
+func TestAPIAuthOverSocket(t *testing.T) {
+	resetConfig(t)
+	db := migratedDB(t)
+	path := filepath.Join(t.TempDir(), "iris.sock")
+	l, err := auth.ListenUnix(path, func(err error) { t.Error(err) })
+	require.NoError(t, err)
+	serveTestAPI(t, db, l, newSocketCredentials())
+
+	conn, err := grpc.NewClient("unix:"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	_, err = api.NewTokensClient(conn).CreateToken(context.Background(), &api.CreateTokenRequest{Name: proto.String("laptop")})
+	require.NoError(t, err)
+
+	u, err := user.Current()
+	require.NoError(t, err)
+	require.Equal(t, "local:"+u.Username, lastAuditUser(t, db))
+}
@@ -0,0 +1,186 @@
+package serve
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stesla/iris/api"
+)
+
+type logsServer struct {
+	api.UnimplementedLogsServer
+	db       *sql.DB
+	sessions *SessionPool
+}
+
+func (s *logsServer) AddScene(_ context.Context, r *api.Scene) (*emptypb.Empty, error) {
+	var stoppedAt sql.NullTime
+	if r.StoppedAt != nil {
+		stoppedAt = sql.NullTime{Time: r.StoppedAt.AsTime(), Valid: true}
+	}
+	_, err := s.db.Exec(
+		"INSERT INTO scenes (upstream, name, started_at, stopped_at) VALUES (?, ?, ?, ?)",
+		r.Upstream, r.Name, r.StartedAt.AsTime(), stoppedAt,
+	)
+	return &emptypb.Empty{}, err
+}
+
+func (s *logsServer) ListScenes(_ context.Context, r *api.ListScenesRequest) (*api.ListScenesResponse, error) {
+	query := "SELECT upstream, name, started_at, stopped_at FROM scenes"
+	args := []any{}
+	if r.Upstream != nil {
+		query += " WHERE upstream=?"
+		args = append(args, *r.Upstream)
+	}
+	query += " ORDER BY started_at"
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := &api.ListScenesResponse{}
+	for rows.Next() {
+		var startedAt time.Time
+		var stoppedAt sql.NullTime
+		scene := &api.Scene{}
+		if err := rows.Scan(&scene.Upstream, &scene.Name, &startedAt, &stoppedAt); err != nil {
+			return nil, err
+		}
+		scene.StartedAt = timestamppb.New(startedAt)
+		if stoppedAt.Valid {
+			scene.StoppedAt = timestamppb.New(stoppedAt.Time)
+		}
+		result.Scenes = append(result.Scenes, scene)
+	}
+	return result, rows.Err()
+}
+
+func (s *logsServer) Tail(r *api.TailRequest, stream grpc.ServerStreamingServer[api.LogData]) error {
+	upstream, found := s.sessions.upstreamWithKey(r.GetName())
+	if !found {
+		return status.Errorf(codes.NotFound, "upstream not connected: %s", r.GetName())
+	}
+
+	out := streamWriter{stream}
+	if !r.GetFollow() {
+		_, err := upstream.history.WriteTo(upstream.replayWriter(out))
+		return err
+	}
+
+	w := newTailWriter()
+	history, err := upstream.follow(w)
+	if err != nil {
+		return err
+	}
+	defer upstream.RemoveDownstream(w)
+	if _, err := upstream.replayWriter(out).Write(history); err != nil {
+		return err
+	}
+	for {
+		select {
+		case p := <-w.data:
+			if _, err := out.Write(p); err != nil {
+				return err
+			}
+		case <-w.done:
+			if w.overflowed.Load() {
+				return status.Error(codes.ResourceExhausted, "tail fell too far behind")
+			}
+			return w.drain(out)
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// follow returns the history and adds w to the downstreams under the same
+// lock, so that nothing the game sends can fall between them.
+func (s *upstream) follow(w io.WriteCloser) ([]byte, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	var buf bytes.Buffer
+	if _, err := s.history.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	s.downstream = append(s.downstream, w)
+	return buf.Bytes(), nil
+}
+
+type streamWriter struct {
+	stream grpc.ServerStreamingServer[api.LogData]
+}
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&api.LogData{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// tailQueueSize is how many writes a tail can fall behind before it is closed.
+const tailQueueSize = 256
+
+var errTailClosed = errors.New("tail closed")
+
+// tailWriter queues what the upstream writes to it, so that Tail can send it
+// without holding up the game. A tail that falls too far behind is closed.
+type tailWriter struct {
+	data       chan []byte
+	done       chan struct{}
+	once       sync.Once
+	overflowed atomic.Bool
+}
+
+func newTailWriter() *tailWriter {
+	return &tailWriter{
+		data: make(chan []byte, tailQueueSize),
+		done: make(chan struct{}),
+	}
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.done:
+		return 0, errTailClosed
+	default:
+	}
+	select {
+	case w.data <- bytes.Clone(p):
+		return len(p), nil
+	default:
+		w.overflowed.Store(true)
+		w.Close()
+		return 0, errTailClosed
+	}
+}
+
+// drain sends whatever was queued before the tail was closed.
+func (w *tailWriter) drain(out io.Writer) error {
+	for {
+		select {
+		case p := <-w.data:
+			if _, err := out.Write(p); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (w *tailWriter) Close() error {
+	w.once.Do(func() { close(w.done) })
+	return nil
+}
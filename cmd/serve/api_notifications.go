@@ -0,0 +1,44 @@
+package serve
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/notify"
+)
+
+type notificationsServer struct {
+	api.UnimplementedNotificationsServer
+	sessions *SessionPool
+}
+
+func (s *notificationsServer) Watch(r *api.WatchNotificationsRequest, stream grpc.ServerStreamingServer[api.Notification]) error {
+	ctx := stream.Context()
+	notes := make(chan notify.Notification)
+	unsubscribe := s.sessions.notifier.Subscribe(func(n notify.Notification) {
+		select {
+		case notes <- n:
+		case <-ctx.Done():
+		}
+	})
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-notes:
+			if r.Upstream != nil && n.Upstream != r.GetUpstream() {
+				continue
+			}
+			err := stream.Send(&api.Notification{
+				Upstream: &n.Upstream,
+				Text:     &n.Text,
+				Time:     timestamppb.New(n.Time),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
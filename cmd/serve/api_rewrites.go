@@ -0,0 +1,82 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/rewrite"
+)
+
+type rewritesServer struct {
+	api.UnimplementedRewritesServer
+	db       *sql.DB
+	sessions *SessionPool
+}
+
+func (s *rewritesServer) AddRewrite(_ context.Context, r *api.Rewrite) (*api.Rewrite, error) {
+	rule := rewrite.Rule{
+		Pattern:  r.GetPattern(),
+		Action:   rewrite.Action(r.GetAction()),
+		Argument: r.GetArgument(),
+		Log:      r.GetLog(),
+	}
+	if err := rewrite.Validate(rule); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	result, err := s.db.Exec(
+		"INSERT INTO rewrites (upstream, pattern, action, argument, log) VALUES (?, ?, ?, ?, ?)",
+		r.GetUpstream(), rule.Pattern, rule.Action, rule.Argument, rule.Log,
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	r.Id = &id
+	return r, s.sessions.ReloadRewrites(r.GetUpstream())
+}
+
+func (s *rewritesServer) RemoveRewrite(_ context.Context, r *api.RemoveRewriteRequest) (*emptypb.Empty, error) {
+	var upstream string
+	row := s.db.QueryRow("SELECT upstream FROM rewrites WHERE id=?", r.GetId())
+	if err := row.Scan(&upstream); err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "no rewrite with id: %d", r.GetId())
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec("DELETE FROM rewrites WHERE id=?", r.GetId()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, s.sessions.ReloadRewrites(upstream)
+}
+
+func (s *rewritesServer) ListRewrites(_ context.Context, r *api.ListRewritesRequest) (*api.ListRewritesResponse, error) {
+	query := "SELECT id, upstream, pattern, action, argument, log FROM rewrites"
+	args := []any{}
+	if r.Upstream != nil {
+		query += " WHERE upstream=?"
+		args = append(args, *r.Upstream)
+	}
+	query += " ORDER BY upstream, id"
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := &api.ListRewritesResponse{}
+	for rows.Next() {
+		rw := &api.Rewrite{}
+		if err := rows.Scan(&rw.Id, &rw.Upstream, &rw.Pattern, &rw.Action, &rw.Argument, &rw.Log); err != nil {
+			return nil, err
+		}
+		result.Rewrites = append(result.Rewrites, rw)
+	}
+	return result, rows.Err()
+}
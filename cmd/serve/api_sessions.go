@@ -0,0 +1,95 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/script"
+)
+
+// defaultExpectTimeout matches the default for expect in scripts.
+const defaultExpectTimeout = 10 * time.Second
+
+type sessionsServer struct {
+	api.UnimplementedSessionsServer
+	db       *sql.DB
+	sessions *SessionPool
+}
+
+func (s *sessionsServer) connected(key string) (*upstream, error) {
+	u, ok := s.sessions.upstreamWithKey(key)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%s is not connected", key)
+	}
+	return u, nil
+}
+
+func (s *sessionsServer) Send(ctx context.Context, r *api.SendRequest) (*emptypb.Empty, error) {
+	u, err := s.connected(r.GetUpstream())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.send(ctx, u, r.GetText()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *sessionsServer) Expect(ctx context.Context, r *api.ExpectRequest) (*api.ExpectResponse, error) {
+	u, err := s.connected(r.GetUpstream())
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(r.GetPattern())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	timeout := defaultExpectTimeout
+	if r.Timeout != nil {
+		timeout = r.Timeout.AsDuration()
+	}
+
+	cursor := u.script.Cursor()
+	if r.Send != nil {
+		if err := s.send(ctx, u, r.GetSend()); err != nil {
+			return nil, err
+		}
+	}
+	line, groups, err := u.script.Expect(ctx, cursor, re, timeout)
+	switch {
+	case errors.Is(err, script.ErrTimeout):
+		return nil, status.Errorf(codes.DeadlineExceeded, "nothing matched %q in %v", r.GetPattern(), timeout)
+	case errors.Is(err, script.ErrSessionClosed):
+		return nil, status.Errorf(codes.Unavailable, "%s disconnected", r.GetUpstream())
+	case err != nil:
+		return nil, status.FromContextError(err).Err()
+	}
+	return &api.ExpectResponse{Line: &line, Groups: groups}, nil
+}
+
+// send sends text upstream a line at a time, expanding aliases as it would
+// for a client. It does not wait for the floor.
+func (s *sessionsServer) send(ctx context.Context, u *upstream, text string) error {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return status.Error(codes.InvalidArgument, "nothing to send")
+	}
+	var commands []string
+	for line := range strings.SplitSeq(text, "\n") {
+		commands = append(commands, u.expandAlias(strings.TrimSuffix(line, "\r")+"\n")...)
+	}
+	if err := u.sendInput(apiCaller(ctx), commands); err != nil {
+		return status.Errorf(codes.Unavailable, "error sending to %s: %v", u.key, err)
+	}
+	auditCall(ctx, s.db, auditSend, u.key, "")
+	return nil
+}
@@ -0,0 +1,89 @@
+package serve
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stesla/iris/api"
+)
+
+type statusServer struct {
+	api.UnimplementedStatusServer
+	sessions *SessionPool
+}
+
+func (s *statusServer) Status(_ context.Context, r *api.StatusRequest) (*api.StatusResponse, error) {
+	var upstreams []*upstream
+	s.sessions.Lock()
+	for key, u := range s.sessions.streams {
+		if u.ready.Load() && (r.Upstream == nil || key == r.GetUpstream()) {
+			upstreams = append(upstreams, u)
+		}
+	}
+	s.sessions.Unlock()
+	if r.Upstream != nil && len(upstreams) == 0 {
+		return nil, status.Errorf(codes.NotFound, "%s is not connected", r.GetUpstream())
+	}
+	slices.SortFunc(upstreams, func(a, b *upstream) int { return cmp.Compare(a.key, b.key) })
+
+	result := &api.StatusResponse{}
+	for _, u := range upstreams {
+		result.Upstreams = append(result.Upstreams, u.status())
+	}
+	return result, nil
+}
+
+func (s *upstream) status() *api.UpstreamStatus {
+	state := s.state.snapshot()
+	s.mux.Lock()
+	remote := s.remote
+	s.mux.Unlock()
+	result := &api.UpstreamStatus{
+		Key:         proto.String(s.key),
+		Address:     proto.String(remote),
+		ConnectedAt: timestamppb.New(state.connectedAt),
+		Idle:        durationpb.New(state.idle),
+		Charset:     proto.String(state.charset),
+		Options:     optionStatuses(state.options),
+		HistoryPath: proto.String(s.history.Name()),
+	}
+	if info, err := s.history.Stat(); err == nil {
+		result.HistorySize = proto.Int64(info.Size())
+	}
+	for _, d := range s.clients() {
+		result.Clients = append(result.Clients, d.status())
+	}
+	return result
+}
+
+func (s *downstream) status() *api.ClientStatus {
+	state := s.state.snapshot()
+	return &api.ClientStatus{
+		Address:     proto.String(s.addr),
+		User:        proto.String(s.User),
+		Role:        proto.String(string(s.Role())),
+		Spectating:  proto.Bool(s.spectating()),
+		ConnectedAt: timestamppb.New(state.connectedAt),
+		Idle:        durationpb.New(state.idle),
+		Charset:     proto.String(state.charset),
+		Options:     optionStatuses(state.options),
+	}
+}
+
+func optionStatuses(options []namedOption) (result []*api.OptionStatus) {
+	for _, opt := range options {
+		result = append(result, &api.OptionStatus{
+			Name: proto.String(opt.name),
+			Us:   proto.Bool(opt.us),
+			Them: proto.Bool(opt.them),
+		})
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package serve
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"maps"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stesla/iris/api"
+)
+
+// queuedConn writes in the background, as a socket's buffer would, so that
+// both ends of a net.Pipe can answer a negotiation while the other is still
+// sending its own.
+type queuedConn struct {
+	net.Conn
+	queue chan []byte
+}
+
+func newQueuedConn(conn net.Conn) *queuedConn {
+	c := &queuedConn{Conn: conn, queue: make(chan []byte, 64)}
+	go func() {
+		for p := range c.queue {
+			conn.Write(p)
+		}
+	}()
+	return c
+}
+
+func (c *queuedConn) Write(p []byte) (int, error) {
+	c.queue <- bytes.Clone(p)
+	return len(p), nil
+}
+
+func TestStatus(t *testing.T) {
+	resetConfig(t)
+	viper.Set("log.dir", t.TempDir())
+	viper.Set("log.history_size", 4096)
+	db := migratedDB(t)
+	addUpstream(t, db, "game", "game-secret")
+	pool := NewSessionPool(db, zerolog.Nop())
+	require.NoError(t, pool.LoadConfig())
+
+	// The game is a telnet server on the other end of a pipe, which agrees to
+	// whatever iris asks for.
+	server, client := net.Pipe()
+	pool.dial = func(string, string) (net.Conn, error) { return client, nil }
+	game := newSession(newQueuedConn(server), zerolog.Nop())
+	game.charset.IsServer = true
+	game.negotiateOptions()
+	go io.Copy(io.Discard, game)
+
+	s := pool.upstreamForKey("game")
+	require.NoError(t, s.Connect("game.example:4201"))
+	t.Cleanup(func() {
+		game.Close()
+		require.Eventually(t, func() bool {
+			_, found := pool.upstreamWithKey("game")
+			return !found
+		}, time.Second, time.Millisecond)
+	})
+	_, err := game.Write([]byte("Welcome to the game!\n"))
+	require.NoError(t, err)
+
+	statuses := &statusServer{sessions: pool}
+	negotiated := map[string][2]bool{
+		"charset":           {true, true},
+		"end-of-record":     {true, true},
+		"suppress-go-ahead": {true, true},
+		"transmit-binary":   {true, true},
+	}
+	var upstream *api.UpstreamStatus
+	options := map[string][2]bool{}
+	require.Eventually(t, func() bool {
+		resp, err := statuses.Status(context.Background(), &api.StatusRequest{Upstream: proto.String("game")})
+		if err != nil || len(resp.GetUpstreams()) != 1 {
+			return false
+		}
+		upstream = resp.GetUpstreams()[0]
+		clear(options)
+		for _, opt := range upstream.GetOptions() {
+			options[opt.GetName()] = [2]bool{opt.GetUs(), opt.GetThem()}
+		}
+		return upstream.GetCharset() != "" && upstream.GetHistorySize() > 0 && maps.Equal(options, negotiated)
+	}, time.Second, time.Millisecond, "options: %v", options)
+
+	require.Equal(t, "game", upstream.GetKey())
+	require.Equal(t, "pipe", upstream.GetAddress())
+	require.Equal(t, "UTF-8", upstream.GetCharset())
+	info, err := s.history.Stat()
+	require.NoError(t, err)
+	require.Equal(t, info.Size(), upstream.GetHistorySize())
+	require.Equal(t, s.history.Name(), upstream.GetHistoryPath())
+
+	_, err = statuses.Status(context.Background(), &api.StatusRequest{Upstream: proto.String("other")})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
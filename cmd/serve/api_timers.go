@@ -0,0 +1,116 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/script"
+	"github.com/stesla/iris/internal/timer"
+)
+
+type timersServer struct {
+	api.UnimplementedTimersServer
+	db       *sql.DB
+	sessions *SessionPool
+}
+
+func (s *timersServer) AddTimer(_ context.Context, r *api.Timer) (*api.Timer, error) {
+	t := timer.Timer{
+		Upstream: r.GetUpstream(),
+		Schedule: r.GetSchedule(),
+		Command:  r.GetCommand(),
+		Enabled:  r.GetEnabled(),
+		Script:   r.GetScript(),
+	}
+	if err := timer.Validate(t); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if t.Script {
+		if err := script.Check("timer.star", t.Command); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	result, err := s.db.Exec(
+		"INSERT INTO timers (upstream, schedule, command, enabled, script) VALUES (?, ?, ?, ?, ?)",
+		t.Upstream, t.Schedule, t.Command, t.Enabled, t.Script,
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	r.Id = &id
+	r.Enabled = &t.Enabled
+	if err := s.sessions.ReloadTimers(t.Upstream); err != nil {
+		return nil, err
+	}
+	if next, ok := s.sessions.NextTimerRun(t.Upstream, id); ok {
+		r.NextRun = timestamppb.New(next)
+	}
+	return r, nil
+}
+
+func (s *timersServer) RemoveTimer(_ context.Context, r *api.RemoveTimerRequest) (*emptypb.Empty, error) {
+	upstream, err := s.timerUpstream(r.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec("DELETE FROM timers WHERE id=?", r.GetId()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, s.sessions.ReloadTimers(upstream)
+}
+
+func (s *timersServer) SetTimerEnabled(_ context.Context, r *api.SetTimerEnabledRequest) (*emptypb.Empty, error) {
+	upstream, err := s.timerUpstream(r.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec("UPDATE timers SET enabled=? WHERE id=?", r.GetEnabled(), r.GetId()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, s.sessions.ReloadTimers(upstream)
+}
+
+func (s *timersServer) timerUpstream(id int64) (upstream string, err error) {
+	row := s.db.QueryRow("SELECT upstream FROM timers WHERE id=?", id)
+	if err = row.Scan(&upstream); err == sql.ErrNoRows {
+		err = status.Errorf(codes.NotFound, "no timer with id: %d", id)
+	}
+	return
+}
+
+func (s *timersServer) ListTimers(_ context.Context, r *api.ListTimersRequest) (*api.ListTimersResponse, error) {
+	query := "SELECT id, upstream, schedule, command, enabled, script FROM timers"
+	args := []any{}
+	if r.Upstream != nil {
+		query += " WHERE upstream=?"
+		args = append(args, *r.Upstream)
+	}
+	query += " ORDER BY upstream, id"
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := &api.ListTimersResponse{}
+	for rows.Next() {
+		t := &api.Timer{}
+		if err := rows.Scan(&t.Id, &t.Upstream, &t.Schedule, &t.Command, &t.Enabled, &t.Script); err != nil {
+			return nil, err
+		}
+		if next, ok := s.sessions.NextTimerRun(t.GetUpstream(), t.GetId()); ok {
+			t.NextRun = timestamppb.New(next)
+		}
+		result.Timers = append(result.Timers, t)
+	}
+	return result, rows.Err()
+}
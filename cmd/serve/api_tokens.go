@@ -0,0 +1,73 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/auth"
+)
+
+type tokensServer struct {
+	api.UnimplementedTokensServer
+	db *sql.DB
+}
+
+func (s *tokensServer) CreateToken(ctx context.Context, r *api.CreateTokenRequest) (*api.CreateTokenResponse, error) {
+	if r.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token name is empty")
+	}
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.db.Exec("INSERT INTO tokens (name, hash, created_at) VALUES (?, ?, ?)", r.GetName(), hash, time.Now())
+	var serr sqlite3.Error
+	if errors.As(err, &serr) && serr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return nil, status.Errorf(codes.AlreadyExists, "token already exists: %s", r.GetName())
+	} else if err != nil {
+		return nil, err
+	}
+	auditCall(ctx, s.db, auditTokenCreate, "", r.GetName())
+	return &api.CreateTokenResponse{Token: &token}, nil
+}
+
+func (s *tokensServer) RevokeToken(ctx context.Context, r *api.RevokeTokenRequest) (*emptypb.Empty, error) {
+	result, err := s.db.Exec("DELETE FROM tokens WHERE name=?", r.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, status.Errorf(codes.NotFound, "no token named: %s", r.GetName())
+	}
+	auditCall(ctx, s.db, auditTokenRevoke, "", r.GetName())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *tokensServer) ListTokens(context.Context, *emptypb.Empty) (*api.ListTokensResponse, error) {
+	rows, err := s.db.Query("SELECT name, created_at FROM tokens ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := &api.ListTokensResponse{}
+	for rows.Next() {
+		var name string
+		var createdAt time.Time
+		if err := rows.Scan(&name, &createdAt); err != nil {
+			return nil, err
+		}
+		result.Tokens = append(result.Tokens, &api.Token{Name: &name, CreatedAt: timestamppb.New(createdAt)})
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,87 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/script"
+	"github.com/stesla/iris/internal/trigger"
+)
+
+type triggersServer struct {
+	api.UnimplementedTriggersServer
+	db       *sql.DB
+	sessions *SessionPool
+}
+
+func (s *triggersServer) AddTrigger(_ context.Context, r *api.Trigger) (*api.Trigger, error) {
+	t := trigger.Trigger{
+		Pattern:  r.GetPattern(),
+		Action:   trigger.Action(r.GetAction()),
+		Argument: r.GetArgument(),
+	}
+	if err := trigger.Validate(t); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if t.Action == trigger.ActionScript {
+		if err := script.Check("trigger.star", t.Argument, "match"); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	result, err := s.db.Exec(
+		"INSERT INTO triggers (upstream, pattern, action, argument) VALUES (?, ?, ?, ?)",
+		r.GetUpstream(), t.Pattern, t.Action, t.Argument,
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	r.Id = &id
+	return r, s.sessions.ReloadTriggers(r.GetUpstream())
+}
+
+func (s *triggersServer) RemoveTrigger(_ context.Context, r *api.RemoveTriggerRequest) (*emptypb.Empty, error) {
+	var upstream string
+	row := s.db.QueryRow("SELECT upstream FROM triggers WHERE id=?", r.GetId())
+	if err := row.Scan(&upstream); err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "no trigger with id: %d", r.GetId())
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec("DELETE FROM triggers WHERE id=?", r.GetId()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, s.sessions.ReloadTriggers(upstream)
+}
+
+func (s *triggersServer) ListTriggers(_ context.Context, r *api.ListTriggersRequest) (*api.ListTriggersResponse, error) {
+	query := "SELECT id, upstream, pattern, action, argument FROM triggers"
+	args := []any{}
+	if r.Upstream != nil {
+		query += " WHERE upstream=?"
+		args = append(args, *r.Upstream)
+	}
+	query += " ORDER BY upstream, id"
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := &api.ListTriggersResponse{}
+	for rows.Next() {
+		t := &api.Trigger{}
+		if err := rows.Scan(&t.Id, &t.Upstream, &t.Pattern, &t.Action, &t.Argument); err != nil {
+			return nil, err
+		}
+		result.Triggers = append(result.Triggers, t)
+	}
+	return result, rows.Err()
+}
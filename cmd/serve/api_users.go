@@ -0,0 +1,170 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/auth"
+)
+
+type usersServer struct {
+	api.UnimplementedUsersServer
+	db       *sql.DB
+	sessions *SessionPool
+}
+
+func (s *usersServer) AddUser(ctx context.Context, r *api.AddUserRequest) (*emptypb.Empty, error) {
+	switch {
+	case r.GetName() == "":
+		return nil, status.Error(codes.InvalidArgument, "user name is empty")
+	case strings.IndexFunc(r.GetName(), unicode.IsSpace) >= 0:
+		return nil, status.Error(codes.InvalidArgument, "user name contains spaces")
+	case r.GetPassword() == "":
+		return nil, status.Error(codes.InvalidArgument, "password is empty")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(r.GetPassword()), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.db.Exec("INSERT INTO users (name, bcrypt, created_at) VALUES (?, ?, ?)", r.GetName(), hash, time.Now())
+	var serr sqlite3.Error
+	if errors.As(err, &serr) && serr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return nil, status.Errorf(codes.AlreadyExists, "user already exists: %s", r.GetName())
+	} else if err != nil {
+		return nil, err
+	}
+	auditCall(ctx, s.db, auditUserAdd, "", r.GetName())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *usersServer) userID(name string) (int64, error) {
+	var id int64
+	row := s.db.QueryRow("SELECT id FROM users WHERE name=?", name)
+	if err := row.Scan(&id); err == sql.ErrNoRows {
+		return 0, status.Errorf(codes.NotFound, "no user named: %s", name)
+	} else if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *usersServer) RemoveUser(ctx context.Context, r *api.RemoveUserRequest) (*emptypb.Empty, error) {
+	id, err := s.userID(r.GetName())
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DELETE FROM grants WHERE user_id=?", id); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("DELETE FROM users WHERE id=?", id); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	auditCall(ctx, s.db, auditUserRemove, "", r.GetName())
+	s.sessions.updateUser(r.GetName(), "", "")
+	return &emptypb.Empty{}, nil
+}
+
+func (s *usersServer) Grant(ctx context.Context, r *api.GrantRequest) (*emptypb.Empty, error) {
+	role, err := auth.ParseRole(r.GetRole())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	id, err := s.userID(r.GetUser())
+	if err != nil {
+		return nil, err
+	}
+	var found bool
+	row := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM upstreams WHERE name=?)", r.GetUpstream())
+	if err := row.Scan(&found); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, status.Errorf(codes.NotFound, "no upstream named: %s", r.GetUpstream())
+	}
+	_, err = s.db.Exec(`INSERT INTO grants (user_id, upstream, role, granted_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, upstream) DO UPDATE SET role=excluded.role, granted_at=excluded.granted_at`,
+		id, r.GetUpstream(), role, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	auditCall(ctx, s.db, auditGrant, r.GetUpstream(), r.GetUser()+" "+string(role))
+	s.sessions.updateUser(r.GetUser(), r.GetUpstream(), role)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *usersServer) Revoke(ctx context.Context, r *api.RevokeRequest) (*emptypb.Empty, error) {
+	id, err := s.userID(r.GetUser())
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.db.Exec("DELETE FROM grants WHERE user_id=? AND upstream=?", id, r.GetUpstream())
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, status.Errorf(codes.NotFound, "user %s has no access to %s", r.GetUser(), r.GetUpstream())
+	}
+	auditCall(ctx, s.db, auditRevoke, r.GetUpstream(), r.GetUser())
+	s.sessions.updateUser(r.GetUser(), r.GetUpstream(), "")
+	return &emptypb.Empty{}, nil
+}
+
+func (s *usersServer) ListUsers(_ context.Context, r *api.ListUsersRequest) (*api.ListUsersResponse, error) {
+	query := `SELECT u.name, u.created_at, g.upstream, g.role, g.granted_at FROM users u
+		LEFT JOIN grants g ON g.user_id = u.id`
+	args := []any{}
+	if r.Name != nil {
+		query += " WHERE u.name=?"
+		args = append(args, *r.Name)
+	}
+	query += " ORDER BY u.name, g.upstream"
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := &api.ListUsersResponse{}
+	var user *api.User
+	for rows.Next() {
+		var name string
+		var createdAt time.Time
+		var upstream, role sql.NullString
+		var grantedAt sql.NullTime
+		if err := rows.Scan(&name, &createdAt, &upstream, &role, &grantedAt); err != nil {
+			return nil, err
+		}
+		if user == nil || user.GetName() != name {
+			user = &api.User{Name: &name, CreatedAt: timestamppb.New(createdAt)}
+			result.Users = append(result.Users, user)
+		}
+		if upstream.Valid {
+			user.Grants = append(user.Grants, &api.UserGrant{
+				Upstream:  &upstream.String,
+				Role:      &role.String,
+				GrantedAt: timestamppb.New(grantedAt.Time),
+			})
+		}
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,84 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+)
+
+// The kinds of audit events.
+const (
+	auditLogin        = "login"
+	auditLoginFailed  = "login-failed"
+	auditAttach       = "attach"
+	auditDetach       = "detach"
+	auditConnect      = "connect"
+	auditDisconnect   = "disconnect"
+	auditUpstreamAdd  = "upstream-add"
+	auditUpstreamEdit = "upstream-edit"
+	auditUserAdd      = "user-add"
+	auditUserRemove   = "user-remove"
+	auditGrant        = "grant"
+	auditRevoke       = "revoke"
+	auditTokenCreate  = "token-create"
+	auditTokenRevoke  = "token-revoke"
+	auditSend         = "send"
+)
+
+type auditEvent struct {
+	Kind     string
+	User     string
+	Remote   string
+	Upstream string
+	Detail   string
+}
+
+// audit records an event, logging any error. Times are in UTC, so that they
+// sort as text.
+func audit(db *sql.DB, e auditEvent) {
+	_, err := db.Exec("INSERT INTO audit_events (time, kind, user, remote, upstream, detail) VALUES (?, ?, ?, ?, ?, ?)",
+		time.Now().UTC(), e.Kind, e.User, e.Remote, e.Upstream, e.Detail)
+	if err != nil {
+		logger.Error().Err(err).Str("kind", e.Kind).Msg("error recording audit event")
+	}
+}
+
+// auditCall records an event caused by an API call.
+func auditCall(ctx context.Context, db *sql.DB, kind, upstream, detail string) {
+	audit(db, auditEvent{
+		Kind:     kind,
+		User:     apiCaller(ctx),
+		Remote:   apiRemote(ctx),
+		Upstream: upstream,
+		Detail:   detail,
+	})
+}
+
+// audit records an event caused by a client.
+func (s *downstream) audit(kind, detail string) {
+	audit(s.pool.db, auditEvent{
+		Kind:     kind,
+		User:     s.User,
+		Remote:   s.addr,
+		Upstream: s.Name,
+		Detail:   detail,
+	})
+}
+
+func (s *downstream) attach() {
+	s.upstream.AddDownstream(s)
+	if s.spectating() {
+		s.audit(auditAttach, modeSpectator)
+	} else {
+		s.audit(auditAttach, "")
+	}
+}
+
+func (s *downstream) detach() {
+	if s.upstream == nil || !slices.Contains(s.upstream.clients(), s) {
+		return
+	}
+	s.upstream.RemoveDownstream(s)
+	s.audit(auditDetach, "")
+}
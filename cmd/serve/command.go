@@ -0,0 +1,101 @@
+package serve
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stesla/iris/internal/auth"
+)
+
+// parseCommand recognizes lines addressed to Iris itself rather than the
+// upstream, e.g. "/iris scene start The Ball".
+func parseCommand(prefix, line string) ([]string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != prefix {
+		return nil, false
+	}
+	return fields[1:], true
+}
+
+func (s *downstream) runCommand(args []string) {
+	if err := s.command(args); err != nil {
+		s.notice("error: %v", err)
+	}
+}
+
+func (s *downstream) command(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing command")
+	}
+	switch cmd, args := args[0], args[1:]; cmd {
+	case "scene":
+		return s.sceneCommand(args)
+	case "floor":
+		return s.floorCommand(args)
+	default:
+		return fmt.Errorf("unknown command: %s", cmd)
+	}
+}
+
+func (s *downstream) sceneCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: scene start [NAME] | scene stop")
+	}
+	switch args[0] {
+	case "start":
+		name, err := s.upstream.StartScene(strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		s.notice("scene started: %s", name)
+	case "stop":
+		if err := s.upstream.StopScene(); err != nil {
+			return err
+		}
+		s.notice("scene stopped")
+	default:
+		return fmt.Errorf("unknown scene command: %s", args[0])
+	}
+	return nil
+}
+
+// floorCommand shows who has the floor, or takes or releases it. Owners can
+// take the floor from someone else.
+func (s *downstream) floorCommand(args []string) error {
+	if !s.pool.config().inputFloor {
+		return errors.New("floor control is off")
+	}
+	if len(args) == 0 {
+		if holder := s.upstream.floorHolder(); holder != nil {
+			s.notice("%s has the floor", holder.who())
+		} else {
+			s.notice("nobody has the floor")
+		}
+		return nil
+	}
+	switch args[0] {
+	case "take":
+		previous := s.upstream.floorHolder()
+		holder, ok := s.upstream.claimFloor(s, s.Role() == auth.RoleOwner)
+		if !ok {
+			return fmt.Errorf("%s has the floor", holder.who())
+		}
+		if previous != nil && previous != s {
+			previous.notice("%s took the floor", s.who())
+		}
+		s.notice("you have the floor")
+	case "release":
+		if err := s.upstream.releaseFloor(s); err != nil {
+			return err
+		}
+		s.notice("you released the floor")
+	default:
+		return fmt.Errorf("unknown floor command: %s", args[0])
+	}
+	return nil
+}
+
+func (s *downstream) notice(format string, args ...any) {
+	fmt.Fprintf(s, "iris: "+format+"\n", args...)
+}
@@ -0,0 +1,166 @@
+package serve
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/stesla/iris/internal/guard"
+)
+
+var (
+	errDenied     = errors.New("address is not allowed")
+	errTooPending = errors.New("too many clients are authenticating")
+)
+
+// clientGuard applies the limits on the telnet listener to the clients that
+// have not authenticated yet.
+type clientGuard struct {
+	ip, upstream *guard.Lockout
+
+	mux        sync.Mutex
+	filter     guard.Filter
+	maxPending int
+	pending    int
+	timeout    time.Duration
+	delay      time.Duration
+}
+
+func newClientGuard() *clientGuard {
+	return &clientGuard{
+		ip:       guard.NewLockout(guard.LockoutConfig{}),
+		upstream: guard.NewLockout(guard.LockoutConfig{}),
+	}
+}
+
+// guardConfig holds the auth settings, as loaded by LoadConfig.
+type guardConfig struct {
+	filter       guard.Filter
+	ip, upstream guard.LockoutConfig
+	maxPending   int
+	timeout      time.Duration
+	delay        time.Duration
+}
+
+func loadGuardConfig() (c guardConfig, err error) {
+	if c.filter.Allow, err = guard.ParsePrefixes(viper.GetStringSlice("auth.allow")); err != nil {
+		return c, fmt.Errorf("auth.allow: %w", err)
+	}
+	if c.filter.Deny, err = guard.ParsePrefixes(viper.GetStringSlice("auth.deny")); err != nil {
+		return c, fmt.Errorf("auth.deny: %w", err)
+	}
+	c.ip = guard.LockoutConfig{
+		Threshold: viper.GetInt("auth.lockout.threshold"),
+		Initial:   viper.GetDuration("auth.lockout.initial"),
+		Max:       viper.GetDuration("auth.lockout.max"),
+	}
+	c.upstream = c.ip
+	c.upstream.Threshold = viper.GetInt("auth.lockout.upstream_threshold")
+	if err := c.ip.Validate(); err != nil {
+		return c, fmt.Errorf("auth.lockout: %w", err)
+	}
+	if err := c.upstream.Validate(); err != nil {
+		return c, fmt.Errorf("auth.lockout: %w", err)
+	}
+	c.delay = viper.GetDuration("auth.lockout.upstream_delay")
+	if c.delay < 0 {
+		return c, fmt.Errorf("auth.lockout.upstream_delay: must not be negative: %v", c.delay)
+	}
+	c.maxPending = viper.GetInt("auth.max_pending")
+	c.timeout = viper.GetDuration("auth.timeout")
+	return c, nil
+}
+
+func (g *clientGuard) configure(c guardConfig) {
+	g.ip.Configure(c.ip)
+	g.upstream.Configure(c.upstream)
+
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	g.filter = c.filter
+	g.maxPending = c.maxPending
+	g.timeout = c.timeout
+	g.delay = c.delay
+}
+
+// admit decides whether a client from addr may start authenticating. If it
+// may, release must be called once it is done.
+func (g *clientGuard) admit(addr netip.Addr) (release func(), err error) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	if !g.filter.Permits(addr) {
+		return nil, errDenied
+	}
+	if g.maxPending > 0 && g.pending >= g.maxPending {
+		return nil, errTooPending
+	}
+	g.pending++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mux.Lock()
+			defer g.mux.Unlock()
+			g.pending--
+		})
+	}, nil
+}
+
+// authTimeout is how long a client has to authenticate, or zero for no limit.
+func (g *clientGuard) authTimeout() time.Duration {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	return g.timeout
+}
+
+// locked returns how much longer a client from addr is locked out for.
+func (g *clientGuard) locked(addr netip.Addr) time.Duration {
+	return g.ip.Locked(addr.String(), time.Now())
+}
+
+// upstreamDelay returns how long an attempt on upstream must wait before its
+// credentials are checked. A locked out upstream is slowed down rather than
+// refused, so that guessing cannot lock its owner out.
+func (g *clientGuard) upstreamDelay(upstream string) time.Duration {
+	if g.upstream.Locked(upstream, time.Now()) <= 0 {
+		return 0
+	}
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	return g.delay
+}
+
+// record counts a failed authentication against the client's address and the
+// upstream. A success only forgets the upstream's failures, since one valid
+// login must not let an address keep guessing.
+func (g *clientGuard) record(addr netip.Addr, upstream string, err error) {
+	switch {
+	case err == nil:
+		g.upstream.Reset(upstream)
+	case errors.Is(err, errAuthFailed):
+		now := time.Now()
+		if d := g.ip.Fail(addr.String(), now); d > 0 {
+			logger.Warn().Str("client", addr.String()).Dur("lockout", d).Msg("locking out client")
+		}
+		if d := g.upstream.Fail(upstream, now); d > 0 {
+			logger.Warn().Str("upstream", upstream).Dur("lockout", d).Msg("locking out upstream")
+		}
+	}
+}
+
+func remoteIP(addr net.Addr) netip.Addr {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.AddrPort().Addr().Unmap()
+	}
+	return netip.Addr{}
+}
+
+func (s *downstream) stopAuthTimer() {
+	if s.authTimer != nil {
+		s.authTimer.Stop()
+	}
+}
@@ -0,0 +1,84 @@
+package serve
+
+import (
+	"database/sql"
+	"io"
+	"net"
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/stesla/iris/internal/auth"
+)
+
+func TestLockedUpstreamAdmitsOwner(t *testing.T) {
+	resetConfig(t)
+	viper.Set("auth.lockout.threshold", 100)
+	viper.Set("auth.lockout.upstream_threshold", 2)
+	viper.Set("auth.lockout.upstream_delay", "10ms")
+	viper.Set("auth.lockout.initial", "1m")
+	viper.Set("auth.lockout.max", "1h")
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "iris.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE upstreams (name TEXT PRIMARY KEY, bcrypt TEXT NOT NULL)")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO upstreams (name, bcrypt) VALUES (?, ?)", "game", hash)
+	require.NoError(t, err)
+
+	pool := NewSessionPool(db, zerolog.Nop())
+	require.NoError(t, pool.LoadConfig())
+	for _, ip := range []string{"203.0.113.1", "203.0.113.2"} {
+		pool.guard.record(netip.MustParseAddr(ip), "game", errAuthFailed)
+	}
+	require.Positive(t, pool.guard.upstream.Locked("game", time.Now()))
+
+	client := func(password string) *downstream {
+		server, client := net.Pipe()
+		t.Cleanup(func() { client.Close() })
+		go io.Copy(io.Discard, client)
+		d := pool.NewDownstream(server)
+		t.Cleanup(func() { d.Close() })
+		d.Name, d.Password = "game", password
+		return d
+	}
+
+	_, err = client("guess").login()
+	require.ErrorIs(t, err, errAuthFailed)
+
+	start := time.Now()
+	role, err := client("secret").login()
+	require.NoError(t, err)
+	require.Equal(t, auth.RoleOwner, role)
+	require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+}
+
+func TestSuccessKeepsAddressFailures(t *testing.T) {
+	resetConfig(t)
+	viper.Set("auth.lockout.threshold", 3)
+	viper.Set("auth.lockout.upstream_threshold", 2)
+	viper.Set("auth.lockout.initial", "1m")
+	viper.Set("auth.lockout.max", "1h")
+
+	pool := NewSessionPool(nil, zerolog.Nop())
+	require.NoError(t, pool.LoadConfig())
+	ip := netip.MustParseAddr("203.0.113.1")
+	for range 3 {
+		require.Zero(t, pool.guard.locked(ip))
+		pool.guard.record(ip, "game", nil)
+		pool.guard.record(ip, "other", errAuthFailed)
+		pool.guard.record(ip, "game", nil)
+	}
+	require.Positive(t, pool.guard.locked(ip))
+	require.Zero(t, pool.guard.upstream.Locked("game", time.Now()))
+}
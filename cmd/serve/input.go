@@ -0,0 +1,116 @@
+package serve
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const logKindInput = "input"
+
+// redacted is recorded in place of a command that may hold a password.
+const redacted = "<redacted>"
+
+// passwordPrompt matches a prompt asking for a password.
+var passwordPrompt = regexp.MustCompile(`(?i)password\s*:?\s*$`)
+
+// who names a client in the history and in notices.
+func (s *downstream) who() string {
+	if s.User != "" {
+		return s.User + "@" + s.addr
+	}
+	return s.addr
+}
+
+// historyName names a client in the history, which is replayed to everyone who
+// attaches, so it leaves out the client's address.
+func (s *downstream) historyName() string {
+	if s.User != "" {
+		return s.User
+	}
+	return "client"
+}
+
+// sendInput sends commands upstream in a single write, after recording who
+// sent them in the history if log.input is set. A command that matches
+// log.input_redact, or answers a password prompt, is recorded as redacted.
+func (s *upstream) sendInput(who string, commands []string) error {
+	s.wmux.Lock()
+	defer s.wmux.Unlock()
+	hidden := s.takePasswordPrompt()
+	if cfg := s.pool.config(); cfg.logInput {
+		redact := cfg.redact
+		for _, cmd := range commands {
+			cmd = strings.TrimRight(cmd, "\r\n")
+			if hidden || slices.ContainsFunc(redact, func(re *regexp.Regexp) bool { return re.MatchString(cmd) }) {
+				cmd = redacted
+			}
+			hidden = false
+			kind := logKindInput + " " + who + ": " + cmd
+			if err := s.writeHistorySeparator(kind, time.Now()); err != nil {
+				s.logger.Error().Err(err).Msg("error writing input to history")
+			}
+		}
+	}
+	_, err := s.telnetSession.Write([]byte(strings.Join(commands, "")))
+	return err
+}
+
+// notePrompt remembers whether the game has just asked for a password.
+func (s *upstream) notePrompt(line []byte) {
+	prompt := len(line) > 0 && line[len(line)-1] != '\n' && passwordPrompt.Match(line)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.passwordPrompt = prompt
+}
+
+func (s *upstream) takePasswordPrompt() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	prompt := s.passwordPrompt
+	s.passwordPrompt = false
+	return prompt
+}
+
+// claimFloor gives d the floor if input.floor is set and nobody else has it.
+// Clients that have left, or have not typed for input.floor_idle, lose the
+// floor to the next client to type. It returns whoever has the floor.
+func (s *upstream) claimFloor(d *downstream, force bool) (*downstream, bool) {
+	cfg := s.pool.config()
+	if !cfg.inputFloor {
+		return d, true
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	idle := cfg.inputFloorIdle
+	switch {
+	case force, s.floor == nil, s.floor == d:
+	case !slices.Contains(s.downstream, io.WriteCloser(s.floor)):
+	case idle > 0 && time.Since(s.floorAt) >= idle:
+	default:
+		return s.floor, false
+	}
+	s.floor, s.floorAt = d, time.Now()
+	return d, true
+}
+
+var errNotFloor = errors.New("you do not have the floor")
+
+func (s *upstream) releaseFloor(d *downstream) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.floor != d {
+		return errNotFloor
+	}
+	s.floor = nil
+	return nil
+}
+
+func (s *upstream) floorHolder() *downstream {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.floor
+}
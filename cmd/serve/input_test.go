@@ -0,0 +1,145 @@
+package serve
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendInput(t *testing.T) {
+	resetConfig(t)
+	viper.Set("log.dir", t.TempDir())
+	viper.Set("log.input", true)
+
+	pool := NewSessionPool(nil, zerolog.Nop())
+	require.NoError(t, pool.LoadConfig())
+	history, err := pool.newHistory("game")
+	require.NoError(t, err)
+	t.Cleanup(func() { history.Close() })
+	server, game := net.Pipe()
+	t.Cleanup(func() { game.Close() })
+	s := &upstream{pool: pool, history: history, telnetSession: newSession(server, zerolog.Nop())}
+
+	sent := make(chan string)
+	go func() {
+		buf := make([]byte, 1024)
+		n, _ := game.Read(buf)
+		sent <- string(buf[:n])
+	}()
+	require.NoError(t, s.sendInput("alice@203.0.113.7:4000", []string{"look\n", "say hi\n"}))
+	require.Equal(t, "look\r\nsay hi\r\n", <-sent)
+
+	text, err := os.ReadFile(history.(*logFile).Name())
+	require.NoError(t, err)
+	require.Contains(t, string(text), "input alice@203.0.113.7:4000: look -")
+	require.Contains(t, string(text), "input alice@203.0.113.7:4000: say hi -")
+}
+
+func TestFloor(t *testing.T) {
+	resetConfig(t)
+	pool := NewSessionPool(nil, zerolog.Nop())
+	require.NoError(t, pool.LoadConfig())
+	a, b := &downstream{addr: "a"}, &downstream{addr: "b"}
+	s := &upstream{pool: pool, downstream: []io.WriteCloser{a, b}}
+
+	// Without floor control, everyone may type.
+	_, ok := s.claimFloor(a, false)
+	require.True(t, ok)
+	_, ok = s.claimFloor(b, false)
+	require.True(t, ok)
+	require.Nil(t, s.floorHolder())
+
+	viper.Set("input.floor", true)
+	viper.Set("input.floor_idle", "1h")
+	require.NoError(t, pool.LoadConfig())
+	holder, ok := s.claimFloor(a, false)
+	require.True(t, ok)
+	require.Equal(t, a, holder)
+	holder, ok = s.claimFloor(b, false)
+	require.False(t, ok)
+	require.Equal(t, a, holder)
+
+	// An owner can take the floor.
+	holder, ok = s.claimFloor(b, true)
+	require.True(t, ok)
+	require.Equal(t, b, holder)
+	require.ErrorIs(t, s.releaseFloor(a), errNotFloor)
+	require.NoError(t, s.releaseFloor(b))
+	require.Nil(t, s.floorHolder())
+
+	// The floor is lost by leaving, or by not typing for input.floor_idle.
+	s.claimFloor(a, false)
+	s.RemoveDownstream(a)
+	require.Nil(t, s.floorHolder())
+	s.downstream = append(s.downstream, a)
+	s.claimFloor(a, false)
+	s.floorAt = time.Now().Add(-2 * time.Hour)
+	holder, ok = s.claimFloor(b, false)
+	require.True(t, ok)
+	require.Equal(t, b, holder)
+}
+
+// bufferHistory is a History kept in memory.
+type bufferHistory struct {
+	bytes.Buffer
+}
+
+func (*bufferHistory) Close() error                         { return nil }
+func (*bufferHistory) Name() string                         { return "buffer" }
+func (*bufferHistory) Stat() (os.FileInfo, error)           { return nil, os.ErrNotExist }
+func (*bufferHistory) Reopen() error                        { return nil }
+func (h *bufferHistory) WriteTo(w io.Writer) (int64, error) { return h.Buffer.WriteTo(w) }
+
+func TestSendInputRedactsPasswords(t *testing.T) {
+	resetConfig(t)
+	viper.Set("log.input", true)
+	viper.Set("log.input_redact", []string{`^connect\s`})
+
+	pool := NewSessionPool(nil, zerolog.Nop())
+	require.NoError(t, pool.LoadConfig())
+	server, game := net.Pipe()
+	t.Cleanup(func() { game.Close() })
+	go io.Copy(io.Discard, game)
+	history := &bufferHistory{}
+	s := &upstream{pool: pool, history: history, telnetSession: newSession(server, zerolog.Nop())}
+	t.Cleanup(func() { s.telnetSession.Close() })
+
+	require.NoError(t, s.sendInput("alice", []string{"connect alice secret\n", "look\n"}))
+	s.notePrompt([]byte("Password: "))
+	require.NoError(t, s.sendInput("alice", []string{"hunter2\n"}))
+	require.NoError(t, s.sendInput("alice", []string{"say password: hi\n"}))
+
+	text := history.String()
+	require.NotContains(t, text, "secret")
+	require.NotContains(t, text, "hunter2")
+	require.Contains(t, text, "alice: "+redacted)
+	require.Contains(t, text, "alice: look")
+	require.Contains(t, text, "alice: say password: hi")
+}
+
+func TestReadLineDropsLongLines(t *testing.T) {
+	resetConfig(t)
+	server, client := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+	go io.Copy(io.Discard, client)
+	d := &downstream{telnetSession: newSession(server, zerolog.Nop())}
+	t.Cleanup(func() { d.Close() })
+
+	input := bytes.Repeat([]byte("x"), 3*maxInputLine)
+	input = append(input, "\nlook\n"...)
+	d.input = bufio.NewReaderSize(bytes.NewReader(input), maxInputLine)
+
+	line, err := d.readLine()
+	require.NoError(t, err)
+	require.Equal(t, "look\n", line)
+	_, err = d.readLine()
+	require.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,80 @@
+package serve
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// lineBuffer splits what is written to it into lines, including the newline,
+// and calls fn with each of them. A partial line, such as a prompt, waits as
+// long as hold returns for the rest of it before it is passed on anyway.
+type lineBuffer struct {
+	mux     sync.Mutex
+	fn      func(line []byte)
+	hold    func() time.Duration
+	partial []byte
+	timer   *time.Timer
+	gen     int
+}
+
+// maxPartialLine is how much of a line is held back before it is passed on
+// without the rest, so that a game that never sends a newline cannot make the
+// buffer grow without bound.
+const maxPartialLine = 16 * 1024
+
+func newLineBuffer(fn func(line []byte), hold func() time.Duration) *lineBuffer {
+	return &lineBuffer{fn: fn, hold: hold}
+}
+
+func (b *lineBuffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.partial = append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(b.partial, '\n')
+		if i < 0 {
+			break
+		}
+		b.fn(bytes.Clone(b.partial[:i+1]))
+		b.partial = b.partial[i+1:]
+	}
+	b.gen++
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	if len(b.partial) > 0 {
+		if delay := b.hold(); delay <= 0 || len(b.partial) >= maxPartialLine {
+			b.flushLocked()
+		} else {
+			gen := b.gen
+			b.timer = time.AfterFunc(delay, func() { b.flush(gen) })
+		}
+	}
+	return len(p), nil
+}
+
+// Flush passes on any partial line right away.
+func (b *lineBuffer) Flush() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.flushLocked()
+}
+
+func (b *lineBuffer) flush(gen int) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if gen == b.gen {
+		b.flushLocked()
+	}
+}
+
+func (b *lineBuffer) flushLocked() {
+	if len(b.partial) > 0 {
+		b.fn(b.partial)
+		b.partial = nil
+	}
+}
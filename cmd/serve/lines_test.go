@@ -0,0 +1,45 @@
+package serve
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineBuffer(t *testing.T) {
+	lines := make(chan string, 4)
+	var delay time.Duration
+	b := newLineBuffer(func(line []byte) { lines <- string(line) }, func() time.Duration { return delay })
+
+	delay = 10 * time.Millisecond
+	b.Write([]byte("one\ntw"))
+	require.Equal(t, "one\n", <-lines)
+	b.Write([]byte("o\r\nprompt> "))
+	require.Equal(t, "two\r\n", <-lines)
+	select {
+	case line := <-lines:
+		t.Fatalf("prompt passed on too soon: %q", line)
+	default:
+	}
+	require.Equal(t, "prompt> ", <-lines)
+
+	delay = time.Hour
+	b.Write([]byte("GA> "))
+	b.Flush()
+	require.Equal(t, "GA> ", <-lines)
+
+	delay = 0
+	b.Write([]byte("now> "))
+	require.Len(t, lines, 1)
+	require.Equal(t, "now> ", <-lines)
+
+	delay = time.Hour
+	long := bytes.Repeat([]byte("x"), maxPartialLine)
+	b.Write(long[:maxPartialLine/2])
+	require.Empty(t, lines)
+	b.Write(long[maxPartialLine/2:])
+	require.Len(t, lines, 1)
+	require.Equal(t, string(long), <-lines)
+}
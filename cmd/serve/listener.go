@@ -0,0 +1,76 @@
+package serve
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// listener runs a server on an address from the config. When the settings it
+// uses change, it stops the server and starts it again with the new ones.
+type listener struct {
+	// keys are the settings the server uses, with its address first.
+	keys []string
+	// start starts the server and returns a function that stops it. If the
+	// server is turned off, it returns a nil stop.
+	start func() (stop func(), err error)
+
+	mux    sync.Mutex
+	values []string
+	stop   func()
+}
+
+func newListener(start func() (func(), error), keys ...string) *listener {
+	return &listener{keys: keys, start: start}
+}
+
+func (l *listener) settings() []string {
+	result := make([]string, len(l.keys))
+	for i, key := range l.keys {
+		result[i] = fmt.Sprint(viper.Get(key))
+	}
+	return result
+}
+
+// run starts the server with the current settings.
+func (l *listener) run() error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.values = l.settings()
+	stop, err := l.start()
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", l.keys[0], err)
+	}
+	l.stop = stop
+	return nil
+}
+
+// reload restarts the server if its settings have changed. If it cannot
+// start again, it stays stopped until they change again.
+func (l *listener) reload() {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	values := l.settings()
+	if slices.Equal(values, l.values) {
+		return
+	}
+	l.values = values
+	msg := "started listener"
+	if l.stop != nil {
+		msg = "restarted listener"
+		l.stop()
+		l.stop = nil
+	}
+	stop, err := l.start()
+	l.stop = stop
+	switch {
+	case err != nil:
+		logger.Error().Err(err).Str("setting", l.keys[0]).Msg("error restarting listener")
+	case stop == nil:
+		logger.Info().Str("setting", l.keys[0]).Msg("stopped listener")
+	default:
+		logger.Info().Str("setting", l.keys[0]).Str("addr", values[0]).Msg(msg)
+	}
+}
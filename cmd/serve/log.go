@@ -8,41 +8,47 @@ import (
 	"github.com/stesla/iris/internal/telnet"
 )
 
+// logQueueSize is how many events can wait to be logged before the oldest are
+// dropped.
+const logQueueSize = 256
+
+// LogHandler logs the telnet protocol events at trace level, but not the raw
+// data read and written. The events are logged from a queue, so that writing
+// the log never holds up the connection.
 type LogHandler struct {
 	ctx context.Context
 	zerolog.Logger
+	queue *event.Queue
 }
 
-func (h LogHandler) Register(ctx context.Context) {
+func (h *LogHandler) Register(ctx context.Context) {
 	h.ctx = ctx
-	dispatcher := ctx.Value(telnet.KeyDispatcher).(event.Dispatcher)
-	dispatcher.Listen(telnet.EventEndOfRecord, h)
-	dispatcher.Listen(telnet.EventGoAhead, h)
-	dispatcher.Listen(telnet.EventNegotation, h)
-	dispatcher.Listen(telnet.EventOption, h)
-	dispatcher.Listen(telnet.EventSubnegotiation, h)
-	dispatcher.Listen(telnet.EventSend, h)
-	dispatcher.Listen(telnet.EventCharsetAccepted, h)
-	dispatcher.Listen(telnet.EventCharsetRejected, h)
+	h.queue = event.NewQueue(event.ListenerFunc(h.log), logQueueSize, event.DropOldest)
+	dispatcher, _ := event.FromContext(ctx)
+	for _, name := range telnet.ProtocolEvents {
+		dispatcher.Listen(name, h)
+	}
+}
+
+func (h *LogHandler) Unregister() {
+	dispatcher, _ := event.FromContext(h.ctx)
+	for _, name := range telnet.ProtocolEvents {
+		dispatcher.RemoveListener(name, h)
+	}
+	h.queue.Close()
 }
 
-func (h LogHandler) Unregister() {
-	dispatcher := h.ctx.Value(telnet.KeyDispatcher).(event.Dispatcher)
-	dispatcher.RemoveListener(telnet.EventEndOfRecord, h)
-	dispatcher.RemoveListener(telnet.EventGoAhead, h)
-	dispatcher.RemoveListener(telnet.EventNegotation, h)
-	dispatcher.RemoveListener(telnet.EventOption, h)
-	dispatcher.RemoveListener(telnet.EventSubnegotiation, h)
-	dispatcher.RemoveListener(telnet.EventSend, h)
-	dispatcher.RemoveListener(telnet.EventCharsetAccepted, h)
-	dispatcher.RemoveListener(telnet.EventCharsetRejected, h)
+// Listen queues an event to be logged, if trace logging is on.
+func (h *LogHandler) Listen(ctx context.Context, ev event.Event) error {
+	if h.GetLevel() > zerolog.TraceLevel || zerolog.GlobalLevel() > zerolog.TraceLevel {
+		return nil
+	}
+	return h.queue.Listen(ctx, ev)
 }
 
-func (h LogHandler) Listen(_ context.Context, ev event.Event) error {
+func (h *LogHandler) log(_ context.Context, ev event.Event) error {
 	log := h.Trace().Str("event", string(ev.Name))
 	switch t := ev.Data.(type) {
-	case []byte:
-		log.Bytes("data", t)
 	case telnet.OptionData:
 		log.Uint8("option", t.Option()).
 			Bool("resolvedThem", t.ResolvedThem).
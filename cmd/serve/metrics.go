@@ -0,0 +1,235 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/viper"
+
+	"github.com/stesla/iris/internal/event"
+	"github.com/stesla/iris/internal/telnet"
+)
+
+var (
+	metricReceivedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iris_upstream_received_bytes_total",
+		Help: "Bytes received from each upstream's game.",
+	}, []string{"upstream"})
+	metricSentBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iris_upstream_sent_bytes_total",
+		Help: "Bytes sent to each upstream's game.",
+	}, []string{"upstream"})
+	metricConnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iris_upstream_connects_total",
+		Help: "Connections made to each upstream's game.",
+	}, []string{"upstream"})
+	metricReconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iris_upstream_reconnects_total",
+		Help: "Connections made to each upstream's game after the first since iris started.",
+	}, []string{"upstream"})
+	metricOptions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "iris_upstream_option_enabled",
+		Help: "Whether a telnet option is enabled with each upstream, for iris (us) or the game (them).",
+	}, []string{"upstream", "option", "side"})
+	metricAuthFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iris_auth_failures_total",
+		Help: "Clients that failed to authenticate, by whether they gave bad credentials or were locked out.",
+	}, []string{"reason"})
+	metricRefused = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iris_clients_refused_total",
+		Help: "Clients refused before authenticating, by whether their address is denied or too many were pending.",
+	}, []string{"reason"})
+	metricHistoryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iris_history_write_errors_total",
+		Help: "Errors writing each upstream's log.",
+	}, []string{"upstream"})
+	metricDispatch = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "iris_event_dispatch_seconds",
+		Help:    "How long events take to handle, by the dispatcher they went through.",
+		Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+	}, []string{"dispatcher", "event"})
+)
+
+// optionNames are the telnet options reported in the metrics.
+var optionNames = map[byte]string{
+	telnet.TransmitBinary:  "transmit-binary",
+	telnet.Echo:            "echo",
+	telnet.SuppressGoAhead: "suppress-go-ahead",
+	telnet.TerminalType:    "terminal-type",
+	telnet.EndOfRecord:     "end-of-record",
+	telnet.NAWS:            "naws",
+	telnet.Charset:         "charset",
+	telnet.GMCP:            "gmcp",
+}
+
+// observeDispatch returns an event option that records dispatch latency under
+// the given dispatcher name.
+func observeDispatch(dispatcher string) event.Option {
+	return event.WithLatencyHook(func(ev event.Event, d time.Duration) {
+		metricDispatch.WithLabelValues(dispatcher, string(ev.Name)).Observe(d.Seconds())
+	})
+}
+
+// poolCollector reports what is connected when the metrics are scraped.
+type poolCollector struct {
+	pool        *SessionPool
+	upstreams   *prometheus.Desc
+	downstreams *prometheus.Desc
+}
+
+func newPoolCollector(pool *SessionPool) *poolCollector {
+	return &poolCollector{
+		pool: pool,
+		upstreams: prometheus.NewDesc("iris_upstreams",
+			"Upstreams connected to their games.", nil, nil),
+		downstreams: prometheus.NewDesc("iris_downstreams",
+			"Clients attached to each connected upstream.", []string{"upstream"}, nil),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.upstreams
+	ch <- c.downstreams
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.pool.Lock()
+	var connected []*upstream
+	for _, s := range c.pool.streams {
+		if s.ready.Load() {
+			connected = append(connected, s)
+		}
+	}
+	c.pool.Unlock()
+	ch <- prometheus.MustNewConstMetric(c.upstreams, prometheus.GaugeValue, float64(len(connected)))
+	for _, s := range connected {
+		ch <- prometheus.MustNewConstMetric(c.downstreams, prometheus.GaugeValue, float64(len(s.clients())), s.key)
+	}
+}
+
+// meteredConn counts the bytes to and from an upstream's game.
+type meteredConn struct {
+	net.Conn
+	received, sent prometheus.Counter
+}
+
+func newMeteredConn(conn net.Conn, key string) *meteredConn {
+	return &meteredConn{
+		Conn:     conn,
+		received: metricReceivedBytes.WithLabelValues(key),
+		sent:     metricSentBytes.WithLabelValues(key),
+	}
+}
+
+func (c *meteredConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.received.Add(float64(n))
+	return n, err
+}
+
+func (c *meteredConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.sent.Add(float64(n))
+	return n, err
+}
+
+// upstreamsSeen is the upstreams that have connected since iris started, to
+// count their reconnects.
+var upstreamsSeen sync.Map
+
+func countConnect(key string) {
+	metricConnects.WithLabelValues(key).Inc()
+	if _, seen := upstreamsSeen.LoadOrStore(key, struct{}{}); seen {
+		metricReconnects.WithLabelValues(key).Inc()
+	}
+}
+
+func countRefused(err error) {
+	switch {
+	case errors.Is(err, errDenied):
+		metricRefused.WithLabelValues("denied").Inc()
+	case errors.Is(err, errTooPending):
+		metricRefused.WithLabelValues("pending").Inc()
+	}
+}
+
+func (s *upstream) recordOption(_ context.Context, opt telnet.OptionData) error {
+	name, found := optionNames[opt.Option()]
+	if !found {
+		return nil
+	}
+	gauge := func(enabled bool) float64 {
+		if enabled {
+			return 1
+		}
+		return 0
+	}
+	metricOptions.WithLabelValues(s.key, name, "us").Set(gauge(opt.EnabledForUs()))
+	metricOptions.WithLabelValues(s.key, name, "them").Set(gauge(opt.EnabledForThem()))
+	return nil
+}
+
+func forgetOptions(key string) {
+	metricOptions.DeletePartialMatch(prometheus.Labels{"upstream": key})
+}
+
+// listening is set while iris is listening for clients.
+var listening atomic.Bool
+
+// metricsListener serves /metrics, /healthz and /readyz.
+func metricsListener(db *sql.DB, sessions *SessionPool) *listener {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newPoolCollector(sessions),
+		metricReceivedBytes, metricSentBytes, metricConnects, metricReconnects, metricOptions,
+		metricAuthFailures, metricRefused, metricHistoryErrors, metricDispatch,
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !listening.Load() {
+			http.Error(w, "not listening", http.StatusServiceUnavailable)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+		defer cancel()
+		if err := db.PingContext(ctx); err != nil {
+			http.Error(w, "database: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+
+	return newListener(func() (func(), error) {
+		addr := viper.GetString("metrics.addr")
+		if addr == "" {
+			return nil, nil
+		}
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		server := &http.Server{Handler: mux}
+		go func() {
+			if err := server.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+				logger.Fatal().Err(err).Msg("error serving metrics")
+			}
+		}()
+		return func() { server.Close() }, nil
+	}, "metrics.addr")
+}
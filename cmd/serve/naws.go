@@ -0,0 +1,58 @@
+package serve
+
+import (
+	"context"
+
+	"github.com/stesla/iris/internal/telnet"
+)
+
+// startNAWS passes the client's window size on to the game. With several
+// clients attached, the last to send one wins.
+func (s *downstream) startNAWS() {
+	s.naws.IsServer = true
+	s.conn.RegisterHandler(&s.naws)
+	telnet.TopicWindowSize.Subscribe(s.conn, s.handleWindowSize)
+}
+
+func (s *downstream) handleWindowSize(_ context.Context, size telnet.WindowSize) error {
+	s.mux.Lock()
+	s.size = size
+	s.mux.Unlock()
+	// A size sent before the handshake goes on from sendWindowSize instead.
+	if s.upstream != nil && !s.spectating() {
+		return s.upstream.setWindowSize(size)
+	}
+	return nil
+}
+
+// sendWindowSize gives the upstream the client's window size, if it has sent
+// one.
+func (s *downstream) sendWindowSize() error {
+	s.mux.Lock()
+	size := s.size
+	s.mux.Unlock()
+	if size == (telnet.WindowSize{}) || s.spectating() {
+		return nil
+	}
+	return s.upstream.setWindowSize(size)
+}
+
+func (s *upstream) startNAWS() {
+	s.conn.RegisterHandler(&s.naws)
+	s.mux.Lock()
+	size := s.size
+	s.mux.Unlock()
+	if size != (telnet.WindowSize{}) {
+		s.naws.SetSize(size)
+	}
+}
+
+func (s *upstream) setWindowSize(size telnet.WindowSize) error {
+	s.mux.Lock()
+	s.size = size
+	s.mux.Unlock()
+	if !s.IsConnected() {
+		return nil
+	}
+	return s.naws.SetSize(size)
+}
@@ -0,0 +1,83 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/stesla/iris/internal/notify"
+)
+
+// notifyOverride is an entry in notify.upstreams, which gives an upstream its
+// own quiet hours or dedup instead of notify.quiet_hours and notify.dedup.
+type notifyOverride struct {
+	Upstream   string         `mapstructure:"upstream"`
+	QuietHours *string        `mapstructure:"quiet_hours"`
+	Dedup      *time.Duration `mapstructure:"dedup"`
+}
+
+func loadNotifyConfig() (config notify.Config, err error) {
+	if url := viper.GetString("notify.webhook"); url != "" {
+		config.Webhook = &notify.Webhook{URL: url}
+	}
+	if config.Quiet, err = notify.ParseQuietHours(viper.GetString("notify.quiet_hours")); err != nil {
+		return
+	}
+	config.Dedup = viper.GetDuration("notify.dedup")
+
+	var overrides []notifyOverride
+	if err = viper.UnmarshalKey("notify.upstreams", &overrides); err != nil {
+		return config, fmt.Errorf("notify.upstreams: %w", err)
+	}
+	config.Upstreams = make(map[string]notify.Policy, len(overrides))
+	for _, o := range overrides {
+		if o.Upstream == "" {
+			return config, errors.New("notify.upstreams: an entry has no upstream")
+		}
+		policy := config.Policy
+		if o.QuietHours != nil {
+			if policy.Quiet, err = notify.ParseQuietHours(*o.QuietHours); err != nil {
+				return config, fmt.Errorf("notify.upstreams: %s: %w", o.Upstream, err)
+			}
+		}
+		if o.Dedup != nil {
+			policy.Dedup = *o.Dedup
+		}
+		config.Upstreams[o.Upstream] = policy
+	}
+	return
+}
+
+func (s *upstream) deliverNotification(_ context.Context, msg string) error {
+	s.pool.notifier.Notify(notify.Notification{
+		Upstream: s.key,
+		Text:     msg,
+		Time:     time.Now(),
+	}, !s.hasClients())
+	return nil
+}
+
+// hasClients reports whether any clients are attached, not counting the log
+// or anything tailing it.
+func (s *upstream) hasClients() bool {
+	return len(s.clients()) > 0
+}
+
+// writeDigest shows a client the notifications it missed.
+func (s *downstream) writeDigest() {
+	digest := s.pool.notifier.TakeDigest(s.Name)
+	if len(digest) == 0 {
+		return
+	}
+	if len(digest) == 1 {
+		s.notice("1 notification while you were away:")
+	} else {
+		s.notice("%d notifications while you were away:", len(digest))
+	}
+	for _, n := range digest {
+		s.notice("[%s] %s", n.Time.Local().Format(time.DateTime), n.Text)
+	}
+}
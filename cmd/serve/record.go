@@ -0,0 +1,35 @@
+package serve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stesla/iris/internal/event"
+	"github.com/stesla/iris/internal/telnet"
+)
+
+const recordTimeFormat = "20060102-150405"
+
+// startRecording writes the events on the upstream connection to a file in
+// record.dir, which telnet.NewReplayer can play back. What is sent upstream is
+// left out, since it may hold passwords. It does nothing unless record.dir is
+// set.
+func (s *upstream) startRecording() error {
+	dir := s.pool.config().recordDir
+	if dir == "" {
+		return nil
+	}
+	name := fmt.Sprintf("%s-%s.jsonl", s.key, time.Now().Format(recordTimeFormat))
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening recording for key (%v): %w", s.key, err)
+	}
+	rec := event.NewRecorder(f)
+	rec.Ignore(telnet.EventSend)
+	rec.Attach(s.conn)
+	s.recording = f
+	s.logger.Info().Str("file", f.Name()).Msg("recording")
+	return nil
+}
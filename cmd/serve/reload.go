@@ -0,0 +1,143 @@
+package serve
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// configSettleTime is how long the config file has to stay the same before it
+// is reloaded, since editors often save a file in more than one write.
+const configSettleTime = 250 * time.Millisecond
+
+// restartSettings are only read when iris starts.
+var restartSettings = []string{"db"}
+
+// reconnectSettings are read when an upstream connects, so they do not change
+// for upstreams that are already connected.
+var reconnectSettings = []string{"record.dir"}
+
+// reloader applies changes to the config file while iris is running.
+type reloader struct {
+	// loadConfig applies the settings that the sessions read.
+	loadConfig func() error
+	listeners  []*listener
+
+	mux      sync.Mutex
+	settings map[string]string
+}
+
+func newReloader(loadConfig func() error, listeners []*listener) *reloader {
+	return &reloader{
+		loadConfig: loadConfig,
+		listeners:  listeners,
+		settings:   currentSettings(),
+	}
+}
+
+func currentSettings() map[string]string {
+	result := make(map[string]string)
+	for _, key := range viper.AllKeys() {
+		result[key] = fmt.Sprint(viper.Get(key))
+	}
+	return result
+}
+
+// reload reads the config file again and applies whatever has changed. If the
+// file cannot be read, the config stays as it was.
+func (r *reloader) reload() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if err := viper.ReadInConfig(); err != nil {
+		logger.Error().Err(err).Msg("error reloading config, so it has not changed")
+		return
+	}
+	settings := currentSettings()
+	var changed []string
+	for key, value := range settings {
+		if old, ok := r.settings[key]; !ok || old != value {
+			changed = append(changed, key)
+		}
+	}
+	for key := range r.settings {
+		if _, ok := settings[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	slices.Sort(changed)
+	r.settings = settings
+	logger.Info().Strs("settings", changed).Msg("config changed")
+	for _, key := range changed {
+		if slices.Contains(restartSettings, key) {
+			logger.Warn().Str("setting", key).Msg("setting cannot change while iris is running, and takes effect when it restarts")
+		}
+		if slices.Contains(reconnectSettings, key) {
+			logger.Warn().Str("setting", key).Msg("setting takes effect for each upstream the next time it connects")
+		}
+	}
+
+	if err := setLogLevel(); err != nil {
+		logger.Error().Err(err).Msg("error setting log.level")
+	}
+	if err := r.loadConfig(); err != nil {
+		logger.Error().Err(err).Msg("error applying config")
+	}
+	for _, l := range r.listeners {
+		l.reload()
+	}
+}
+
+// watchConfig calls reload when the config file changes. It watches the
+// file's directory, so that it sees editors that save by replacing the file.
+func watchConfig(reload func()) error {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return errors.New("there is no config file")
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return err
+	}
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) != path || !ev.Has(fsnotify.Write|fsnotify.Create) {
+					continue
+				}
+				if timer == nil {
+					timer = time.AfterFunc(configSettleTime, reload)
+				} else {
+					timer.Reset(configSettleTime)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Error().Err(err).Msg("error watching config file")
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,144 @@
+package serve
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// stubServer is a listener's start function that records what it does. It is
+// turned off when addr is empty, and fails when addr is "bad".
+type stubServer struct {
+	started []string
+	stopped []string
+}
+
+func (s *stubServer) start() (func(), error) {
+	addr := viper.GetString("addr")
+	switch addr {
+	case "":
+		return nil, nil
+	case "bad":
+		return nil, errors.New("bad address")
+	}
+	s.started = append(s.started, addr)
+	return func() { s.stopped = append(s.stopped, addr) }, nil
+}
+
+func resetConfig(t *testing.T) {
+	t.Helper()
+	viper.Reset()
+	level := zerolog.GlobalLevel()
+	saved := logger
+	logger = zerolog.Nop()
+	t.Cleanup(func() {
+		viper.Reset()
+		zerolog.SetGlobalLevel(level)
+		logger = saved
+	})
+}
+
+func TestListenerReload(t *testing.T) {
+	type settings struct{ addr, other string }
+	var tests = []struct {
+		name    string
+		from    settings
+		to      []settings
+		started []string
+		stopped []string
+	}{
+		{"unchanged", settings{":1", "a"}, []settings{{":1", "a"}}, []string{":1"}, nil},
+		{"restart", settings{":1", "a"}, []settings{{":2", "a"}}, []string{":1", ":2"}, []string{":1"}},
+		{"other key", settings{":1", "a"}, []settings{{":1", "b"}}, []string{":1", ":1"}, []string{":1"}},
+		{"stop", settings{":1", "a"}, []settings{{"", "a"}}, []string{":1"}, []string{":1"}},
+		{"start", settings{"", "a"}, []settings{{":1", "a"}}, []string{":1"}, nil},
+		{"error", settings{":1", "a"}, []settings{{"bad", "a"}, {":2", "a"}}, []string{":1", ":2"}, []string{":1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetConfig(t)
+			stub := &stubServer{}
+			l := newListener(stub.start, "addr", "other")
+			viper.Set("addr", test.from.addr)
+			viper.Set("other", test.from.other)
+			require.NoError(t, l.run())
+			for _, to := range test.to {
+				viper.Set("addr", to.addr)
+				viper.Set("other", to.other)
+				viper.Set("unrelated", to.addr)
+				l.reload()
+			}
+			require.Equal(t, test.started, stub.started)
+			require.Equal(t, test.stopped, stub.stopped)
+		})
+	}
+}
+
+func TestListenerRunError(t *testing.T) {
+	resetConfig(t)
+	viper.Set("addr", "bad")
+	err := newListener((&stubServer{}).start, "addr").run()
+	require.ErrorContains(t, err, "error listening on addr")
+}
+
+func writeConfig(t *testing.T, path, src string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(src), 0o600))
+}
+
+func TestReloaderReload(t *testing.T) {
+	resetConfig(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "addr: \":1\"\nlog: {level: info}\n")
+	viper.SetConfigFile(path)
+	require.NoError(t, viper.ReadInConfig())
+
+	stub := &stubServer{}
+	l := newListener(stub.start, "addr")
+	require.NoError(t, l.run())
+	loads := 0
+	r := newReloader(func() error { loads++; return nil }, []*listener{l})
+
+	r.reload()
+	require.Equal(t, 0, loads, "nothing changed")
+
+	writeConfig(t, path, "addr: \":2\"\nlog: {level: warn}\n")
+	r.reload()
+	require.Equal(t, 1, loads)
+	require.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+	require.Equal(t, []string{":1", ":2"}, stub.started)
+
+	writeConfig(t, path, "addr: [\n")
+	r.reload()
+	require.Equal(t, 1, loads, "bad config is not applied")
+	require.Equal(t, ":2", viper.GetString("addr"))
+
+	writeConfig(t, path, "addr: \":2\"\nlog: {level: warn}\ndb: other.db\n")
+	r.reload()
+	require.Equal(t, 2, loads)
+	require.Equal(t, []string{":1", ":2"}, stub.started, "listener settings did not change")
+}
+
+func TestLoadConfigAllOrNothing(t *testing.T) {
+	resetConfig(t)
+	pool := NewSessionPool(nil, zerolog.Nop())
+	viper.Set("prompt.delay", "250ms")
+	require.NoError(t, pool.LoadConfig())
+	cfg := pool.config()
+
+	viper.Set("prompt.delay", "1s")
+	viper.Set("auth.lockout.upstream_delay", "-1s")
+	require.ErrorContains(t, pool.LoadConfig(), "auth.lockout.upstream_delay")
+	require.Same(t, cfg, pool.config())
+
+	viper.Set("auth.lockout.upstream_delay", "1s")
+	require.NoError(t, pool.LoadConfig())
+	require.Equal(t, time.Second, pool.config().promptDelay)
+	require.Equal(t, 250*time.Millisecond, cfg.promptDelay)
+}
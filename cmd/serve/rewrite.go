@@ -0,0 +1,75 @@
+package serve
+
+import (
+	"io"
+	"strings"
+
+	"github.com/stesla/iris/internal/rewrite"
+)
+
+func (p *SessionPool) loadRewrites(key string) (*rewrite.Set, error) {
+	rows, err := p.db.Query("SELECT id, pattern, action, argument, log FROM rewrites WHERE upstream=? ORDER BY id", key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var rules []rewrite.Rule
+	for rows.Next() {
+		r := rewrite.Rule{Upstream: key}
+		if err := rows.Scan(&r.ID, &r.Pattern, &r.Action, &r.Argument, &r.Log); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rewrite.Compile(rules)
+}
+
+// ReloadRewrites reads the rewrite rules for a connected upstream from the
+// database again, after they have been changed.
+func (p *SessionPool) ReloadRewrites(key string) error {
+	s, found := p.upstreamWithKey(key)
+	if !found {
+		return nil
+	}
+	return s.loadRewrites()
+}
+
+func (s *upstream) loadRewrites() error {
+	rewrites, err := s.pool.loadRewrites(s.key)
+	if err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.rewrites = rewrites
+	return nil
+}
+
+// replayWriter applies the rewrite rules that the log has not already had to
+// the history it is given, before writing it to a client.
+type replayWriter struct {
+	io.Writer
+	rewrites *rewrite.Set
+}
+
+func (s *upstream) replayWriter(w io.Writer) io.Writer {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return replayWriter{Writer: w, rewrites: s.rewrites}
+}
+
+func (w replayWriter) Write(p []byte) (int, error) {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(string(p), "\n") {
+		if text, ok := w.rewrites.Apply(line, rewrite.Replay); ok {
+			b.WriteString(text)
+		}
+	}
+	if _, err := io.WriteString(w.Writer, b.String()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
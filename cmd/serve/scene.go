@@ -0,0 +1,110 @@
+package serve
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/stesla/iris/internal/logs"
+)
+
+const (
+	logKindSceneStart = logs.KindSceneStart
+	logKindSceneStop  = logs.KindSceneStop
+)
+
+type scenePatterns struct {
+	start []*regexp.Regexp
+	stop  []*regexp.Regexp
+}
+
+func loadScenePatterns() (*scenePatterns, error) {
+	var err error
+	p := &scenePatterns{}
+	if p.start, err = compilePatterns(viper.GetStringSlice("scenes.start")); err != nil {
+		return nil, err
+	}
+	if p.stop, err = compilePatterns(viper.GetStringSlice("scenes.stop")); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func compilePatterns(patterns []string) (result []*regexp.Regexp, err error) {
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling pattern (%v): %w", pattern, err)
+		}
+		result = append(result, re)
+	}
+	return
+}
+
+// match checks a line of input against the configured patterns. The first
+// capture group of a start pattern, if any, names the scene.
+func (p *scenePatterns) match(line string) (start, stop bool, name string) {
+	for _, re := range p.start {
+		if m := re.FindStringSubmatch(line); m != nil {
+			if len(m) > 1 {
+				name = m[1]
+			}
+			return true, false, name
+		}
+	}
+	for _, re := range p.stop {
+		if re.MatchString(line) {
+			return false, true, ""
+		}
+	}
+	return
+}
+
+func (s *upstream) StartScene(name string) (string, error) {
+	t := time.Now().Truncate(time.Second)
+	if name == "" {
+		name = "scene-" + t.Format("2006-01-02-1504")
+	}
+	if err := s.StopScene(); err != nil && !errors.Is(err, errNoScene) {
+		return "", err
+	}
+	_, err := s.pool.db.Exec(
+		"INSERT INTO scenes (upstream, name, started_at) VALUES (?, ?, ?)",
+		s.key, name, t,
+	)
+	if err != nil {
+		return "", err
+	}
+	return name, s.writeHistorySeparator(logKindSceneStart+" "+name, t)
+}
+
+var errNoScene = errors.New("no scene in progress")
+
+func (s *upstream) StopScene() error {
+	t := time.Now().Truncate(time.Second)
+	var id int64
+	var name string
+	row := s.pool.db.QueryRow(
+		"SELECT id, name FROM scenes WHERE upstream=? AND stopped_at IS NULL ORDER BY started_at DESC",
+		s.key,
+	)
+	if err := row.Scan(&id, &name); errors.Is(err, sql.ErrNoRows) {
+		return errNoScene
+	} else if err != nil {
+		return err
+	}
+	if _, err := s.pool.db.Exec("UPDATE scenes SET stopped_at=? WHERE id=?", t, id); err != nil {
+		return err
+	}
+	return s.writeHistorySeparator(logKindSceneStop+" "+name, t)
+}
+
+func (s *upstream) writeHistorySeparator(kind string, t time.Time) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return logs.WriteSeparator(s.history, kind, t)
+}
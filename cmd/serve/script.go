@@ -0,0 +1,112 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/stesla/iris/internal/script"
+	"github.com/stesla/iris/internal/telnet"
+	"github.com/stesla/iris/internal/trigger"
+)
+
+const (
+	// scriptTypeText is a connect script that is a single command, with
+	// %LOGIN% and %PASSWORD% replaced.
+	scriptTypeText = "text"
+	// scriptTypeSteps is a connect script of wait, send and sleep steps.
+	scriptTypeSteps    = "steps"
+	scriptTypeStarlark = "starlark"
+)
+
+// checkConnectScript reports whether a connect script of the given type would
+// run.
+func checkConnectScript(src, scriptType string) error {
+	switch scriptType {
+	case "", scriptTypeText:
+		return nil
+	case scriptTypeSteps:
+		_, err := script.ParseSteps(src)
+		return err
+	case scriptTypeStarlark:
+		return script.Check("connect.star", src, "login", "password")
+	}
+	return fmt.Errorf("unknown script type: %q", scriptType)
+}
+
+// scriptHost gives scripts access to their upstream.
+type scriptHost struct {
+	*upstream
+}
+
+func (h scriptHost) Send(line string) error {
+	_, err := io.WriteString(h.upstream, line+"\n")
+	return err
+}
+
+func (h scriptHost) SendGMCP(pkg string, data []byte) error {
+	return h.gmcp.Send(telnet.GMCPMessage{Package: pkg, Data: data})
+}
+
+func (h scriptHost) Print(msg string) {
+	h.logger.Info().Str("session-key", h.key).Str("text", msg).Msg("script")
+}
+
+func (h scriptHost) Error(err error) {
+	h.logger.Error().Err(err).Str("session-key", h.key).Msg("script error")
+}
+
+func (s *upstream) startScripts() {
+	s.script = script.New(scriptHost{s})
+	s.conn.RegisterHandler(&s.gmcp)
+	telnet.TopicGMCP.Subscribe(s.conn, func(_ context.Context, msg telnet.GMCPMessage) error {
+		s.script.GMCP(msg.Package, msg.Data)
+		return nil
+	})
+}
+
+// runScript runs a script to completion and logs any error.
+func (s *upstream) runScript(name, src string, vars starlark.StringDict) {
+	if err := s.script.Run(name, src, vars); err != nil {
+		scriptHost{s}.Error(err)
+	}
+}
+
+// queueTriggerScript runs a trigger's script once the scripts ahead of it have
+// run, without holding up the line that fired it.
+func (s *upstream) queueTriggerScript(t trigger.Script) {
+	match := make(starlark.Tuple, len(t.Match))
+	for i, group := range t.Match {
+		match[i] = starlark.String(group)
+	}
+	name := fmt.Sprintf("trigger-%d.star", t.TriggerID)
+	if err := s.script.RunLater(name, t.Source, starlark.StringDict{"match": match}); err != nil {
+		s.logger.Error().Err(err).Str("session-key", s.key).Int64("trigger", t.TriggerID).Msg("error running trigger script")
+	}
+}
+
+// runConnectScript runs a steps or starlark connect script, and reports it to
+// the downstream if it fails.
+func (s *downstream) runConnectScript(src, scriptType, login string) {
+	var err error
+	switch scriptType {
+	case scriptTypeSteps:
+		var steps []script.Step
+		if steps, err = script.ParseSteps(src); err == nil {
+			replacer := strings.NewReplacer("%LOGIN%", login, "%PASSWORD%", s.upstreamPassword())
+			err = s.upstream.script.RunSteps(steps, replacer.Replace)
+		}
+	case scriptTypeStarlark:
+		err = s.upstream.script.RunConnect("connect.star", src, starlark.StringDict{
+			"login":    starlark.String(login),
+			"password": starlark.String(s.upstreamPassword()),
+		})
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("upstream", s.Name).Msg("connect script failed")
+		s.notice("connect script failed: %v", err)
+	}
+}
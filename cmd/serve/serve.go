@@ -3,9 +3,12 @@ package serve
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -14,9 +17,14 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/auth"
 )
 
 var logger = zerolog.New(os.Stdout)
@@ -32,25 +40,104 @@ func AddToCommand(cmd *cobra.Command) {
 func Serve(cmd *cobra.Command, args []string) {
 	db, err := sql.Open("sqlite3", viper.GetString("db"))
 	cobra.CheckErr(err)
+	cobra.CheckErr(setLogLevel())
 
-	if l, err := zerolog.ParseLevel(viper.GetString("log.level")); err != nil {
-		cobra.CheckErr(err)
-	} else {
-		logger.Level(l)
-	}
+	sessions := NewSessionPool(db, logger)
+	cobra.CheckErr(sessions.LoadConfig())
 
-	go runApiServer(db)
-	runTelnetProxy(db)
+	listeners := apiListeners(db, sessions)
+	listeners = append(listeners, metricsListener(db, sessions), telnetListener(sessions))
+	for _, l := range listeners {
+		if err := l.run(); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+	r := newReloader(sessions.LoadConfig, listeners)
+	if err := watchConfig(r.reload); err != nil {
+		logger.Error().Err(err).Msg("error watching the config file, so changes to it need a restart")
+	}
+	waitForExit(sessions)
 }
 
-func runApiServer(db *sql.DB) {
-	l, err := net.Listen("tcp", viper.GetString("grpc.server.addr"))
+func setLogLevel() error {
+	l, err := zerolog.ParseLevel(viper.GetString("log.level"))
 	if err != nil {
-		logger.Fatal().Err(err).Msg("error listening on grpc.server.addr")
+		return err
 	}
-	s := grpc.NewServer()
-	api.RegisterUpstreamsServer(s, &apiServer{db: db})
-	if err := s.Serve(l); err != nil {
+	zerolog.SetGlobalLevel(l)
+	return nil
+}
+
+func apiListeners(db *sql.DB, sessions *SessionPool) []*listener {
+	register := func(s *grpc.Server) {
+		api.RegisterUpstreamsServer(s, &apiServer{db: db})
+		api.RegisterLogsServer(s, &logsServer{db: db, sessions: sessions})
+		api.RegisterAliasesServer(s, &aliasesServer{db: db, sessions: sessions})
+		api.RegisterTimersServer(s, &timersServer{db: db, sessions: sessions})
+		api.RegisterTriggersServer(s, &triggersServer{db: db, sessions: sessions})
+		api.RegisterRewritesServer(s, &rewritesServer{db: db, sessions: sessions})
+		api.RegisterNotificationsServer(s, &notificationsServer{sessions: sessions})
+		api.RegisterUsersServer(s, &usersServer{db: db, sessions: sessions})
+		api.RegisterTokensServer(s, &tokensServer{db: db})
+		api.RegisterAuditServer(s, &auditServer{db: db})
+		api.RegisterStatusServer(s, &statusServer{sessions: sessions})
+		api.RegisterSessionsServer(s, &sessionsServer{db: db, sessions: sessions})
+	}
+
+	// The local socket is only open to this user and root, so it needs no
+	// other authentication.
+	a := &apiAuth{db: db}
+	socket := newListener(func() (func(), error) {
+		path := viper.GetString("grpc.server.socket")
+		if path == "" {
+			return nil, nil
+		}
+		l, err := auth.ListenUnix(path, func(err error) {
+			logger.Warn().Err(err).Msg("rejected connection on grpc.server.socket")
+		})
+		if err != nil {
+			return nil, err
+		}
+		s := grpc.NewServer(
+			grpc.Creds(newSocketCredentials()),
+			grpc.UnaryInterceptor(a.unary),
+			grpc.StreamInterceptor(a.stream),
+		)
+		register(s)
+		go serveApi(s, l)
+		return s.Stop, nil
+	}, "grpc.server.socket")
+
+	tcp := newListener(func() (func(), error) {
+		addr := viper.GetString("grpc.server.addr")
+		if addr == "" {
+			return nil, nil
+		}
+		config, err := serverTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("error configuring grpc tls: %w", err)
+		}
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		s := grpc.NewServer(
+			grpc.Creds(credentials.NewTLS(config)),
+			grpc.UnaryInterceptor(a.unary),
+			grpc.StreamInterceptor(a.stream),
+		)
+		register(s)
+		go serveApi(s, l)
+		return s.Stop, nil
+	}, "grpc.server.addr", "grpc.server.tls.cert", "grpc.server.tls.key", "grpc.server.tls.client_ca")
+
+	return []*listener{socket, tcp}
+}
+
+// serveApi serves the API until s stops, which a reload can do before Serve
+// has even started.
+func serveApi(s *grpc.Server, l net.Listener) {
+	if err := s.Serve(l); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
 		logger.Fatal().Err(err).Msg("error serving grpc")
 	}
 }
@@ -60,21 +147,31 @@ type apiServer struct {
 	db *sql.DB
 }
 
-func (s *apiServer) AddUpstream(_ context.Context, r *api.AddUpstreamRequest) (*emptypb.Empty, error) {
+func (s *apiServer) AddUpstream(ctx context.Context, r *api.AddUpstreamRequest) (*emptypb.Empty, error) {
+	scriptType := r.GetScriptType()
+	if scriptType == "" {
+		scriptType = scriptTypeText
+	}
+	if err := checkConnectScript(r.GetScript(), scriptType); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	hash, err := bcrypt.GenerateFromPassword([]byte(*r.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
 
 	_, err = s.db.Exec(
-		"INSERT INTO upstreams (name, address, login, bcrypt, script) VALUES (?, ?, ?, ?, ?)",
-		r.Upstream.Name, r.Upstream.Address, r.Upstream.Login, hash, r.Script,
+		"INSERT INTO upstreams (name, address, login, bcrypt, script, script_type) VALUES (?, ?, ?, ?, ?, ?)",
+		r.Upstream.Name, r.Upstream.Address, r.Upstream.Login, hash, r.Script, scriptType,
 	)
-
-	return &emptypb.Empty{}, err
+	if err != nil {
+		return nil, err
+	}
+	auditCall(ctx, s.db, auditUpstreamAdd, r.Upstream.GetName(), r.Upstream.GetAddress())
+	return &emptypb.Empty{}, nil
 }
 
-func (s *apiServer) EditUpstream(_ context.Context, r *api.EditUpstreamRequest) (*emptypb.Empty, error) {
+func (s *apiServer) EditUpstream(ctx context.Context, r *api.EditUpstreamRequest) (*emptypb.Empty, error) {
 	var hash string
 	row := s.db.QueryRow("SELECT bcrypt FROM upstreams WHERE name=?", r.Name)
 	if err := row.Scan(&hash); err != nil {
@@ -84,26 +181,86 @@ func (s *apiServer) EditUpstream(_ context.Context, r *api.EditUpstreamRequest)
 		return nil, err
 	}
 
-	query := "UPDATE upstreams SET"
-	args := []any{}
-	fields := map[string]*string{
-		"name":     r.NewName,
-		"password": r.NewPassword,
-		"address":  r.Address,
-		"login":    r.Login,
-		"script":   r.Script,
+	if r.Script != nil || r.ScriptType != nil {
+		var script sql.NullString
+		var scriptType string
+		row := s.db.QueryRow("SELECT script, script_type FROM upstreams WHERE name=?", r.Name)
+		if err := row.Scan(&script, &scriptType); err != nil {
+			return nil, err
+		}
+		if r.Script != nil {
+			script.String = *r.Script
+		}
+		if r.ScriptType != nil {
+			scriptType = *r.ScriptType
+		}
+		if err := checkConnectScript(script.String, scriptType); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 	}
-	for field, value := range fields {
-		if value != nil {
-			query += " " + field + "=?"
-			args = append(args, *value)
+
+	var newHash *string
+	if r.NewPassword != nil {
+		hash, err := bcrypt.GenerateFromPassword([]byte(*r.NewPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
 		}
+		newHash = proto.String(string(hash))
+	}
+
+	// Secret fields are audited by name only.
+	fields := []struct {
+		column, name string
+		value        *string
+		secret       bool
+	}{
+		{"name", "name", r.NewName, false},
+		{"bcrypt", "password", newHash, true},
+		{"address", "address", r.Address, false},
+		{"login", "login", r.Login, false},
+		{"script", "script", r.Script, true},
+		{"script_type", "script_type", r.ScriptType, false},
 	}
-	query += " WHERE name=?"
+	var sets, changed []string
+	args := []any{}
+	for _, f := range fields {
+		if f.value == nil {
+			continue
+		}
+		sets = append(sets, f.column+"=?")
+		args = append(args, *f.value)
+		if f.secret {
+			changed = append(changed, f.name)
+		} else {
+			changed = append(changed, f.name+"="+*f.value)
+		}
+	}
+	if len(sets) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "nothing to change")
+	}
+	query := "UPDATE upstreams SET " + strings.Join(sets, ", ") + " WHERE name=?"
 	args = append(args, *r.Name)
 
-	_, err := s.db.Exec(query, args...)
-	return &emptypb.Empty{}, err
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(query, args...); err != nil {
+		return nil, err
+	}
+	// Grants follow the upstream, so that another upstream given its old
+	// name later does not inherit them.
+	if r.NewName != nil {
+		if _, err := tx.Exec("UPDATE grants SET upstream=? WHERE upstream=?", *r.NewName, *r.Name); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	auditCall(ctx, s.db, auditUpstreamEdit, r.GetName(), strings.Join(changed, " "))
+	return &emptypb.Empty{}, nil
 }
 
 func (s *apiServer) ListUpstreams(context.Context, *emptypb.Empty) (*api.ListUpstreamsResponse, error) {
@@ -120,9 +277,39 @@ func (s *apiServer) ListUpstreams(context.Context, *emptypb.Empty) (*api.ListUps
 	return result, rows.Err()
 }
 
-func runTelnetProxy(db *sql.DB) {
-	sessions := NewSessionPool(db, logger)
+func telnetListener(sessions *SessionPool) *listener {
+	return newListener(func() (func(), error) {
+		l, err := net.Listen("tcp", viper.GetString("addr"))
+		if err != nil {
+			return nil, err
+		}
+		listening.Store(true)
+		logger.Info().Str("addr", l.Addr().String()).Int("pid", os.Getpid()).Msg("listening")
+		go func() {
+			for {
+				tcp, err := l.Accept()
+				if errors.Is(err, net.ErrClosed) {
+					return
+				} else if err != nil {
+					logger.Fatal().Err(err).Send()
+				}
+				go func() {
+					session := sessions.NewDownstream(tcp)
+					defer session.Close()
+					session.runForever()
+				}()
+			}
+		}()
+		return func() {
+			listening.Store(false)
+			l.Close()
+		}, nil
+	}, "addr")
+}
 
+// waitForExit reopens the histories on SIGHUP, so that they can be rotated,
+// and returns after closing every session on SIGINT or SIGTERM.
+func waitForExit(sessions *SessionPool) {
 	signal.Ignore(os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
 
 	chReopenSignal := make(chan os.Signal, 1)
@@ -134,47 +321,9 @@ func runTelnetProxy(db *sql.DB) {
 		}
 	}()
 
-	chExit := make(chan struct{})
 	chExitSignal := make(chan os.Signal, 1)
 	signal.Notify(chExitSignal, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		for sig := range chExitSignal {
-			logger.Info().Str("signal", sig.String()).Msg("exiting")
-			sessions.CloseAll()
-			close(chExit)
-		}
-	}()
-
-	l, err := net.Listen("tcp", viper.GetString("addr"))
-	if err != nil {
-		logger.Fatal().Err(err).Send()
-	}
-	defer l.Close()
-
-	chAccept := make(chan net.Conn)
-	go func() {
-		for {
-			if tcp, err := l.Accept(); err != nil {
-				logger.Fatal().Err(err).Send()
-			} else {
-				chAccept <- tcp
-			}
-		}
-	}()
-
-	logger.Info().Str("addr", viper.GetString("addr")).Int("pid", os.Getpid()).Msg("listening")
-
-loop:
-	for {
-		select {
-		case <-chExit:
-			break loop
-		case tcp := <-chAccept:
-			go func() {
-				session := sessions.NewDownstream(tcp)
-				defer session.Close()
-				session.runForever()
-			}()
-		}
-	}
+	sig := <-chExitSignal
+	logger.Info().Str("signal", sig.String()).Msg("exiting")
+	sessions.CloseAll()
 }
@@ -1,6 +1,7 @@
 package serve
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"database/sql"
@@ -9,18 +10,28 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/netip"
 	"os"
-	"path"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
+	"github.com/stesla/iris/internal/alias"
+	"github.com/stesla/iris/internal/auth"
 	"github.com/stesla/iris/internal/event"
+	"github.com/stesla/iris/internal/logs"
+	"github.com/stesla/iris/internal/notify"
+	"github.com/stesla/iris/internal/rewrite"
+	"github.com/stesla/iris/internal/script"
 	"github.com/stesla/iris/internal/telnet"
+	"github.com/stesla/iris/internal/timer"
+	"github.com/stesla/iris/internal/trigger"
 	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/text/encoding/unicode"
 )
@@ -29,9 +40,13 @@ const readBufSize = 4096
 
 type SessionPool struct {
 	sync.Mutex
-	streams map[string]*upstream
-	db      *sql.DB
-	logger  zerolog.Logger
+	streams  map[string]*upstream
+	db       *sql.DB
+	logger   zerolog.Logger
+	cfg      *sessionConfig
+	notifier *notify.Notifier
+	guard    *clientGuard
+	dial     func(network, address string) (net.Conn, error)
 }
 
 func NewSessionPool(db *sql.DB, logger zerolog.Logger) *SessionPool {
@@ -39,7 +54,88 @@ func NewSessionPool(db *sql.DB, logger zerolog.Logger) *SessionPool {
 		streams: make(map[string]*upstream),
 		db:      db,
 		logger:  logger,
+		cfg:     &sessionConfig{scenes: &scenePatterns{}},
+		guard:   newClientGuard(),
+		dial:    net.Dial,
+		notifier: notify.NewNotifier(notify.Config{}, func(_ context.Context, _ event.Event, err error) {
+			logger.Error().Err(err).Msg("error delivering notification")
+		}),
+	}
+}
+
+// sessionConfig holds the settings that sessions read while they run. Viper
+// is not safe to read while the config file is being reloaded, so LoadConfig
+// replaces the whole of it instead.
+type sessionConfig struct {
+	scenes          *scenePatterns
+	redact          []*regexp.Regexp
+	aliasSeparator  string
+	commandPrefix   string
+	historySize     int64
+	inputFloor      bool
+	inputFloorIdle  time.Duration
+	logDir          string
+	logInput        bool
+	promptDelay     time.Duration
+	recordDir       string
+	requireUser     bool
+	spectatorNotice bool
+}
+
+// LoadConfig checks the settings and then applies them. If any of them are
+// wrong, none of them change.
+func (p *SessionPool) LoadConfig() error {
+	scenes, err := loadScenePatterns()
+	if err != nil {
+		return err
+	}
+	notifyConfig, err := loadNotifyConfig()
+	if err != nil {
+		return err
+	}
+	redact, err := compilePatterns(viper.GetStringSlice("log.input_redact"))
+	if err != nil {
+		return err
+	}
+	guardConfig, err := loadGuardConfig()
+	if err != nil {
+		return err
+	}
+	cfg := &sessionConfig{
+		scenes:          scenes,
+		redact:          redact,
+		aliasSeparator:  viper.GetString("alias.separator"),
+		commandPrefix:   viper.GetString("command.prefix"),
+		historySize:     max(viper.GetInt64("log.history_size"), 0),
+		inputFloor:      viper.GetBool("input.floor"),
+		inputFloorIdle:  viper.GetDuration("input.floor_idle"),
+		logDir:          viper.GetString("log.dir"),
+		logInput:        viper.GetBool("log.input"),
+		promptDelay:     viper.GetDuration("prompt.delay"),
+		recordDir:       viper.GetString("record.dir"),
+		requireUser:     viper.GetBool("auth.require_user"),
+		spectatorNotice: viper.GetBool("spectator.notice"),
+	}
+
+	p.notifier.Configure(notifyConfig)
+	p.guard.configure(guardConfig)
+	p.Lock()
+	old := p.cfg
+	p.cfg = cfg
+	p.Unlock()
+	if old.logDir != cfg.logDir {
+		p.ReopenHistories()
+	}
+	if old.aliasSeparator != cfg.aliasSeparator {
+		return p.ReloadAliases("")
 	}
+	return nil
+}
+
+func (p *SessionPool) config() *sessionConfig {
+	p.Lock()
+	defer p.Unlock()
+	return p.cfg
 }
 
 func (p *SessionPool) CloseAll() {
@@ -53,11 +149,14 @@ func (p *SessionPool) CloseAll() {
 func (p *SessionPool) NewDownstream(conn net.Conn) *downstream {
 	result := &downstream{
 		pool: p,
+		addr: conn.RemoteAddr().String(),
+		ip:   remoteIP(conn.RemoteAddr()),
 		telnetSession: newSession(conn, p.logger.With().
 			Str("client", conn.RemoteAddr().String()).
 			Logger()),
 	}
 	result.charset.IsServer = true
+	result.startNAWS()
 	return result
 }
 
@@ -65,6 +164,9 @@ func (p *SessionPool) ReopenHistories() {
 	p.Lock()
 	defer p.Unlock()
 	for _, session := range p.streams {
+		if session.history == nil {
+			continue
+		}
 		if err := session.history.Reopen(); err != nil {
 			session.Close()
 			p.logger.Error().Err(err).Str("session-key", session.key).Msg("error reloading history")
@@ -76,16 +178,28 @@ func (p *SessionPool) upstreamForKey(key string) *upstream {
 	p.Lock()
 	defer p.Unlock()
 	if _, found := p.streams[key]; !found {
-		p.streams[key] = &upstream{
+		s := &upstream{
 			pool:       p,
 			key:        key,
-			dispatcher: event.NewDispatcher(),
+			dispatcher: event.NewDispatcher(observeDispatch("upstream")),
 			logger:     p.logger,
 		}
+		topicNotify.Subscribe(s.dispatcher, s.logNotification)
+		topicNotify.Subscribe(s.dispatcher, s.deliverNotification)
+		p.streams[key] = s
 	}
 	return p.streams[key]
 }
 
+// upstreamWithKey returns the upstream with the given key, if it has finished
+// connecting.
+func (p *SessionPool) upstreamWithKey(key string) (*upstream, bool) {
+	p.Lock()
+	defer p.Unlock()
+	s, found := p.streams[key]
+	return s, found && s.ready.Load()
+}
+
 func (p *SessionPool) deleteUpstreamWithKey(key string) {
 	p.Lock()
 	defer p.Unlock()
@@ -97,26 +211,49 @@ type telnetSession struct {
 	logger         zerolog.Logger
 	charset        telnet.CharsetHandler
 	transmitBinary telnet.TransmitBinaryHandler
-	dispatcher     event.Dispatcher
+	dispatcher     event.AsyncDispatcher
+	state          *sessionState
+	log            *LogHandler
 }
 
+const sessionQueueSize = 16
+
 func newSession(conn net.Conn, logger zerolog.Logger) *telnetSession {
 	s := &telnetSession{
-		conn:       telnet.Wrap(context.Background(), conn),
-		logger:     logger,
-		dispatcher: event.NewDispatcher(),
-	}
-	s.conn.RegisterHandler(LogHandler{Logger: s.logger})
+		conn:   telnet.Wrap(context.Background(), conn),
+		logger: logger,
+		state:  newSessionState(),
+		log:    &LogHandler{Logger: logger},
+	}
+	s.dispatcher = event.NewAsyncDispatcher(sessionQueueSize, event.Block,
+		event.WithErrorHook(s.logEventError), observeDispatch("session"))
+	s.conn.SetErrorHandler(func(ctx context.Context, ev event.Event, err error) bool {
+		s.logEventError(ctx, ev, err)
+		return telnet.DefaultErrorHandler(ctx, ev, err)
+	})
+	s.conn.RegisterHandler(s.log)
 	s.conn.RegisterHandler(&s.transmitBinary)
 	s.conn.RegisterHandler(&s.charset)
-	s.conn.Listen(telnet.EventOption, s)
-	s.conn.Listen(telnet.EventCharsetAccepted, s)
-	s.conn.Listen(telnet.EventCharsetRejected, s)
+	telnet.TopicOption.Subscribe(s.conn, s.handleOption)
+	telnet.TopicCharsetAccepted.Subscribe(s.conn, s.handleCharsetAccepted)
+	telnet.TopicCharsetRejected.Subscribe(s.conn, s.handleCharsetRejected)
 	return s
 }
 
+func (s *telnetSession) logEventError(_ context.Context, ev event.Event, err error) {
+	log := s.logger.Error().Err(err).Str("event", string(ev.Name))
+	var perr *event.PanicError
+	if errors.As(err, &perr) {
+		log.Bytes("stack", perr.Stack)
+	}
+	log.Msg("error handling event")
+}
+
 func (s *telnetSession) Close() error {
-	return s.conn.Close()
+	err := s.conn.Close()
+	s.log.Unregister()
+	s.dispatcher.Close()
+	return err
 }
 
 func (s *telnetSession) Context() context.Context {
@@ -128,36 +265,49 @@ func (s *telnetSession) GetOption(opt byte) telnet.OptionState {
 }
 
 func (s *telnetSession) Read(p []byte) (n int, err error) {
-	return s.conn.Read(p)
+	n, err = s.conn.Read(p)
+	if n > 0 {
+		s.state.read()
+	}
+	return
 }
 
 func (s *telnetSession) Write(p []byte) (n int, err error) {
 	return s.conn.Write(p)
 }
 
-func (s *telnetSession) Listen(_ context.Context, ev event.Event) error {
-	switch ev.Name {
-	case telnet.EventOption:
-		opt := ev.Data.(telnet.OptionData)
-		switch opt.Option() {
-		case telnet.Charset:
-			if opt.ResolvedUs {
-				if opt.EnabledForUs() {
-					s.charset.RequestEncoding(unicode.UTF8)
-				} else {
-					s.dispatcher.Dispatch(context.Background(), event.Event{Name: EventCharsetResolved})
-				}
+func (s *telnetSession) handleOption(_ context.Context, opt telnet.OptionData) error {
+	s.state.setOption(opt)
+	switch opt.Option() {
+	case telnet.Charset:
+		if opt.ResolvedUs {
+			if opt.EnabledForUs() {
+				s.charset.RequestEncoding(unicode.UTF8)
+			} else {
+				s.resolveCharset()
 			}
 		}
-	case telnet.EventCharsetAccepted:
-		s.GetOption(telnet.TransmitBinary).Allow(true, true).EnableBoth(s.Context())
-		fallthrough
-	case telnet.EventCharsetRejected:
-		s.dispatcher.Dispatch(context.Background(), event.Event{Name: EventCharsetResolved})
 	}
 	return nil
 }
 
+func (s *telnetSession) handleCharsetAccepted(_ context.Context, data telnet.CharsetData) error {
+	s.state.setCharset(data.Encoding)
+	s.GetOption(telnet.TransmitBinary).Allow(true, true).EnableBoth(s.Context())
+	s.resolveCharset()
+	return nil
+}
+
+func (s *telnetSession) handleCharsetRejected(context.Context, struct{}) error {
+	s.resolveCharset()
+	return nil
+}
+
+func (s *telnetSession) resolveCharset() {
+	ctx := event.NewContext(context.Background(), s.dispatcher)
+	topicCharsetResolved.Publish(ctx, struct{}{})
+}
+
 func (s *telnetSession) negotiateOptions() {
 	opts := []byte{
 		telnet.SuppressGoAhead,
@@ -173,32 +323,53 @@ type downstream struct {
 	pool *SessionPool
 	*telnetSession
 	upstream *upstream
+	addr     string
+	ip       netip.Addr
+	// authTimer closes the client if it has not authenticated in time.
+	authTimer *time.Timer
+	input     *bufio.Reader
+	wmux      sync.Mutex
+	mux       sync.Mutex
+	role      auth.Role
+	naws      telnet.NAWSHandler
+	size      telnet.WindowSize
 
 	Name     string            `json:"name"`
 	Password string            `json:"password"`
 	Options  map[string]string `json:"options"`
+	// User is who is attaching. If it is set, Password is theirs, and
+	// UpstreamPassword is only needed to connect the upstream.
+	User             string `json:"user"`
+	UpstreamPassword string `json:"upstream_password"`
+	// Mode is "spectator" to watch without sending input.
+	Mode string `json:"mode"`
 }
 
-func (s *downstream) Listen(_ context.Context, ev event.Event) error {
-	switch ev.Name {
-	case EventCharsetResolved:
-		go s.dispatcher.RemoveListener(EventCharsetResolved, s)
-		_, err := s.upstream.history.WriteTo(s)
-		if err != nil {
-			s.logger.Error().AnErr("error", err).Msg("error writing history")
-		}
+func (s *downstream) Write(p []byte) (int, error) {
+	s.wmux.Lock()
+	defer s.wmux.Unlock()
+	return s.telnetSession.Write(p)
+}
+
+func (s *downstream) writeHistory(context.Context, struct{}) error {
+	_, err := s.upstream.history.WriteTo(s.upstream.replayWriter(s))
+	if err != nil {
+		s.logger.Error().AnErr("error", err).Msg("error writing history")
 	}
+	s.writeDigest()
 	return nil
 }
 
 func (s *downstream) connectNewUpstream() error {
-	var address, login, hash string
+	var address, login, hash, scriptType string
 	var script sql.NullString
-	row := s.pool.db.QueryRow("SELECT address, login, bcrypt, script FROM upstreams WHERE name=?", s.Name)
-	if err := row.Scan(&address, &login, &hash, &script); err != nil {
+	row := s.pool.db.QueryRow("SELECT address, login, bcrypt, script, script_type FROM upstreams WHERE name=?", s.Name)
+	if err := row.Scan(&address, &login, &hash, &script, &scriptType); err != nil {
 		return err
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(s.Password)); err != nil {
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(s.upstreamPassword())); err != nil {
+		err = fmt.Errorf("%w: upstream password: %w", errAuthFailed, err)
+		s.loginFailed(authFailureCredentials, err.Error())
 		return err
 	}
 
@@ -206,6 +377,12 @@ func (s *downstream) connectNewUpstream() error {
 	if err := s.upstream.Connect(address); err != nil {
 		return fmt.Errorf("error connecting (%v): %w", address, err)
 	}
+	s.audit(auditConnect, address)
+
+	if scriptType == scriptTypeSteps || scriptType == scriptTypeStarlark {
+		go s.runConnectScript(script.String, scriptType, login)
+		return nil
+	}
 
 	var connectScript string
 	if script.Valid {
@@ -215,7 +392,7 @@ func (s *downstream) connectNewUpstream() error {
 	}
 
 	connectScript = strings.ReplaceAll(connectScript, "%LOGIN%", login)
-	connectScript = strings.ReplaceAll(connectScript, "%PASSWORD%", s.Password)
+	connectScript = strings.ReplaceAll(connectScript, "%PASSWORD%", s.upstreamPassword())
 	if _, err := s.upstream.Write([]byte(connectScript + "\n")); err != nil {
 		return fmt.Errorf("error writing to (%v): %w", address, err)
 	}
@@ -228,33 +405,165 @@ func (s *downstream) connectUpstream() error {
 	if err := decoder.Decode(&s); err != nil {
 		return err
 	}
+	// Anything the decoder read past the handshake belongs to the upstream,
+	// except for the end of the handshake line itself.
+	rest, _ := io.ReadAll(decoder.Buffered())
+	rest = bytes.TrimPrefix(bytes.TrimLeft(rest, " \t\r"), []byte{'\n'})
+	s.input = bufio.NewReaderSize(io.MultiReader(bytes.NewReader(rest), s.telnetSession), maxInputLine)
+	if s.Mode != "" && s.Mode != modeSpectator {
+		s.notice("unknown mode: %s", s.Mode)
+		return fmt.Errorf("unknown mode: %q", s.Mode)
+	}
+	role, err := s.login()
+	if err != nil {
+		return err
+	}
+	s.audit(auditLogin, string(role))
+	s.setRole(role)
+	s.stopAuthTimer()
 	s.upstream = s.pool.upstreamForKey(s.Name)
-	s.upstream.AddDownstream(s)
 	if s.upstream.IsConnected() {
-		s.dispatcher.Listen(EventCharsetResolved, s)
+		if s.spectating() {
+			s.notice("spectating %s", s.Name)
+		}
+		s.attach()
+		s.sendWindowSize()
+		topicCharsetResolved.Once(s.dispatcher, s.writeHistory)
 	} else {
+		if s.Mode == modeSpectator {
+			s.notice("%s is not connected, so there is nothing to spectate", s.Name)
+			return fmt.Errorf("cannot spectate %s: not connected", s.Name)
+		}
+		if !role.CanConnect() {
+			s.notice("%s is not connected, and only an owner can connect it", s.Name)
+			return fmt.Errorf("user %s cannot connect %s", s.User, s.Name)
+		}
+		s.attach()
+		s.sendWindowSize()
 		for option, value := range s.Options {
 			if err := s.upstream.setOption(option, value); err != nil {
 				return err
 			}
 		}
-		return s.connectNewUpstream()
+		if err := s.connectNewUpstream(); err != nil {
+			return err
+		}
+		s.writeDigest()
 	}
 	return nil
 }
 
-const EventCharsetResolved = "charset.resolved"
+const EventCharsetResolved event.Name = "charset.resolved"
+
+var topicCharsetResolved = event.NewTopic[struct{}](EventCharsetResolved)
 
 func (s *downstream) runForever() {
 	s.logger.Debug().Msg("connected")
 	defer s.logger.Debug().Msg("disconnected")
 
+	release, err := s.pool.guard.admit(s.ip)
+	if err != nil {
+		s.logger.Info().AnErr("error", err).Msg("refused client")
+		countRefused(err)
+		return
+	}
+	defer release()
+	if d := s.pool.guard.authTimeout(); d > 0 {
+		s.authTimer = time.AfterFunc(d, func() {
+			s.notice("timed out waiting for the handshake")
+			s.Close()
+		})
+	}
+
 	s.negotiateOptions()
-	if err := s.connectUpstream(); err != nil {
+	s.GetOption(telnet.NAWS).EnableThem(s.Context())
+	defer s.detach()
+	err = s.connectUpstream()
+	s.stopAuthTimer()
+	release()
+	s.pool.guard.record(s.ip, s.Name, err)
+	if err != nil {
 		s.logger.Info().AnErr("error", err).Msg("error connecting upstream")
 		return
 	}
-	io.Copy(s.upstream, s)
+	for {
+		// Only whole lines are sent, so that a client that goes away in the
+		// middle of one cannot leave it for the next to finish.
+		line, err := s.readLine()
+		if err == nil {
+			if err := s.handleInput(line); err != nil {
+				s.logger.Info().AnErr("error", err).Msg("error writing upstream")
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+const maxInputLine = 16 * 1024
+
+// readLine reads a line of input, dropping any longer than maxInputLine.
+func (s *downstream) readLine() (string, error) {
+	for {
+		line, err := s.input.ReadSlice('\n')
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+		s.notice("your input was longer than %d bytes, so it was not sent", maxInputLine)
+		for err == bufio.ErrBufferFull {
+			_, err = s.input.ReadSlice('\n')
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+const modeSpectator = "spectator"
+
+// spectating reports whether the client only watches, because it asked to or
+// because its role is read-only.
+func (s *downstream) spectating() bool {
+	return s.Mode == modeSpectator || !s.Role().CanWrite()
+}
+
+func (s *downstream) handleInput(line string) error {
+	if s.spectating() {
+		if s.pool.config().spectatorNotice {
+			s.notice("you are spectating %s, so your input was not sent", s.Name)
+		}
+		return nil
+	}
+	if args, ok := parseCommand(s.pool.config().commandPrefix, line); ok {
+		s.runCommand(args)
+		return nil
+	}
+	if holder, ok := s.upstream.claimFloor(s, false); !ok {
+		s.notice("%s has the floor, so your input was not sent", holder.who())
+		return nil
+	}
+	commands := s.upstream.expandAlias(line)
+	for _, cmd := range commands {
+		s.matchScene(cmd)
+	}
+	return s.upstream.sendInput(s.historyName(), commands)
+}
+
+// matchScene starts or stops a scene if a command matches one of the scene
+// patterns.
+func (s *downstream) matchScene(line string) {
+	switch start, stop, name := s.pool.config().scenes.match(strings.TrimSpace(line)); {
+	case start:
+		if _, err := s.upstream.StartScene(name); err != nil {
+			s.logger.Error().AnErr("error", err).Msg("error starting scene")
+		}
+	case stop:
+		if err := s.upstream.StopScene(); err != nil && !errors.Is(err, errNoScene) {
+			s.logger.Error().AnErr("error", err).Msg("error stopping scene")
+		}
+	}
 }
 
 type upstream struct {
@@ -262,50 +571,140 @@ type upstream struct {
 	*telnetSession
 	key        string
 	mux        sync.Mutex
+	wmux       sync.Mutex
 	downstream []io.WriteCloser
 	history    History
 	dispatcher event.Dispatcher
 	logger     zerolog.Logger
+	recording  io.Closer
+	lines      *lineBuffer
+	triggers   *trigger.Set
+	rewrites   *rewrite.Set
+	aliases    *alias.Set
+	timers     *timer.Scheduler
+	gmcp       telnet.GMCPHandler
+	naws       telnet.NAWSHandler
+	size       telnet.WindowSize
+	script     *script.Runtime
+	floor      *downstream
+	floorAt    time.Time
+	remote     string
+	// closing is set once Close has started, so that timers cannot start.
+	closing bool
+	// passwordPrompt is set when the game's last prompt asked for a password.
+	passwordPrompt bool
+	// prompted is set when the game marks the end of a prompt with GA or
+	// EOR, so that it is flushed without waiting for prompt.delay.
+	prompted bool
+	// ready is set once Connect has finished, and until then the upstream is
+	// left out of the API, status and metrics.
+	ready atomic.Bool
 }
 
 const EventConnectUpstream event.Name = "upstream.connect"
 
+var topicConnectUpstream = event.NewTopic[*upstream](EventConnectUpstream)
+
 func (s *upstream) Connect(addr string) (err error) {
 	if s == nil {
 		return errors.New("you must select an upstream to connect")
 	}
-	s.history, err = newHistory(s.key)
+	s.history, err = s.pool.newHistory(s.key)
 	if err != nil {
 		return
 	}
 	s.AddDownstream(s.history)
+	s.lines = newLineBuffer(s.handleLine, s.promptDelay)
+	if err = s.loadTriggers(); err != nil {
+		return
+	}
+	if err = s.loadAliases(); err != nil {
+		return
+	}
+	if err = s.loadRewrites(); err != nil {
+		return
+	}
 
-	tcp, err := net.Dial("tcp", addr)
+	tcp, err := s.pool.dial("tcp", addr)
 	if err != nil {
 		return err
 	}
-	s.telnetSession = newSession(tcp, s.logger.With().
+	countConnect(s.key)
+	s.mux.Lock()
+	s.remote = tcp.RemoteAddr().String()
+	s.mux.Unlock()
+	s.telnetSession = newSession(newMeteredConn(tcp, s.key), s.logger.With().
 		Str("server", tcp.RemoteAddr().String()).
 		Logger())
-	s.dispatcher.Dispatch(s.Context(), event.Event{
-		Name: EventConnectUpstream,
-		Data: s,
-	})
+	telnet.TopicOption.Subscribe(s.telnetSession.conn, s.recordOption)
+	telnet.TopicGoAhead.Subscribe(s.telnetSession.conn, s.endPrompt)
+	telnet.TopicEndOfRecord.Subscribe(s.telnetSession.conn, s.endPrompt)
+	s.startScripts()
+	s.startNAWS()
+	if err := s.startRecording(); err != nil {
+		s.logger.Error().Err(err).Msg("error starting recording")
+	}
+	s.dispatcher.Dispatch(s.Context(), topicConnectUpstream.Event(s))
+	if err := s.startTimers(); err != nil {
+		s.logger.Error().Err(err).Msg("error starting timers")
+	}
+	s.ready.Store(true)
 	go s.runForever()
 	return nil
 }
 
+func (s *upstream) Write(p []byte) (int, error) {
+	s.wmux.Lock()
+	defer s.wmux.Unlock()
+	return s.telnetSession.Write(p)
+}
+
 func (s *upstream) AddDownstream(w io.WriteCloser) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 	s.downstream = append(s.downstream, w)
 }
 
+// clients returns the clients attached to the upstream, leaving out the log and
+// anything tailing it.
+func (s *upstream) clients() (result []*downstream) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, w := range s.downstream {
+		if d, ok := w.(*downstream); ok {
+			result = append(result, d)
+		}
+	}
+	return
+}
+
+func (s *upstream) RemoveDownstream(w io.WriteCloser) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.downstream = slices.DeleteFunc(s.downstream, func(wc io.WriteCloser) bool {
+		return wc == w
+	})
+	if w == io.WriteCloser(s.floor) {
+		s.floor = nil
+	}
+}
+
+// Close closes the upstream, which may never have connected, such as when the
+// upstream password was wrong.
 func (s *upstream) Close() error {
+	s.stopTimers()
+	if s.script != nil {
+		s.script.Close()
+	}
 	for _, wc := range s.downstream {
 		wc.Close()
 	}
-	s.telnetSession.Close()
+	if s.telnetSession != nil {
+		s.telnetSession.Close()
+	}
+	if s.recording != nil {
+		s.recording.Close()
+	}
 	return nil
 }
 
@@ -326,17 +725,41 @@ func (s *upstream) runForever() {
 		if err != nil {
 			break
 		}
-		buf = buf[:n]
-		s.sendDownstream(buf)
+		s.lines.Write(buf[:n])
+		if s.prompted {
+			s.prompted = false
+			s.lines.Flush()
+		}
 	}
+	s.lines.Flush()
+	forgetOptions(s.key)
 	s.logger.Debug().Msg("disconnected")
+	audit(s.pool.db, auditEvent{Kind: auditDisconnect, Upstream: s.key})
 }
 
-func (s *upstream) sendDownstream(buf []byte) {
+// endPrompt is called from Read, before what was read has reached the line
+// buffer, so runForever does the flushing.
+func (s *upstream) endPrompt(context.Context, struct{}) error {
+	s.prompted = true
+	return nil
+}
+
+// sendDownstream writes a line to the clients and the log, after rewriting it
+// for each of them.
+func (s *upstream) sendDownstream(line string) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
+	toClients, sendClients := s.rewrites.Apply(line, rewrite.Downstream)
+	toLog, sendLog := s.rewrites.Apply(line, rewrite.Log)
 	s.downstream = slices.DeleteFunc(s.downstream, func(wc io.WriteCloser) bool {
-		_, err := wc.Write(buf)
+		text, send := toClients, sendClients
+		if wc == s.history {
+			text, send = toLog, sendLog
+		}
+		if !send {
+			return false
+		}
+		_, err := io.WriteString(wc, text)
 		return err != nil
 	})
 }
@@ -351,7 +774,7 @@ func (s *upstream) setOption(optionName, optionValue string) error {
 		if err != nil {
 			return err
 		}
-		s.dispatcher.ListenFunc(EventConnectUpstream, func(context.Context, event.Event) error {
+		topicConnectUpstream.Subscribe(s.dispatcher, func(context.Context, *upstream) error {
 			s.telnetSession.charset.AllowWithoutTransmitBinary = value
 			return nil
 		})
@@ -360,7 +783,7 @@ func (s *upstream) setOption(optionName, optionValue string) error {
 		if err != nil {
 			return err
 		}
-		s.dispatcher.ListenFunc(EventConnectUpstream, func(context.Context, event.Event) error {
+		topicConnectUpstream.Subscribe(s.dispatcher, func(context.Context, *upstream) error {
 			s.telnetSession.conn.SuppressGoAhead(value)
 			return nil
 		})
@@ -372,16 +795,13 @@ func (s *upstream) setOption(optionName, optionValue string) error {
 type History interface {
 	io.WriteCloser
 	io.WriterTo
+	Name() string
+	Stat() (os.FileInfo, error)
 	Reopen() error
 }
 
-const defaultHistorySize = 20 * 1024 // about 256 lines of text
-const logTimeFormat = "2006-01-02 15:04:05 -0700 MST"
-const logSeperator = "--------------- %s - %s ---------------\n"
-const logSepOpened = "--------------- opened"
-
-func newHistory(key string) (History, error) {
-	log := &logFile{key: key, historySize: defaultHistorySize}
+func (p *SessionPool) newHistory(key string) (History, error) {
+	log := &logFile{pool: p, key: key}
 	if err := log.Open(); err != nil {
 		return nil, fmt.Errorf("error opening log for key (%v): %w", key, err)
 	}
@@ -390,30 +810,38 @@ func newHistory(key string) (History, error) {
 
 type logFile struct {
 	*os.File
-	key         string
-	historySize int64
+	pool *SessionPool
+	key  string
+}
+
+func (f *logFile) Write(p []byte) (n int, err error) {
+	if n, err = f.File.Write(p); err != nil {
+		metricHistoryErrors.WithLabelValues(f.key).Inc()
+	}
+	return
+}
+
+func (f *logFile) WriteString(s string) (n int, err error) {
+	if n, err = f.File.WriteString(s); err != nil {
+		metricHistoryErrors.WithLabelValues(f.key).Inc()
+	}
+	return
 }
 
 func (f *logFile) Open() (err error) {
-	logFileName := path.Join(
-		viper.GetString("log.dir"),
-		fmt.Sprintf("%s-%s.log", time.Now().Format("2006-01-02"), f.key),
-	)
 	f.File, err = os.OpenFile(
-		logFileName,
+		logs.FileName(f.pool.config().logDir, f.key, time.Now()),
 		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
 		0644,
 	)
 	if err == nil {
-		t := time.Now()
-		fmt.Fprintf(f, logSeperator, "opened", t.Format(logTimeFormat))
+		logs.WriteSeparator(f, logs.KindOpened, time.Now())
 	}
 	return
 }
 
 func (f *logFile) Close() (err error) {
-	t := time.Now()
-	fmt.Fprintf(f, logSeperator, "closed", t.Format(logTimeFormat))
+	logs.WriteSeparator(f, logs.KindClosed, time.Now())
 	return f.File.Close()
 }
 
@@ -441,19 +869,20 @@ func (f *logFile) WriteTo(w io.Writer) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	if end > f.historySize {
-		_, err = file.Seek(end-f.historySize, io.SeekCurrent)
+	size := f.pool.config().historySize
+	if end > size {
+		_, err = file.Seek(end-size, io.SeekCurrent)
 		if err != nil {
 			return 0, err
 		}
 	}
-	buf := make([]byte, f.historySize)
+	buf := make([]byte, size)
 	n, err := file.Read(buf)
 	if err != nil && err != io.EOF {
 		return 0, err
 	}
 	buf = buf[:n]
-	if n = bytes.LastIndex(buf, []byte(logSepOpened)); n > 0 {
+	if n = bytes.LastIndex(buf, []byte(logs.SeparatorPrefix(logs.KindOpened))); n > 0 {
 		buf = buf[n:]
 		n = bytes.IndexByte(buf, '\n')
 		buf = buf[n+1:]
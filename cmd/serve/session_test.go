@@ -0,0 +1,148 @@
+package serve
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stesla/iris/internal/auth"
+)
+
+// pipeOutput collects what is written to the other end of a net.Pipe.
+type pipeOutput struct {
+	mux sync.Mutex
+	buf bytes.Buffer
+}
+
+func readPipe(t *testing.T, conn net.Conn) *pipeOutput {
+	t.Cleanup(func() { conn.Close() })
+	out := &pipeOutput{}
+	go io.Copy(out, conn)
+	return out
+}
+
+func (o *pipeOutput) Write(p []byte) (int, error) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	return o.buf.Write(p)
+}
+
+func (o *pipeOutput) String() string {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	return o.buf.String()
+}
+
+func (o *pipeOutput) waitFor(t *testing.T, s string) {
+	t.Helper()
+	require.Eventually(t, func() bool { return strings.Contains(o.String(), s) }, time.Second, time.Millisecond,
+		"waiting for %q in %q", s, o.String())
+}
+
+// connectedGame puts a connected upstream named game in the pool, and returns
+// what is sent to it.
+func connectedGame(t *testing.T, pool *SessionPool) *pipeOutput {
+	server, game := net.Pipe()
+	s := pool.upstreamForKey("game")
+	s.history = &bufferHistory{}
+	s.telnetSession = newSession(server, zerolog.Nop())
+	t.Cleanup(func() { s.telnetSession.Close() })
+	return readPipe(t, game)
+}
+
+// attachClient logs a client in with a handshake, and returns it with what is
+// sent to it.
+func attachClient(t *testing.T, pool *SessionPool, handshake string) (*downstream, *pipeOutput, error) {
+	server, client := net.Pipe()
+	d := pool.NewDownstream(server)
+	t.Cleanup(func() { d.Close() })
+	out := readPipe(t, client)
+	go client.Write([]byte(handshake + "\n"))
+	return d, out, d.connectUpstream()
+}
+
+func spectatorPool(t *testing.T) *SessionPool {
+	resetConfig(t)
+	db := migratedDB(t)
+	addUpstream(t, db, "game", "game-secret")
+	addUpstream(t, db, "other", "other-secret")
+	addUser(t, db, "alice", "alice-secret", map[string]string{"game": "read-write", "other": "read-write"})
+	addUser(t, db, "bob", "bob-secret", map[string]string{"game": "read-only"})
+	pool := NewSessionPool(db, zerolog.Nop())
+	require.NoError(t, pool.LoadConfig())
+	return pool
+}
+
+func TestSpectatorInputIsDropped(t *testing.T) {
+	pool := spectatorPool(t)
+	viper.Set("spectator.notice", true)
+	require.NoError(t, pool.LoadConfig())
+	game := connectedGame(t, pool)
+	spectator, out, err := attachClient(t, pool, `{"name":"game","user":"alice","password":"alice-secret","mode":"spectator"}`)
+	require.NoError(t, err)
+	player, _, err := attachClient(t, pool, `{"name":"game","user":"alice","password":"alice-secret"}`)
+	require.NoError(t, err)
+
+	require.NoError(t, spectator.handleInput("say from the spectator\n"))
+	out.waitFor(t, "iris: you are spectating game, so your input was not sent")
+
+	viper.Set("spectator.notice", false)
+	require.NoError(t, pool.LoadConfig())
+	require.NoError(t, spectator.handleInput("say again\n"))
+	require.NoError(t, player.handleInput("say from the player\n"))
+	game.waitFor(t, "say from the player\r\n")
+	require.Equal(t, "say from the player\r\n", game.String())
+	require.Equal(t, 1, strings.Count(out.String(), "your input was not sent"))
+}
+
+func TestReadOnlyGrantSpectates(t *testing.T) {
+	pool := spectatorPool(t)
+	connectedGame(t, pool)
+	d, out, err := attachClient(t, pool, `{"name":"game","user":"bob","password":"bob-secret"}`)
+	require.NoError(t, err)
+	require.Equal(t, auth.RoleReadOnly, d.Role())
+	require.True(t, d.spectating())
+	out.waitFor(t, "iris: spectating game")
+	require.Contains(t, pool.upstreamForKey("game").clients(), d)
+}
+
+func TestSpectatorModeAtLogin(t *testing.T) {
+	pool := spectatorPool(t)
+	connectedGame(t, pool)
+	var tests = []struct {
+		handshake  string
+		spectating bool
+	}{
+		{`{"name":"game","password":"game-secret","mode":"spectator"}`, true},
+		{`{"name":"game","user":"alice","password":"alice-secret","mode":"spectator"}`, true},
+		{`{"name":"game","user":"alice","password":"alice-secret"}`, false},
+	}
+	for _, test := range tests {
+		d, out, err := attachClient(t, pool, test.handshake)
+		require.NoError(t, err, test.handshake)
+		require.Equal(t, test.spectating, d.spectating(), test.handshake)
+		if test.spectating {
+			out.waitFor(t, "iris: spectating game")
+		}
+	}
+
+	_, out, err := attachClient(t, pool, `{"name":"game","user":"alice","password":"alice-secret","mode":"lurker"}`)
+	require.ErrorContains(t, err, "unknown mode")
+	out.waitFor(t, "iris: unknown mode: lurker")
+}
+
+func TestSpectateUnconnectedUpstream(t *testing.T) {
+	pool := spectatorPool(t)
+	d, out, err := attachClient(t, pool, `{"name":"other","user":"alice","password":"alice-secret","mode":"spectator"}`)
+	require.ErrorContains(t, err, "cannot spectate other: not connected")
+	out.waitFor(t, "iris: other is not connected, so there is nothing to spectate")
+	require.NotContains(t, d.upstream.clients(), d)
+}
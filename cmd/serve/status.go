@@ -0,0 +1,90 @@
+package serve
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+
+	"github.com/stesla/iris/internal/telnet"
+)
+
+// sessionState tracks what a telnet session has negotiated, for Status.
+type sessionState struct {
+	mux         sync.Mutex
+	connectedAt time.Time
+	lastRead    time.Time
+	charset     string
+	options     map[byte]optionStatus
+}
+
+type optionStatus struct {
+	us, them bool
+}
+
+func newSessionState() *sessionState {
+	now := time.Now()
+	return &sessionState{
+		connectedAt: now,
+		lastRead:    now,
+		options:     make(map[byte]optionStatus),
+	}
+}
+
+func (s *sessionState) read() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.lastRead = time.Now()
+}
+
+func (s *sessionState) setOption(opt telnet.OptionData) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.options[opt.Option()] = optionStatus{us: opt.EnabledForUs(), them: opt.EnabledForThem()}
+}
+
+func (s *sessionState) setCharset(enc encoding.Encoding) {
+	name, err := ianaindex.IANA.Name(enc)
+	if err != nil {
+		return
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.charset = name
+}
+
+// sessionSnapshot is a copy of a sessionState.
+type sessionSnapshot struct {
+	connectedAt time.Time
+	idle        time.Duration
+	charset     string
+	options     []namedOption
+}
+
+type namedOption struct {
+	name string
+	optionStatus
+}
+
+func (s *sessionState) snapshot() sessionSnapshot {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	result := sessionSnapshot{
+		connectedAt: s.connectedAt,
+		idle:        time.Since(s.lastRead),
+		charset:     s.charset,
+	}
+	for opt, status := range s.options {
+		name, found := optionNames[opt]
+		if !found {
+			name = strconv.Itoa(int(opt))
+		}
+		result.options = append(result.options, namedOption{name, status})
+	}
+	slices.SortFunc(result.options, func(a, b namedOption) int { return cmp.Compare(a.name, b.name) })
+	return result
+}
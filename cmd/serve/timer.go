@@ -0,0 +1,91 @@
+package serve
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/stesla/iris/internal/timer"
+)
+
+func (p *SessionPool) loadTimers(key string) ([]timer.Timer, error) {
+	rows, err := p.db.Query("SELECT id, upstream, schedule, command, enabled, script FROM timers WHERE upstream=? ORDER BY id", key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var timers []timer.Timer
+	for rows.Next() {
+		var t timer.Timer
+		if err := rows.Scan(&t.ID, &t.Upstream, &t.Schedule, &t.Command, &t.Enabled, &t.Script); err != nil {
+			return nil, err
+		}
+		timers = append(timers, t)
+	}
+	return timers, rows.Err()
+}
+
+// ReloadTimers restarts the timers for a connected upstream after they have
+// been changed in the database.
+func (p *SessionPool) ReloadTimers(key string) error {
+	s, found := p.upstreamWithKey(key)
+	if !found {
+		return nil
+	}
+	return s.startTimers()
+}
+
+// NextTimerRun returns when a timer will next run, if its upstream is
+// connected.
+func (p *SessionPool) NextTimerRun(key string, id int64) (time.Time, bool) {
+	s, found := p.upstreamWithKey(key)
+	if !found {
+		return time.Time{}, false
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.timers.Next(id)
+}
+
+// startTimers replaces any running timers with the ones in the database. They
+// only run while the upstream is connected, and start again when it
+// reconnects.
+func (s *upstream) startTimers() error {
+	timers, err := s.pool.loadTimers(s.key)
+	if err != nil {
+		return err
+	}
+	scheduler, err := timer.NewScheduler(timers, s.runTimer)
+	if err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.closing {
+		return nil
+	}
+	s.timers.Stop()
+	s.timers = scheduler
+	s.timers.Start()
+	return nil
+}
+
+// stopTimers stops the timers for good, as the upstream is closing.
+func (s *upstream) stopTimers() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.closing = true
+	s.timers.Stop()
+	s.timers = nil
+}
+
+func (s *upstream) runTimer(t timer.Timer) {
+	s.logger.Debug().Int64("timer", t.ID).Str("command", t.Command).Msg("running timer")
+	if t.Script {
+		s.runScript(fmt.Sprintf("timer-%d.star", t.ID), t.Command, nil)
+		return
+	}
+	if _, err := io.WriteString(s, t.Command+"\n"); err != nil {
+		s.logger.Error().Err(err).Int64("timer", t.ID).Msg("error running timer")
+	}
+}
@@ -0,0 +1,100 @@
+package serve
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/stesla/iris/internal/event"
+	"github.com/stesla/iris/internal/trigger"
+)
+
+const EventNotify event.Name = "upstream.notify"
+
+// topicNotify carries the message of a notification raised on an upstream.
+var topicNotify = event.NewTopic[string](EventNotify)
+
+func (p *SessionPool) loadTriggers(key string) (*trigger.Set, error) {
+	rows, err := p.db.Query("SELECT id, pattern, action, argument FROM triggers WHERE upstream=? ORDER BY id", key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var triggers []trigger.Trigger
+	for rows.Next() {
+		var t trigger.Trigger
+		if err := rows.Scan(&t.ID, &t.Pattern, &t.Action, &t.Argument); err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return trigger.Compile(triggers)
+}
+
+// ReloadTriggers reads the triggers for a connected upstream from the
+// database again, after they have been changed.
+func (p *SessionPool) ReloadTriggers(key string) error {
+	s, found := p.upstreamWithKey(key)
+	if !found {
+		return nil
+	}
+	return s.loadTriggers()
+}
+
+func (s *upstream) loadTriggers() error {
+	triggers, err := s.pool.loadTriggers(s.key)
+	if err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.triggers = triggers
+	return nil
+}
+
+// promptDelay is how long to hold back a partial line. Only upstreams with
+// triggers or rewrite rules, which would miss a line that reached them in
+// pieces, gain anything by waiting.
+func (s *upstream) promptDelay() time.Duration {
+	s.mux.Lock()
+	whole := s.triggers.Len() > 0 || s.rewrites.Len() > 0
+	s.mux.Unlock()
+	if !whole {
+		return 0
+	}
+	return s.pool.config().promptDelay
+}
+
+// handleLine runs the triggers on a line from the game before passing it on.
+// Triggers run whether or not anyone is connected downstream.
+func (s *upstream) handleLine(line []byte) {
+	s.notePrompt(line)
+	s.mux.Lock()
+	triggers := s.triggers
+	s.mux.Unlock()
+
+	result := triggers.Apply(string(line))
+	for _, cmd := range result.Send {
+		if _, err := io.WriteString(s, cmd+"\n"); err != nil {
+			s.logger.Error().Err(err).Msg("error sending trigger command")
+		}
+	}
+	s.script.Line(string(line))
+	for _, t := range result.Scripts {
+		s.queueTriggerScript(t)
+	}
+	for _, msg := range result.Notify {
+		topicNotify.Publish(event.NewContext(context.Background(), s.dispatcher), msg)
+	}
+	if !result.Gag {
+		s.sendDownstream(result.Line)
+	}
+}
+
+func (s *upstream) logNotification(_ context.Context, msg string) error {
+	s.logger.Info().Str("session-key", s.key).Str("text", msg).Msg("notification")
+	return nil
+}
@@ -0,0 +1,126 @@
+package serve
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/stesla/iris/internal/auth"
+)
+
+var errAuthFailed = errors.New("authentication failed")
+
+// authenticate returns the role that the handshake's credentials give on the
+// upstream. The upstream's own password makes the client an owner.
+func (s *downstream) authenticate() (auth.Role, error) {
+	if s.User == "" {
+		if s.pool.config().requireUser {
+			return "", fmt.Errorf("%w: a user is required", errAuthFailed)
+		}
+		var hash string
+		row := s.pool.db.QueryRow("SELECT bcrypt FROM upstreams WHERE name=?", s.Name)
+		if err := row.Scan(&hash); err != nil {
+			return "", fmt.Errorf("%w: %w", errAuthFailed, err)
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(s.Password)); err != nil {
+			return "", fmt.Errorf("%w: %w", errAuthFailed, err)
+		}
+		return auth.RoleOwner, nil
+	}
+
+	var hash string
+	var role sql.NullString
+	row := s.pool.db.QueryRow(`SELECT u.bcrypt, g.role FROM users u
+		LEFT JOIN grants g ON g.user_id = u.id AND g.upstream = ?
+		WHERE u.name = ?`, s.Name, s.User)
+	if err := row.Scan(&hash, &role); err != nil {
+		return "", fmt.Errorf("%w: user %s: %w", errAuthFailed, s.User, err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(s.Password)); err != nil {
+		return "", fmt.Errorf("%w: user %s: %w", errAuthFailed, s.User, err)
+	}
+	if !role.Valid {
+		return "", fmt.Errorf("%w: user %s has no access to %s", errAuthFailed, s.User, s.Name)
+	}
+	return auth.ParseRole(role.String)
+}
+
+// login authenticates the client, unless its address is locked out.
+func (s *downstream) login() (auth.Role, error) {
+	if wait := s.pool.guard.locked(s.ip); wait > 0 {
+		s.notice("too many failed attempts, try again in %v", wait.Round(time.Second))
+		s.loginFailed(authFailureLockedOut, "locked out")
+		return "", fmt.Errorf("locked out for %v", wait)
+	}
+	if delay := s.pool.guard.upstreamDelay(s.Name); delay > 0 {
+		time.Sleep(delay)
+	}
+	role, err := s.authenticate()
+	if err != nil {
+		s.notice("authentication failed")
+		s.loginFailed(authFailureCredentials, err.Error())
+		return "", err
+	}
+	return role, nil
+}
+
+// The reasons clients fail to authenticate.
+const (
+	authFailureCredentials = "credentials"
+	authFailureLockedOut   = "locked-out"
+)
+
+// loginFailed records a client failing to authenticate.
+func (s *downstream) loginFailed(reason, detail string) {
+	metricAuthFailures.WithLabelValues(reason).Inc()
+	s.audit(auditLoginFailed, detail)
+}
+
+// upstreamPassword returns the password for the upstream itself, which users
+// give separately from their own.
+func (s *downstream) upstreamPassword() string {
+	if s.User == "" {
+		return s.Password
+	}
+	return s.UpstreamPassword
+}
+
+func (s *downstream) Role() auth.Role {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.role
+}
+
+func (s *downstream) setRole(role auth.Role) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.role = role
+}
+
+// updateUser applies a change to a user's role on an upstream, or on every
+// upstream if upstream is empty, to the clients they have attached. Clients
+// that lose their access are disconnected.
+func (p *SessionPool) updateUser(user, upstream string, role auth.Role) {
+	var clients []*downstream
+	p.Lock()
+	for key, s := range p.streams {
+		if upstream == "" || key == upstream {
+			clients = append(clients, s.clients()...)
+		}
+	}
+	p.Unlock()
+	for _, d := range clients {
+		switch {
+		case d.User != user:
+		case role == "":
+			d.notice("your access to %s has been revoked", d.Name)
+			d.Close()
+		default:
+			d.setRole(role)
+			d.notice("your role on %s is now %s", d.Name, role)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package serve
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/internal/auth"
+)
+
+// migratedDB opens an in-memory database with the Up half of every migration
+// applied.
+func migratedDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	files, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		require.NoError(t, err)
+		up, _, _ := strings.Cut(string(src), "-- +goose Down")
+		_, err = db.Exec(strings.TrimPrefix(up, "-- +goose Up"))
+		require.NoError(t, err, file)
+	}
+	return db
+}
+
+func addUpstream(t *testing.T, db *sql.DB, name, password string) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO upstreams (name, address, login, bcrypt) VALUES (?, ?, ?, ?)",
+		name, "localhost:4201", name, hash)
+	require.NoError(t, err)
+}
+
+func addUser(t *testing.T, db *sql.DB, name, password string, grants map[string]string) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	result, err := db.Exec("INSERT INTO users (name, bcrypt, created_at) VALUES (?, ?, ?)", name, hash, time.Now())
+	require.NoError(t, err)
+	id, err := result.LastInsertId()
+	require.NoError(t, err)
+	for upstream, role := range grants {
+		_, err = db.Exec("INSERT INTO grants (user_id, upstream, role, granted_at) VALUES (?, ?, ?, ?)",
+			id, upstream, role, time.Now())
+		require.NoError(t, err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	db := migratedDB(t)
+	addUpstream(t, db, "game", "game-secret")
+	addUpstream(t, db, "other", "other-secret")
+	addUser(t, db, "alice", "alice-secret", map[string]string{"game": "read-write"})
+	addUser(t, db, "bob", "bob-secret", map[string]string{"game": "read-only", "other": "owner"})
+	addUser(t, db, "carol", "carol-secret", map[string]string{"other": "owner"})
+	addUser(t, db, "dave", "dave-secret", map[string]string{"game": "admin"})
+
+	var tests = []struct {
+		name        string
+		requireUser bool
+		user        string
+		password    string
+		role        auth.Role
+		err         string
+	}{
+		{"upstream password", false, "", "game-secret", auth.RoleOwner, ""},
+		{"wrong upstream password", false, "", "guess", "", "authentication failed"},
+		{"upstream password with a user required", true, "", "game-secret", "", "a user is required"},
+		{"read-write", false, "alice", "alice-secret", auth.RoleReadWrite, ""},
+		{"grant on this upstream", false, "bob", "bob-secret", auth.RoleReadOnly, ""},
+		{"grant on another upstream", false, "carol", "carol-secret", "", "carol has no access to game"},
+		{"wrong user password", false, "alice", "game-secret", "", "authentication failed: user alice"},
+		{"unknown user", false, "eve", "eve-secret", "", "authentication failed: user eve"},
+		{"unknown role", false, "dave", "dave-secret", "", "unknown role"},
+		{"user with a user required", true, "alice", "alice-secret", auth.RoleReadWrite, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetConfig(t)
+			viper.Set("auth.require_user", test.requireUser)
+			pool := NewSessionPool(db, zerolog.Nop())
+			require.NoError(t, pool.LoadConfig())
+			d := &downstream{pool: pool, Name: "game", User: test.user, Password: test.password}
+			role, err := d.authenticate()
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.role, role)
+		})
+	}
+}
+
+func TestAuthenticateAfterRevoke(t *testing.T) {
+	resetConfig(t)
+	db := migratedDB(t)
+	addUpstream(t, db, "game", "game-secret")
+	addUser(t, db, "alice", "alice-secret", map[string]string{"game": "read-write"})
+	pool := NewSessionPool(db, zerolog.Nop())
+	require.NoError(t, pool.LoadConfig())
+	d := &downstream{pool: pool, Name: "game", User: "alice", Password: "alice-secret"}
+
+	_, err := d.authenticate()
+	require.NoError(t, err)
+	users := &usersServer{db: db, sessions: pool}
+	_, err = users.Revoke(context.Background(), &api.RevokeRequest{User: proto.String("alice"), Upstream: proto.String("game")})
+	require.NoError(t, err)
+	_, err = d.authenticate()
+	require.ErrorIs(t, err, errAuthFailed)
+	require.ErrorContains(t, err, "alice has no access to game")
+}
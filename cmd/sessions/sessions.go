@@ -0,0 +1,107 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+)
+
+var (
+	timeout time.Duration
+	send    string
+	group   int
+)
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(&cobra.Command{
+		Use:   "send UPSTREAM TEXT...",
+		Short: "send a command to a connected upstream",
+		Long: `Send sends text to a connected upstream, as if a client had typed it, so
+that scripts and cron jobs can use a session without attaching to it. Aliases
+are expanded. If TEXT is "-", it is read from standard input, and each line
+of it is sent in turn.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: Send,
+	})
+
+	expectCmd := &cobra.Command{
+		Use:   "expect UPSTREAM PATTERN",
+		Short: "wait for a line from a connected upstream",
+		Long: `Expect waits for a line from a connected upstream that matches PATTERN, a
+regular expression, and prints it. Lines are matched without ANSI escape
+codes. It fails if nothing matches before the timeout.
+
+Only lines that arrive after expect starts are seen, so to wait for the reply
+to a command, give the command with --send rather than running send first:
+
+  iris expect mygame 'You have (\d+) new' --send '+mail' --group 1`,
+		Args: cobra.ExactArgs(2),
+		RunE: Expect,
+	}
+	expectCmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "how long to wait")
+	expectCmd.Flags().StringVar(&send, "send", "", "a command to send once waiting")
+	expectCmd.Flags().IntVar(&group, "group", 0, "print only this group of the match, instead of the line")
+	cmd.AddCommand(expectCmd)
+}
+
+func Send(cmd *cobra.Command, args []string) error {
+	text := strings.Join(args[1:], " ")
+	if text == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		text = string(data)
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewSessionsClient(conn).Send(ctx, &api.SendRequest{Upstream: &args[0], Text: &text})
+	return err
+}
+
+func Expect(cmd *cobra.Command, args []string) error {
+	req := &api.ExpectRequest{
+		Upstream: &args[0],
+		Pattern:  &args[1],
+		Timeout:  durationpb.New(timeout),
+	}
+	if cmd.Flags().Changed("send") {
+		req.Send = &send
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout+time.Second)
+	defer cancel()
+	resp, err := api.NewSessionsClient(conn).Expect(ctx, req)
+	if err != nil {
+		return err
+	}
+	switch {
+	case group == 0:
+		fmt.Println(resp.GetLine())
+	case group <= len(resp.Groups):
+		fmt.Println(resp.Groups[group-1])
+	default:
+		return fmt.Errorf("the pattern has no group %d", group)
+	}
+	return nil
+}
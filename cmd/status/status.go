@@ -0,0 +1,113 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+)
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status [UPSTREAM]",
+		Short: "show the connected upstreams and their clients",
+		Long: `Status shows each connected upstream, or just the one given, with the
+telnet options and charset negotiated with the game and with each client.
+Options are listed with the sides they are enabled for: "us" is iris, and
+"them" is the game or the client.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: Status,
+	})
+}
+
+func Status(cmd *cobra.Command, args []string) error {
+	req := &api.StatusRequest{}
+	if len(args) > 0 {
+		req.Upstream = &args[0]
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.NewStatusClient(conn).Status(ctx, req)
+	if err != nil {
+		return err
+	}
+	if len(resp.Upstreams) == 0 {
+		fmt.Println("no upstreams are connected")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for i, u := range resp.Upstreams {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", u.GetKey(), u.GetAddress())
+		fmt.Fprintf(w, "  connected\t%s\n", since(u.ConnectedAt.AsTime()))
+		fmt.Fprintf(w, "  idle\t%s\n", u.Idle.AsDuration().Round(time.Second))
+		fmt.Fprintf(w, "  charset\t%s\n", charset(u.GetCharset()))
+		fmt.Fprintf(w, "  options\t%s\n", options(u.Options))
+		fmt.Fprintf(w, "  history\t%s (%d bytes)\n", u.GetHistoryPath(), u.GetHistorySize())
+		fmt.Fprintf(w, "  clients\t%d\n", len(u.Clients))
+		for _, c := range u.Clients {
+			who := c.GetAddress()
+			if c.GetUser() != "" {
+				who = c.GetUser() + "@" + who
+			}
+			role := c.GetRole()
+			if c.GetSpectating() {
+				role += ", spectating"
+			}
+			fmt.Fprintf(w, "    %s\t%s\n", who, role)
+			fmt.Fprintf(w, "      connected\t%s\n", since(c.ConnectedAt.AsTime()))
+			fmt.Fprintf(w, "      idle\t%s\n", c.Idle.AsDuration().Round(time.Second))
+			fmt.Fprintf(w, "      charset\t%s\n", charset(c.GetCharset()))
+			fmt.Fprintf(w, "      options\t%s\n", options(c.Options))
+		}
+	}
+	return w.Flush()
+}
+
+func since(t time.Time) string {
+	return fmt.Sprintf("%s (%s ago)", t.Local().Format(time.DateTime), time.Since(t).Round(time.Second))
+}
+
+func charset(name string) string {
+	if name == "" {
+		return "none negotiated"
+	}
+	return name
+}
+
+func options(opts []*api.OptionStatus) string {
+	var result []string
+	for _, opt := range opts {
+		var sides []string
+		if opt.GetUs() {
+			sides = append(sides, "us")
+		}
+		if opt.GetThem() {
+			sides = append(sides, "them")
+		}
+		if len(sides) == 0 {
+			sides = append(sides, "off")
+		}
+		result = append(result, fmt.Sprintf("%s (%s)", opt.GetName(), strings.Join(sides, ", ")))
+	}
+	if len(result) == 0 {
+		return "none negotiated"
+	}
+	return strings.Join(result, ", ")
+}
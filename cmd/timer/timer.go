@@ -0,0 +1,167 @@
+package timer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+)
+
+var (
+	pkgcmd = &cobra.Command{
+		Use:   "timer [OPTIONS] COMMAND",
+		Short: "commands for managing timers",
+		Long: `Timers send a command to an upstream on a schedule while it is connected.
+The schedule is a five-field cron expression such as "0 9 * * *", a
+descriptor such as "@daily", or an interval such as "@every 5m". With
+--script, the command is a Starlark script to run instead.`,
+	}
+	disabled bool
+	isScript bool
+)
+
+func init() {
+	addCmd := &cobra.Command{
+		Use:   "add UPSTREAM SCHEDULE COMMAND",
+		Short: "add a timer to an upstream",
+		Args:  cobra.ExactArgs(3),
+		RunE:  Add,
+	}
+	addCmd.Flags().BoolVar(&disabled, "disabled", false, "add the timer without enabling it")
+	addCmd.Flags().BoolVar(&isScript, "script", false, "run the command as a script")
+	pkgcmd.AddCommand(addCmd)
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "list [UPSTREAM]",
+		Short: "list timers",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  List,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "remove ID",
+		Short: "remove a timer",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Remove,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "enable ID",
+		Short: "enable a timer",
+		Args:  cobra.ExactArgs(1),
+		RunE:  func(cmd *cobra.Command, args []string) error { return setEnabled(args[0], true) },
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "disable ID",
+		Short: "disable a timer",
+		Args:  cobra.ExactArgs(1),
+		RunE:  func(cmd *cobra.Command, args []string) error { return setEnabled(args[0], false) },
+	})
+}
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(pkgcmd)
+}
+
+func Add(cmd *cobra.Command, args []string) error {
+	enabled := !disabled
+	req := &api.Timer{
+		Upstream: &args[0],
+		Schedule: &args[1],
+		Command:  &args[2],
+		Enabled:  &enabled,
+		Script:   &isScript,
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	t, err := api.NewTimersClient(conn).AddTimer(ctx, req)
+	if err != nil {
+		return err
+	}
+	fmt.Println(t.GetId())
+	return nil
+}
+
+func List(cmd *cobra.Command, args []string) error {
+	req := &api.ListTimersRequest{}
+	if len(args) > 0 {
+		req.Upstream = &args[0]
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.NewTimersClient(conn).ListTimers(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, t := range resp.Timers {
+		state := "disabled"
+		if t.GetEnabled() {
+			state = "enabled"
+		}
+		next := "-"
+		if t.NextRun != nil {
+			next = t.NextRun.AsTime().Local().Format(time.DateTime)
+		}
+		fmt.Printf("%d\t%s\t%s\t%s\t%s\t%s\n", t.GetId(), t.GetUpstream(), t.GetSchedule(), state, next, t.GetCommand())
+	}
+	return nil
+}
+
+func Remove(cmd *cobra.Command, args []string) error {
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewTimersClient(conn).RemoveTimer(ctx, &api.RemoveTimerRequest{Id: &id})
+	return err
+}
+
+func setEnabled(arg string, enabled bool) error {
+	id, err := parseID(arg)
+	if err != nil {
+		return err
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewTimersClient(conn).SetTimerEnabled(ctx, &api.SetTimerEnabledRequest{
+		Id:      &id,
+		Enabled: &enabled,
+	})
+	return err
+}
+
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timer id: %s", s)
+	}
+	return id, nil
+}
@@ -0,0 +1,102 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+)
+
+var pkgcmd = &cobra.Command{
+	Use:   "token COMMAND",
+	Short: "commands for managing API tokens",
+	Long: `The iris commands talk to the server over a local socket, grpc.server.socket,
+which only the user running the server and root can open. To manage iris from
+elsewhere, set grpc.server.addr along with grpc.server.tls.cert and
+grpc.server.tls.key, and give each client a token:
+
+  iris token create laptop
+
+On the client, set grpc.client.addr to the server's address and
+grpc.client.token (or IRIS_GRPC_CLIENT_TOKEN) to the token. Clients may
+instead present a certificate signed by grpc.server.tls.client_ca.`,
+}
+
+func init() {
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "create NAME",
+		Short: "create a token and print it",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Create,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "revoke NAME",
+		Short: "revoke a token",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Revoke,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "list tokens",
+		Args:  cobra.NoArgs,
+		RunE:  List,
+	})
+}
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(pkgcmd)
+}
+
+func Create(cmd *cobra.Command, args []string) error {
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.NewTokensClient(conn).CreateToken(ctx, &api.CreateTokenRequest{Name: &args[0]})
+	if err != nil {
+		return err
+	}
+	fmt.Println(resp.GetToken())
+	return nil
+}
+
+func Revoke(cmd *cobra.Command, args []string) error {
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewTokensClient(conn).RevokeToken(ctx, &api.RevokeTokenRequest{Name: &args[0]})
+	return err
+}
+
+func List(cmd *cobra.Command, args []string) error {
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.NewTokensClient(conn).ListTokens(ctx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+	for _, t := range resp.Tokens {
+		fmt.Printf("%s\t%s\n", t.GetName(), t.CreatedAt.AsTime().Local().Format(time.DateTime))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+)
+
+var pkgcmd = &cobra.Command{
+	Use:   "trigger [OPTIONS] COMMAND",
+	Short: "commands for managing triggers",
+	Long: `Triggers match each line from an upstream against a regular expression
+and fire an action, whether or not a client is connected. The actions are:
+
+  send ARGUMENT       send ARGUMENT to the upstream as a command
+  gag                 hide the line from clients and logs
+  highlight [STYLE]   highlight the line, e.g. "bold", "red", "white on blue"
+  notify [MESSAGE]    raise a notification with MESSAGE, or the line
+  script SOURCE       run SOURCE as a Starlark script
+
+Arguments can refer to capture groups as $1 or ${name}. Scripts are given
+the whole match and the groups in the tuple match. They cannot call on or
+on_gmcp, which only a connect script can.`,
+}
+
+func init() {
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "add UPSTREAM PATTERN ACTION [ARGUMENT]",
+		Short: "add a trigger to an upstream",
+		Args:  cobra.RangeArgs(3, 4),
+		RunE:  Add,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "list [UPSTREAM]",
+		Short: "list triggers",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  List,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "remove ID",
+		Short: "remove a trigger",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Remove,
+	})
+}
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(pkgcmd)
+}
+
+func Add(cmd *cobra.Command, args []string) error {
+	req := &api.Trigger{
+		Upstream: &args[0],
+		Pattern:  &args[1],
+		Action:   &args[2],
+	}
+	if len(args) > 3 {
+		req.Argument = &args[3]
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	t, err := api.NewTriggersClient(conn).AddTrigger(ctx, req)
+	if err != nil {
+		return err
+	}
+	fmt.Println(t.GetId())
+	return nil
+}
+
+func List(cmd *cobra.Command, args []string) error {
+	req := &api.ListTriggersRequest{}
+	if len(args) > 0 {
+		req.Upstream = &args[0]
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.NewTriggersClient(conn).ListTriggers(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, t := range resp.Triggers {
+		fmt.Printf("%d\t%s\t%q\t%s\t%s\n", t.GetId(), t.GetUpstream(), t.GetPattern(), t.GetAction(), t.GetArgument())
+	}
+	return nil
+}
+
+func Remove(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid trigger id: %s", args[0])
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewTriggersClient(conn).RemoveTrigger(ctx, &api.RemoveTriggerRequest{Id: &id})
+	return err
+}
@@ -3,28 +3,38 @@ package upstream
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
 )
 
 var (
 	pkgcmd = &cobra.Command{
 		Use:   "upstream [OPTIONS] COMMAND",
 		Short: "commands for managing upstreams",
+		Long: `Upstreams are the games that iris connects to. Once connected, iris runs
+the upstream's connect script, which has one of these types:
+
+  text       a single command, with %LOGIN% and %PASSWORD% replaced
+  steps      one step per line, for logins behind a menu or splash screen:
+               wait "PATTERN" [TIMEOUT]   wait for a matching line
+               send "TEXT"                send TEXT, with %LOGIN% and
+                                          %PASSWORD% replaced
+               sleep DURATION             pause, e.g. 2 or 500ms
+  starlark   a Starlark script, with login and password variables`,
 	}
 	address     string
 	login       string
 	newName     string
 	newPassword string
 	script      string
+	scriptFile  string
+	scriptType  string
 )
 
 func init() {
@@ -33,6 +43,8 @@ func init() {
 	pkgcmd.PersistentFlags().StringVarP(&newName, "name", "n", "", "name for upstream session")
 	pkgcmd.PersistentFlags().StringVarP(&newPassword, "password", "p", "", "password for upstream session")
 	pkgcmd.PersistentFlags().StringVarP(&script, "script", "s", "", "connect script")
+	pkgcmd.PersistentFlags().StringVarP(&scriptFile, "script-file", "f", "", "read the connect script from a file")
+	pkgcmd.PersistentFlags().StringVarP(&scriptType, "script-type", "t", "", "connect script type (text, steps or starlark)")
 	pkgcmd.AddCommand(&cobra.Command{
 		Use:   "add",
 		Short: "add a new upstream",
@@ -66,9 +78,13 @@ func Add(cmd *cobra.Command, args []string) error {
 		},
 		Password: &args[1],
 	}
+	cobra.CheckErr(readScriptFile())
 	if script != "" {
 		req.Script = &script
 	}
+	if scriptType != "" {
+		req.ScriptType = &scriptType
+	}
 	conn, err := grpcNew()
 	cobra.CheckErr(err)
 
@@ -95,9 +111,13 @@ func Edit(cmd *cobra.Command, args []string) error {
 	if newPassword != "" {
 		req.NewPassword = &newPassword
 	}
+	cobra.CheckErr(readScriptFile())
 	if script != "" {
 		req.Script = &script
 	}
+	if scriptType != "" {
+		req.ScriptType = &scriptType
+	}
 
 	conn, err := grpcNew()
 	cobra.CheckErr(err)
@@ -121,10 +141,16 @@ func List(cmd *cobra.Command, args []string) {
 	}
 }
 
+func readScriptFile() error {
+	if scriptFile == "" {
+		return nil
+	}
+	b, err := os.ReadFile(scriptFile)
+	script = string(b)
+	return err
+}
+
 func grpcNew() (api.UpstreamsClient, error) {
-	conn, err := grpc.NewClient(
-		viper.GetString("grpc.client.addr"),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	conn, err := client.Dial()
 	return api.NewUpstreamsClient(conn), err
 }
@@ -0,0 +1,152 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stesla/iris/api"
+	"github.com/stesla/iris/cmd/client"
+)
+
+var pkgcmd = &cobra.Command{
+	Use:   "user COMMAND",
+	Short: "commands for managing users",
+	Long: `Users attach to upstreams with their own name and password, in the
+"user" and "password" fields of the handshake, and can only attach to the
+upstreams they have been granted. The roles are:
+
+  owner        connect the upstream, giving its password as
+               "upstream_password", and send it input
+  read-write   attach to the upstream while it is connected and send it input
+  read-only    attach to the upstream while it is connected and watch it
+
+A client can attach as a spectator, which watches without sending input, by
+setting "mode" to "spectator" in the handshake. Read-only users always
+spectate.
+
+Revoking a grant, or removing a user, disconnects their clients. Unless
+auth.require_user is set, anyone with the upstream's own password can still
+attach as an owner without a user.`,
+}
+
+func init() {
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "add NAME PASSWORD",
+		Short: "add a user",
+		Args:  cobra.ExactArgs(2),
+		RunE:  Add,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:     "rm NAME",
+		Aliases: []string{"remove"},
+		Short:   "remove a user and their grants",
+		Args:    cobra.ExactArgs(1),
+		RunE:    Remove,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "grant NAME UPSTREAM ROLE",
+		Short: "give a user a role on an upstream",
+		Args:  cobra.ExactArgs(3),
+		RunE:  Grant,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "revoke NAME UPSTREAM",
+		Short: "take away a user's role on an upstream",
+		Args:  cobra.ExactArgs(2),
+		RunE:  Revoke,
+	})
+	pkgcmd.AddCommand(&cobra.Command{
+		Use:   "list [NAME]",
+		Short: "list users and their grants",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  List,
+	})
+}
+
+func AddToCommand(cmd *cobra.Command) {
+	cmd.AddCommand(pkgcmd)
+}
+
+func Add(cmd *cobra.Command, args []string) error {
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewUsersClient(conn).AddUser(ctx, &api.AddUserRequest{Name: &args[0], Password: &args[1]})
+	return err
+}
+
+func Remove(cmd *cobra.Command, args []string) error {
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewUsersClient(conn).RemoveUser(ctx, &api.RemoveUserRequest{Name: &args[0]})
+	return err
+}
+
+func Grant(cmd *cobra.Command, args []string) error {
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewUsersClient(conn).Grant(ctx, &api.GrantRequest{User: &args[0], Upstream: &args[1], Role: &args[2]})
+	return err
+}
+
+func Revoke(cmd *cobra.Command, args []string) error {
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.NewUsersClient(conn).Revoke(ctx, &api.RevokeRequest{User: &args[0], Upstream: &args[1]})
+	return err
+}
+
+func List(cmd *cobra.Command, args []string) error {
+	req := &api.ListUsersRequest{}
+	if len(args) > 0 {
+		req.Name = &args[0]
+	}
+	conn, err := client.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.NewUsersClient(conn).ListUsers(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, u := range resp.Users {
+		if len(u.Grants) == 0 {
+			fmt.Printf("%s\t-\t-\t-\n", u.GetName())
+		}
+		for _, g := range u.Grants {
+			granted := g.GrantedAt.AsTime().Local().Format(time.DateTime)
+			fmt.Printf("%s\t%s\t%s\t%s\n", u.GetName(), g.GetUpstream(), g.GetRole(), granted)
+		}
+	}
+	return nil
+}
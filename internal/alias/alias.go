@@ -0,0 +1,103 @@
+// Package alias expands the commands typed downstream before they are sent
+// upstream.
+package alias
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Alias replaces a command named Name with Expansion, which is split on the
+// separator into several commands. In each of them $1 through $9 are replaced
+// with the arguments to the alias, $* with all of them, and $$ with $.
+type Alias struct {
+	ID        int64
+	Upstream  string
+	Name      string
+	Expansion string
+}
+
+func Validate(a Alias) error {
+	switch {
+	case a.Name == "":
+		return errors.New("alias name is empty")
+	case strings.IndexFunc(a.Name, unicode.IsSpace) >= 0:
+		return errors.New("alias name contains spaces")
+	case strings.TrimSpace(a.Expansion) == "":
+		return errors.New("alias expansion is empty")
+	}
+	return nil
+}
+
+type Set struct {
+	aliases   map[string]Alias
+	separator string
+}
+
+// NewSet returns a Set of aliases, in which later aliases replace earlier
+// ones with the same name.
+func NewSet(aliases []Alias, separator string) *Set {
+	s := &Set{aliases: make(map[string]Alias, len(aliases)), separator: separator}
+	for _, a := range aliases {
+		s.aliases[a.Name] = a
+	}
+	return s
+}
+
+// Expand returns the commands line expands to, each ending in a newline. If
+// the first word of line is not an alias, it returns false. Expansions are
+// not expanded again.
+func (s *Set) Expand(line string) ([]string, bool) {
+	if s == nil {
+		return nil, false
+	}
+	text := strings.TrimLeftFunc(strings.TrimRight(line, "\r\n"), unicode.IsSpace)
+	name, rest := text, ""
+	if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+		name, rest = text[:i], text[i:]
+	}
+	a, found := s.aliases[name]
+	if !found {
+		return nil, false
+	}
+	rest = strings.TrimSpace(rest)
+	args := strings.Fields(rest)
+	parts := []string{a.Expansion}
+	if s.separator != "" {
+		parts = strings.Split(a.Expansion, s.separator)
+	}
+	var commands []string
+	for _, part := range parts {
+		if cmd := strings.TrimSpace(substitute(part, rest, args)); cmd != "" {
+			commands = append(commands, cmd+"\n")
+		}
+	}
+	return commands, true
+}
+
+func substitute(template, rest string, args []string) string {
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '$' || i+1 == len(template) {
+			b.WriteByte(c)
+			continue
+		}
+		switch next := template[i+1]; {
+		case next == '*':
+			b.WriteString(rest)
+		case next == '$':
+			b.WriteByte('$')
+		case next >= '1' && next <= '9':
+			if n := int(next - '1'); n < len(args) {
+				b.WriteString(args[n])
+			}
+		default:
+			b.WriteByte(c)
+			continue
+		}
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package alias
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	set := NewSet([]Alias{
+		{Name: "hi", Expansion: "say Hello, $1!"},
+		{Name: "greet", Expansion: "pose waves to $1.; say $*"},
+		{Name: "cost", Expansion: "say That'll be $$$1."},
+		{Name: "hi", Upstream: "game", Expansion: "say Hi, $1."},
+		{Name: "empty", Expansion: "look ;; $2"},
+	}, ";")
+
+	var tests = []struct {
+		line     string
+		expected []string
+		found    bool
+	}{
+		{"look\n", nil, false},
+		{"hi\r\n", []string{"say Hi, .\n"}, true},
+		{"hi Bob\n", []string{"say Hi, Bob.\n"}, true},
+		{"greet Bob and Alice\n", []string{"pose waves to Bob.\n", "say Bob and Alice\n"}, true},
+		{"  greet  Bob\n", []string{"pose waves to Bob.\n", "say Bob\n"}, true},
+		{"greet\tBob\n", []string{"pose waves to Bob.\n", "say Bob\n"}, true},
+		{"cost 5\n", []string{"say That'll be $5.\n"}, true},
+		{"empty\n", []string{"look\n"}, true},
+		{"hiya\n", nil, false},
+	}
+	for _, test := range tests {
+		commands, found := set.Expand(test.line)
+		require.Equal(t, test.found, found, test.line)
+		require.Equal(t, test.expected, commands, test.line)
+	}
+}
+
+func TestExpandWithoutSeparator(t *testing.T) {
+	set := NewSet([]Alias{{Name: "p", Expansion: "page $1=$*; ok"}}, "")
+	commands, found := set.Expand("p Bob hi\n")
+	require.True(t, found)
+	require.Equal(t, []string{"page Bob=Bob hi; ok\n"}, commands)
+}
+
+func TestNilSet(t *testing.T) {
+	var set *Set
+	_, found := set.Expand("hi\n")
+	require.False(t, found)
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(Alias{Name: "hi", Expansion: "say hi"}))
+	require.Error(t, Validate(Alias{Name: "", Expansion: "say hi"}))
+	require.Error(t, Validate(Alias{Name: "h i", Expansion: "say hi"}))
+	require.Error(t, Validate(Alias{Name: "hi", Expansion: " "}))
+}
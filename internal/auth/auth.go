@@ -0,0 +1,34 @@
+// Package auth decides what users may do with the upstreams they have been
+// granted.
+package auth
+
+import "fmt"
+
+type Role string
+
+const (
+	// RoleOwner may do everything, including connecting the upstream.
+	RoleOwner Role = "owner"
+	// RoleReadWrite may attach to a connected upstream and send it input.
+	RoleReadWrite Role = "read-write"
+	// RoleReadOnly may attach to a connected upstream and watch it.
+	RoleReadOnly Role = "read-only"
+)
+
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleOwner, RoleReadWrite, RoleReadOnly:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role: %q", s)
+}
+
+// CanConnect reports whether r may connect an upstream that is not connected.
+func (r Role) CanConnect() bool {
+	return r == RoleOwner
+}
+
+// CanWrite reports whether r may send input to the upstream.
+func (r Role) CanWrite() bool {
+	return r == RoleOwner || r == RoleReadWrite
+}
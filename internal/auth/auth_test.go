@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRole(t *testing.T) {
+	for _, r := range []Role{RoleOwner, RoleReadWrite, RoleReadOnly} {
+		parsed, err := ParseRole(string(r))
+		require.NoError(t, err)
+		require.Equal(t, r, parsed)
+	}
+	_, err := ParseRole("admin")
+	require.Error(t, err)
+}
+
+func TestPermissions(t *testing.T) {
+	var tests = []struct {
+		role    Role
+		connect bool
+		write   bool
+	}{
+		{RoleOwner, true, true},
+		{RoleReadWrite, false, true},
+		{RoleReadOnly, false, false},
+		{"", false, false},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.connect, test.role.CanConnect(), test.role)
+		require.Equal(t, test.write, test.role.CanWrite(), test.role)
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// ListenUnix listens on a unix socket at path, and closes connections from
+// any user other than this one or root, reporting them to onReject.
+func ListenUnix(path string, onReject func(error)) (net.Listener, error) {
+	// Remove a stale socket, but nothing else.
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return &peerListener{Listener: l, uid: os.Getuid(), onReject: onReject}, nil
+}
+
+type peerListener struct {
+	net.Listener
+	uid      int
+	onReject func(error)
+}
+
+func (l *peerListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		uid, err := PeerUID(conn)
+		if err == nil && uid != l.uid && uid != 0 {
+			err = fmt.Errorf("peer uid %d is not allowed", uid)
+		}
+		if err == nil {
+			return conn, nil
+		}
+		conn.Close()
+		if l.onReject != nil {
+			l.onReject(err)
+		}
+	}
+}
+
+// PeerUID returns the user of the process on the other end of a unix socket.
+func PeerUID(conn net.Conn) (int, error) {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return 0, fmt.Errorf("not a socket: %T", conn)
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var uid int
+	var uerr error
+	if err := raw.Control(func(fd uintptr) { uid, uerr = peerUID(fd) }); err != nil {
+		return 0, err
+	}
+	return uid, uerr
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package auth
+
+import "errors"
+
+func peerUID(fd uintptr) (int, error) {
+	return 0, errors.New("peer credentials are not supported on this platform")
+}
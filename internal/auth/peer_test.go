@@ -0,0 +1,50 @@
+//go:build linux || darwin
+
+package auth
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "iris.sock")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	_, err := ListenUnix(path, nil)
+	require.Error(t, err)
+	require.NoError(t, os.Remove(path))
+
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err := ListenUnix(path, func(err error) { t.Error(err) })
+	require.NoError(t, err)
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	server := <-accepted
+	defer server.Close()
+	uid, err := PeerUID(server)
+	require.NoError(t, err)
+	require.Equal(t, os.Getuid(), uid)
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	tokenPrefix = "iris_"
+	tokenBytes  = 32
+)
+
+// NewToken returns a random API token, and the hash that is stored in its
+// place.
+func NewToken() (token, hash string, err error) {
+	b := make([]byte, tokenBytes)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+	token = tokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hash under which token is stored. Tokens are random, so
+// they do not need a slow hash like passwords do.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewToken(t *testing.T) {
+	token, hash, err := NewToken()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, tokenPrefix))
+	require.Equal(t, HashToken(token), hash)
+	require.NotContains(t, hash, token)
+
+	other, _, err := NewToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, other)
+	require.NotEqual(t, hash, HashToken(other))
+}
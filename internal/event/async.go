@@ -0,0 +1,259 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what an AsyncDispatcher does when a listener's queue
+// is full.
+type OverflowPolicy int
+
+const (
+	// Block waits until the listener has room in its queue.
+	Block OverflowPolicy = iota
+	// DropOldest discards the oldest queued event to make room.
+	DropOldest
+	// Error discards the new event and returns ErrQueueFull from Dispatch.
+	Error
+)
+
+var (
+	ErrClosed    = errors.New("dispatcher closed")
+	ErrQueueFull = errors.New("event queue full")
+)
+
+type AsyncDispatcher interface {
+	Dispatcher
+	// Close stops accepting events and waits for every listener to finish
+	// the events already in its queue. Since it waits for them, it must not
+	// be called from one of the dispatcher's own listeners.
+	Close() error
+}
+
+// NewAsyncDispatcher returns a dispatcher that gives each listener its own
+// queue of the given size and goroutine, so a slow listener never holds up
+// the code calling Dispatch.
+//
+// Listeners always run as if JoinErrors were set, because a panic on a queue
+// goroutine would otherwise take down the whole process. Their errors are
+// reported to the ErrorHook, if there is one.
+func NewAsyncDispatcher(size int, policy OverflowPolicy, opts ...Option) AsyncDispatcher {
+	return &asyncDispatcher{
+		options: newOptions(opts),
+		size:    size,
+		policy:  policy,
+	}
+}
+
+type asyncDispatcher struct {
+	registry
+	options
+	size   int
+	policy OverflowPolicy
+	closed atomic.Bool
+	wg     sync.WaitGroup
+}
+
+func (d *asyncDispatcher) Listen(event Name, l Listener) {
+	d.ListenPriority(event, 0, l)
+}
+
+func (d *asyncDispatcher) ListenPriority(event Name, priority int, l Listener) {
+	d.start(&registration{pattern: event, priority: priority, l: l})
+}
+
+func (d *asyncDispatcher) Once(event Name, l Listener) {
+	d.start(&registration{pattern: event, once: true, l: l})
+}
+
+func (d *asyncDispatcher) start(reg *registration) {
+	if d.closed.Load() {
+		return
+	}
+	reg.q = newQueue(reg.l, d.size, d.policy, d.options)
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		reg.q.run()
+	}()
+	d.add(reg)
+	if d.closed.Load() {
+		d.remove(reg)
+		reg.q.stop()
+	}
+}
+
+func (d *asyncDispatcher) ListenFunc(event Name, fn ListenerFunc) (l Listener) {
+	l = &wrapper{fn}
+	d.Listen(event, l)
+	return
+}
+
+// Dispatch queues the event for every matching listener. Since each listener
+// runs on its own goroutine, priority only orders the queueing, not the
+// listeners themselves.
+func (d *asyncDispatcher) Dispatch(ctx context.Context, ev Event) (err error) {
+	if d.closed.Load() {
+		return ErrClosed
+	}
+	start := time.Now()
+	for _, r := range d.match(ev.Name) {
+		if r.once {
+			if !r.fired.CompareAndSwap(false, true) {
+				continue
+			}
+			d.remove(r)
+		}
+		if qerr := r.q.push(item{ctx, ev, start}); qerr != nil && err == nil {
+			err = qerr
+		}
+		if r.once {
+			r.q.stop()
+		}
+	}
+	d.report(ctx, ev, err)
+	return
+}
+
+func (d *asyncDispatcher) RemoveListener(event Name, l Listener) {
+	for _, r := range d.removeListener(event, l) {
+		r.q.stop()
+	}
+}
+
+func (d *asyncDispatcher) Close() error {
+	d.closed.Store(true)
+	for _, r := range d.clear() {
+		r.q.stop()
+	}
+	d.wg.Wait()
+	return nil
+}
+
+// Queue is a listener that passes events on to another from a goroutine of
+// its own, through a queue like the ones an AsyncDispatcher gives each
+// listener. It keeps a slow listener on a synchronous dispatcher from holding
+// up the code calling Dispatch. Since the listener sees events later, their
+// data must not change after they are dispatched.
+type Queue struct {
+	q    *queue
+	done chan struct{}
+}
+
+func NewQueue(l Listener, size int, policy OverflowPolicy, opts ...Option) *Queue {
+	q := &Queue{
+		q:    newQueue(l, size, policy, newOptions(opts)),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(q.done)
+		q.q.run()
+	}()
+	return q
+}
+
+func (q *Queue) Listen(ctx context.Context, ev Event) error {
+	return q.q.push(item{ctx, ev, time.Now()})
+}
+
+// Close stops accepting events and waits for the listener to finish the ones
+// already queued, so like AsyncDispatcher.Close, the listener must not call
+// it.
+func (q *Queue) Close() error {
+	q.q.stop()
+	<-q.done
+	return nil
+}
+
+type item struct {
+	ctx context.Context
+	ev  Event
+	at  time.Time
+}
+
+type queue struct {
+	options
+	l      Listener
+	ch     chan item
+	policy OverflowPolicy
+	quit   chan struct{}
+	once   sync.Once
+}
+
+func newQueue(l Listener, size int, policy OverflowPolicy, opts options) *queue {
+	return &queue{
+		options: opts,
+		l:       l,
+		ch:      make(chan item, size),
+		policy:  policy,
+		quit:    make(chan struct{}),
+	}
+}
+
+func (q *queue) push(it item) error {
+	select {
+	case <-q.quit:
+		return ErrClosed
+	default:
+	}
+
+	switch q.policy {
+	case DropOldest:
+		for {
+			select {
+			case q.ch <- it:
+				return nil
+			default:
+			}
+			select {
+			case <-q.ch:
+			default:
+			}
+		}
+	case Error:
+		select {
+		case q.ch <- it:
+			return nil
+		default:
+			return ErrQueueFull
+		}
+	default:
+		select {
+		case q.ch <- it:
+			return nil
+		case <-q.quit:
+			return ErrClosed
+		}
+	}
+}
+
+func (q *queue) run() {
+	for {
+		select {
+		case it := <-q.ch:
+			q.listen(it)
+		case <-q.quit:
+			for {
+				select {
+				case it := <-q.ch:
+					q.listen(it)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *queue) listen(it item) {
+	q.report(it.ctx, it.ev, safeListen(it.ctx, q.l, it.ev))
+	q.observe(it.ev, it.at)
+}
+
+func (q *queue) stop() {
+	q.once.Do(func() { close(q.quit) })
+}
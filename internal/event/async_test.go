@@ -0,0 +1,141 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncDispatch(t *testing.T) {
+	var events []Event
+	bus := NewAsyncDispatcher(8, Block)
+	bus.ListenFunc(testEvent, func(_ context.Context, ev Event) error {
+		events = append(events, ev)
+		return nil
+	})
+	for i := range 3 {
+		err := bus.Dispatch(context.Background(), Event{testEvent, i})
+		require.NoError(t, err)
+	}
+	require.NoError(t, bus.Close())
+	require.Equal(t, []Event{{testEvent, 0}, {testEvent, 1}, {testEvent, 2}}, events)
+
+	err := bus.Dispatch(context.Background(), Event{testEvent, 3})
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestAsyncDoesNotWaitForListener(t *testing.T) {
+	release := make(chan struct{})
+	bus := NewAsyncDispatcher(1, Block)
+	bus.ListenFunc(testEvent, func(context.Context, Event) error {
+		<-release
+		return nil
+	})
+	err := bus.Dispatch(context.Background(), Event{testEvent, 1})
+	require.NoError(t, err)
+	close(release)
+	require.NoError(t, bus.Close())
+}
+
+func TestQueue(t *testing.T) {
+	var received []any
+	started := make(chan struct{})
+	release := make(chan struct{})
+	q := NewQueue(ListenerFunc(func(_ context.Context, ev Event) error {
+		if ev.Data == 0 {
+			close(started)
+			<-release
+		}
+		received = append(received, ev.Data)
+		return nil
+	}), 1, DropOldest)
+	bus := NewDispatcher()
+	bus.Listen(testEvent, q)
+
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, 0}))
+	<-started
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, i}))
+	}
+	close(release)
+	require.NoError(t, q.Close())
+	require.Equal(t, []any{0, 3}, received)
+	require.ErrorIs(t, q.Listen(context.Background(), Event{testEvent, 4}), ErrClosed)
+}
+
+func TestAsyncOverflow(t *testing.T) {
+	var tests = []struct {
+		policy   OverflowPolicy
+		err      error
+		expected []any
+	}{
+		{DropOldest, nil, []any{0, 3, 4}},
+		{Error, ErrQueueFull, []any{0, 1, 2}},
+	}
+	for _, test := range tests {
+		var received []any
+		started := make(chan struct{})
+		release := make(chan struct{})
+		bus := NewAsyncDispatcher(2, test.policy)
+		bus.ListenFunc(testEvent, func(_ context.Context, ev Event) error {
+			if ev.Data == 0 {
+				close(started)
+				<-release
+			}
+			received = append(received, ev.Data)
+			return nil
+		})
+		require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, 0}))
+		<-started
+		var err error
+		for i := 1; i <= 4; i++ {
+			if e := bus.Dispatch(context.Background(), Event{testEvent, i}); e != nil {
+				err = e
+			}
+		}
+		require.ErrorIs(t, err, test.err, test.policy)
+		close(release)
+		require.NoError(t, bus.Close())
+		require.Equal(t, test.expected, received, test.policy)
+	}
+}
+
+func TestAsyncRemoveListenerFromListener(t *testing.T) {
+	var wg sync.WaitGroup
+	var calls int
+	bus := NewAsyncDispatcher(4, Block)
+	var l Listener
+	wg.Add(1)
+	l = bus.ListenFunc(testEvent, func(context.Context, Event) error {
+		calls++
+		bus.RemoveListener(testEvent, l)
+		wg.Done()
+		return nil
+	})
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, 1}))
+	wg.Wait()
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, 2}))
+	require.NoError(t, bus.Close())
+	require.Equal(t, 1, calls)
+}
+
+func TestAsyncOnceAndWildcard(t *testing.T) {
+	var once, all []any
+	bus := NewAsyncDispatcher(4, Block)
+	bus.Once(testEvent, ListenerFunc(func(_ context.Context, ev Event) error {
+		once = append(once, ev.Data)
+		return nil
+	}))
+	bus.ListenFunc("test.*", func(_ context.Context, ev Event) error {
+		all = append(all, ev.Data)
+		return nil
+	})
+	for i := range 3 {
+		require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, i}))
+	}
+	require.NoError(t, bus.Close())
+	require.Equal(t, []any{0}, once)
+	require.Equal(t, []any{0, 1, 2}, all)
+}
@@ -0,0 +1,81 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// ErrorHook is called with the error from every Dispatch that fails. For an
+// AsyncDispatcher, it is the only place listener errors are reported.
+type ErrorHook func(ctx context.Context, ev Event, err error)
+
+// LatencyHook is called with how long an event took to handle. An
+// AsyncDispatcher calls it for each listener, counting the time spent queued.
+type LatencyHook func(ev Event, d time.Duration)
+
+type Option func(*options)
+
+type options struct {
+	joinErrors  bool
+	errorHook   ErrorHook
+	latencyHook LatencyHook
+}
+
+func newOptions(opts []Option) (o options) {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return
+}
+
+// JoinErrors makes Dispatch call every listener even when one fails, recover
+// any panics as a *PanicError, and return all of the errors with errors.Join.
+func JoinErrors() Option {
+	return func(o *options) { o.joinErrors = true }
+}
+
+func WithErrorHook(hook ErrorHook) Option {
+	return func(o *options) { o.errorHook = hook }
+}
+
+func WithLatencyHook(hook LatencyHook) Option {
+	return func(o *options) { o.latencyHook = hook }
+}
+
+func (o options) observe(ev Event, start time.Time) {
+	if o.latencyHook != nil {
+		o.latencyHook(ev, time.Since(start))
+	}
+}
+
+func (o options) report(ctx context.Context, ev Event, err error) {
+	if err != nil && o.errorHook != nil {
+		o.errorHook(ctx, ev, err)
+	}
+}
+
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in listener: %v", e.Value)
+}
+
+func IsPanic(err error) bool {
+	var perr *PanicError
+	return errors.As(err, &perr)
+}
+
+func safeListen(ctx context.Context, l Listener, ev Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return l.Listen(ctx, ev)
+}
@@ -0,0 +1,112 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatchStopsAtFirstError(t *testing.T) {
+	var calls int
+	boom := errors.New("boom")
+	bus := NewDispatcher()
+	bus.ListenFunc(testEvent, func(context.Context, Event) error {
+		calls++
+		return boom
+	})
+	bus.ListenFunc(testEvent, func(context.Context, Event) error {
+		calls++
+		return nil
+	})
+	err := bus.Dispatch(context.Background(), Event{testEvent, 42})
+	require.ErrorIs(t, err, boom)
+	require.Equal(t, 1, calls)
+}
+
+func TestJoinErrors(t *testing.T) {
+	var calls int
+	var hooked error
+	boom := errors.New("boom")
+	bus := NewDispatcher(JoinErrors(), WithErrorHook(func(_ context.Context, _ Event, err error) {
+		hooked = err
+	}))
+	bus.ListenFunc(testEvent, func(context.Context, Event) error {
+		calls++
+		return boom
+	})
+	bus.ListenFunc(testEvent, func(context.Context, Event) error {
+		calls++
+		panic("oops")
+	})
+	bus.ListenFunc(testEvent, func(context.Context, Event) error {
+		calls++
+		return nil
+	})
+	err := bus.Dispatch(context.Background(), Event{testEvent, 42})
+	require.Equal(t, 3, calls)
+	require.ErrorIs(t, err, boom)
+	require.True(t, IsPanic(err))
+	var perr *PanicError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, "oops", perr.Value)
+	require.Equal(t, err, hooked)
+
+	hooked = nil
+	bus = NewDispatcher(JoinErrors(), WithErrorHook(func(_ context.Context, _ Event, err error) {
+		hooked = err
+	}))
+	err = bus.Dispatch(context.Background(), Event{testEvent, 42})
+	require.NoError(t, err)
+	require.Nil(t, hooked)
+}
+
+func TestAsyncRecoversPanic(t *testing.T) {
+	var hooked []error
+	bus := NewAsyncDispatcher(4, Block, WithErrorHook(func(_ context.Context, _ Event, err error) {
+		hooked = append(hooked, err)
+	}))
+	bus.ListenFunc(testEvent, func(context.Context, Event) error {
+		panic("oops")
+	})
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, 1}))
+	require.NoError(t, bus.Close())
+	require.Len(t, hooked, 1)
+	require.True(t, IsPanic(hooked[0]))
+}
+
+func TestLatencyHook(t *testing.T) {
+	var mux sync.Mutex
+	var observed []time.Duration
+	hook := WithLatencyHook(func(ev Event, d time.Duration) {
+		mux.Lock()
+		defer mux.Unlock()
+		require.Equal(t, testEvent, ev.Name)
+		observed = append(observed, d)
+	})
+	slow := func(context.Context, Event) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+
+	bus := NewDispatcher(hook)
+	bus.ListenFunc(testEvent, slow)
+	bus.ListenFunc(testEvent, slow)
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, 1}))
+	require.Len(t, observed, 1)
+	require.GreaterOrEqual(t, observed[0], 20*time.Millisecond)
+
+	observed = nil
+	async := NewAsyncDispatcher(4, Block, hook)
+	async.ListenFunc(testEvent, slow)
+	async.ListenFunc(testEvent, slow)
+	require.NoError(t, async.Dispatch(context.Background(), Event{testEvent, 1}))
+	require.NoError(t, async.Close())
+	require.Len(t, observed, 2)
+	for _, d := range observed {
+		require.GreaterOrEqual(t, d, 10*time.Millisecond)
+	}
+}
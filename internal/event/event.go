@@ -2,12 +2,23 @@ package event
 
 import (
 	"context"
-	"slices"
-	"sync"
+	"errors"
+	"strings"
+	"time"
 )
 
 type Name string
 
+// Match reports whether an event with the given name should be delivered to a
+// listener registered under n. A trailing "*" matches any suffix, so
+// "telnet.*" matches every telnet event and "*" matches everything.
+func (n Name) Match(event Name) bool {
+	if prefix, ok := strings.CutSuffix(string(n), "*"); ok {
+		return strings.HasPrefix(string(event), prefix)
+	}
+	return n == event
+}
+
 type Event struct {
 	Name
 	Data any
@@ -26,25 +37,37 @@ func (f ListenerFunc) Listen(ctx context.Context, ev Event) error {
 type Dispatcher interface {
 	Listen(event Name, l Listener)
 	ListenFunc(event Name, fn ListenerFunc) Listener
+	// ListenPriority registers a listener that is called before every
+	// listener with a lower priority. Listen uses priority 0.
+	ListenPriority(event Name, priority int, l Listener)
+	// Once registers a listener that is removed after its first event.
+	Once(event Name, l Listener)
 	Dispatch(ctx context.Context, ev Event) error
 	RemoveListener(event Name, l Listener)
+	// Listening reports whether any listener would receive an event with the
+	// given name, so that an event that is costly to build can be skipped.
+	Listening(event Name) bool
 }
 
-func NewDispatcher() Dispatcher {
-	return &dispatcher{
-		handlers: map[Name][]Listener{},
-	}
+func NewDispatcher(opts ...Option) Dispatcher {
+	return &dispatcher{options: newOptions(opts)}
 }
 
 type dispatcher struct {
-	handlers map[Name][]Listener
-	sync.RWMutex
+	registry
+	options
 }
 
 func (d *dispatcher) Listen(event Name, l Listener) {
-	d.Lock()
-	defer d.Unlock()
-	d.handlers[event] = append(d.handlers[event], l)
+	d.ListenPriority(event, 0, l)
+}
+
+func (d *dispatcher) ListenPriority(event Name, priority int, l Listener) {
+	d.add(&registration{pattern: event, priority: priority, l: l})
+}
+
+func (d *dispatcher) Once(event Name, l Listener) {
+	d.add(&registration{pattern: event, once: true, l: l})
 }
 
 type wrapper struct {
@@ -58,20 +81,30 @@ func (d *dispatcher) ListenFunc(event Name, fn ListenerFunc) (l Listener) {
 }
 
 func (d *dispatcher) Dispatch(ctx context.Context, ev Event) (err error) {
-	d.RLock()
-	defer d.RUnlock()
-	for _, h := range d.handlers[ev.Name] {
-		if err = h.Listen(ctx, ev); err != nil {
-			return
+	start := time.Now()
+	defer func() {
+		d.report(ctx, ev, err)
+		d.observe(ev, start)
+	}()
+	var errs []error
+	for _, r := range d.match(ev.Name) {
+		if r.once {
+			if !r.fired.CompareAndSwap(false, true) {
+				continue
+			}
+			d.remove(r)
+		}
+		if !d.joinErrors {
+			if err = r.l.Listen(ctx, ev); err != nil {
+				return
+			}
+		} else if lerr := safeListen(ctx, r.l, ev); lerr != nil {
+			errs = append(errs, lerr)
 		}
 	}
-	return
+	return errors.Join(errs...)
 }
 
 func (d *dispatcher) RemoveListener(event Name, l Listener) {
-	d.Lock()
-	defer d.Unlock()
-	d.handlers[event] = slices.DeleteFunc(d.handlers[event], func(ll Listener) bool {
-		return l == ll
-	})
+	d.removeListener(event, l)
 }
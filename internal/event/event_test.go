@@ -31,8 +31,84 @@ func TestRemoveListener(t *testing.T) {
 
 	bus := NewDispatcher()
 	l := bus.ListenFunc(testEvent, fn)
+	require.True(t, bus.Listening(testEvent))
 	bus.RemoveListener(testEvent, l)
+	require.False(t, bus.Listening(testEvent))
 	err := bus.Dispatch(context.Background(), Event{testEvent, 42})
 	require.NoError(t, err)
 	require.False(t, called)
 }
+
+func TestListenPriority(t *testing.T) {
+	var order []string
+	record := func(s string) ListenerFunc {
+		return func(context.Context, Event) error {
+			order = append(order, s)
+			return nil
+		}
+	}
+	bus := NewDispatcher()
+	bus.ListenFunc(testEvent, record("a"))
+	bus.ListenPriority(testEvent, 10, record("high"))
+	bus.ListenFunc(testEvent, record("b"))
+	bus.ListenPriority(testEvent, -10, record("low"))
+	bus.ListenPriority(testEvent, 10, record("high2"))
+	err := bus.Dispatch(context.Background(), Event{testEvent, 42})
+	require.NoError(t, err)
+	require.Equal(t, []string{"high", "high2", "a", "b", "low"}, order)
+}
+
+func TestOnce(t *testing.T) {
+	var calls int
+	bus := NewDispatcher()
+	bus.Once(testEvent, ListenerFunc(func(ctx context.Context, ev Event) error {
+		calls++
+		// dispatching from inside the listener must neither deadlock nor
+		// deliver the event a second time
+		return bus.Dispatch(ctx, ev)
+	}))
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, 1}))
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, 2}))
+	require.Equal(t, 1, calls)
+}
+
+func TestWildcard(t *testing.T) {
+	var tests = []struct {
+		pattern Name
+		event   Name
+		match   bool
+	}{
+		{"test.event", "test.event", true},
+		{"test.event", "test.other", false},
+		{"test.*", "test.event", true},
+		{"test.*", "test.reader.event", true},
+		{"test.*", "other.event", false},
+		{"*", "other.event", true},
+	}
+	for _, test := range tests {
+		var called bool
+		bus := NewDispatcher()
+		bus.ListenFunc(test.pattern, func(context.Context, Event) error {
+			called = true
+			return nil
+		})
+		require.Equal(t, test.match, bus.Listening(test.event), test)
+		err := bus.Dispatch(context.Background(), Event{test.event, nil})
+		require.NoError(t, err)
+		require.Equal(t, test.match, called, test)
+	}
+}
+
+func TestRemoveListenerDuringDispatch(t *testing.T) {
+	var calls int
+	bus := NewDispatcher()
+	var l Listener
+	l = bus.ListenFunc(testEvent, func(context.Context, Event) error {
+		calls++
+		bus.RemoveListener(testEvent, l)
+		return nil
+	})
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, 1}))
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, 2}))
+	require.Equal(t, 1, calls)
+}
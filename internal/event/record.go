@@ -0,0 +1,94 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// Record is one line of a recording, in JSON Lines format.
+type Record struct {
+	Time  time.Time       `json:"time"`
+	Name  Name            `json:"name"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// Recorder writes every event dispatched on the dispatchers it is attached to.
+type Recorder struct {
+	mu          sync.Mutex
+	enc         *json.Encoder
+	dispatchers []Dispatcher
+	ignore      map[Name]bool
+	now         func() time.Time
+}
+
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w), now: time.Now}
+}
+
+// Ignore stops the recorder writing events with the given names.
+func (r *Recorder) Ignore(names ...Name) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ignore == nil {
+		r.ignore = make(map[Name]bool)
+	}
+	for _, name := range names {
+		r.ignore[name] = true
+	}
+}
+
+// Attach records events from d before any other listener sees them.
+func (r *Recorder) Attach(d Dispatcher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dispatchers = append(r.dispatchers, d)
+	d.ListenPriority("*", math.MaxInt, r)
+}
+
+func (r *Recorder) Detach() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.dispatchers {
+		d.RemoveListener("*", r)
+	}
+	r.dispatchers = nil
+}
+
+func (r *Recorder) Listen(_ context.Context, ev Event) error {
+	r.mu.Lock()
+	ignored := r.ignore[ev.Name]
+	r.mu.Unlock()
+	if ignored {
+		return nil
+	}
+	rec := Record{Time: r.now(), Name: ev.Name}
+	if ev.Data != nil {
+		if data, err := json.Marshal(ev.Data); err != nil {
+			rec.Error = err.Error()
+		} else {
+			rec.Data = data
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(rec)
+}
+
+// ReadRecords reads a recording written by a Recorder.
+func ReadRecords(r io.Reader) (records []Record, err error) {
+	dec := json.NewDecoder(r)
+	for {
+		var rec Record
+		if err = dec.Decode(&rec); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return
+		}
+		records = append(records, rec)
+	}
+}
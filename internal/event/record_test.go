@@ -0,0 +1,50 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	r := NewRecorder(&buf)
+	r.now = func() time.Time { return now }
+
+	bus := NewDispatcher()
+	bus.ListenFunc(testEvent, func(context.Context, Event) error { return nil })
+	r.Attach(bus)
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, testData{42}}))
+	require.NoError(t, bus.Dispatch(context.Background(), Event{Name: "other.event"}))
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, func() {}}))
+	r.Detach()
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, testData{7}}))
+
+	records, err := ReadRecords(&buf)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, Record{Time: now, Name: testEvent, Data: json.RawMessage(`{"Value":42}`)}, records[0])
+	require.Equal(t, Record{Time: now, Name: "other.event"}, records[1])
+	require.NotEmpty(t, records[2].Error)
+}
+
+func TestRecorderIgnore(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	r.Ignore("other.event")
+
+	bus := NewDispatcher()
+	r.Attach(bus)
+	require.NoError(t, bus.Dispatch(context.Background(), Event{testEvent, testData{42}}))
+	require.NoError(t, bus.Dispatch(context.Background(), Event{Name: "other.event"}))
+
+	records, err := ReadRecords(&buf)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, testEvent, records[0].Name)
+}
@@ -0,0 +1,83 @@
+package event
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+type registration struct {
+	pattern  Name
+	priority int
+	once     bool
+	fired    atomic.Bool
+	l        Listener
+	q        *queue
+}
+
+// registry keeps listeners sorted by priority. Dispatchers take a snapshot of
+// the matching registrations and call them without holding the lock, so a
+// listener is free to add or remove listeners.
+type registry struct {
+	sync.RWMutex
+	entries []*registration
+}
+
+func (r *registry) add(reg *registration) {
+	r.Lock()
+	defer r.Unlock()
+	i, _ := slices.BinarySearchFunc(r.entries, reg.priority, func(e *registration, p int) int {
+		if e.priority >= p {
+			return -1
+		}
+		return 1
+	})
+	r.entries = slices.Insert(r.entries, i, reg)
+}
+
+func (r *registry) match(event Name) (result []*registration) {
+	r.RLock()
+	defer r.RUnlock()
+	for _, reg := range r.entries {
+		if reg.pattern.Match(event) {
+			result = append(result, reg)
+		}
+	}
+	return
+}
+
+func (r *registry) Listening(event Name) bool {
+	r.RLock()
+	defer r.RUnlock()
+	return slices.ContainsFunc(r.entries, func(e *registration) bool {
+		return e.pattern.Match(event)
+	})
+}
+
+func (r *registry) remove(reg *registration) {
+	r.Lock()
+	defer r.Unlock()
+	r.entries = slices.DeleteFunc(r.entries, func(e *registration) bool {
+		return e == reg
+	})
+}
+
+func (r *registry) removeListener(event Name, l Listener) (removed []*registration) {
+	r.Lock()
+	defer r.Unlock()
+	r.entries = slices.DeleteFunc(r.entries, func(e *registration) bool {
+		if e.pattern == event && e.l == l {
+			removed = append(removed, e)
+			return true
+		}
+		return false
+	})
+	return
+}
+
+func (r *registry) clear() (removed []*registration) {
+	r.Lock()
+	defer r.Unlock()
+	removed, r.entries = r.entries, nil
+	return
+}
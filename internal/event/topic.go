@@ -0,0 +1,70 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, d Dispatcher) context.Context {
+	return context.WithValue(ctx, contextKey{}, d)
+}
+
+func FromContext(ctx context.Context) (Dispatcher, bool) {
+	d, ok := ctx.Value(contextKey{}).(Dispatcher)
+	return d, ok
+}
+
+var ErrNoDispatcher = errors.New("no dispatcher in context")
+
+// Topic ties an event name to the type of its data, so that publishers and
+// subscribers that disagree about the type fail to compile rather than panic.
+type Topic[T any] struct {
+	Name Name
+}
+
+func NewTopic[T any](name Name) Topic[T] {
+	return Topic[T]{Name: name}
+}
+
+func (t Topic[T]) Event(data T) Event {
+	return Event{Name: t.Name, Data: data}
+}
+
+// Publish dispatches data on the dispatcher in ctx.
+func (t Topic[T]) Publish(ctx context.Context, data T) error {
+	d, ok := FromContext(ctx)
+	if !ok {
+		return ErrNoDispatcher
+	}
+	return d.Dispatch(ctx, t.Event(data))
+}
+
+// Listener adapts fn to a Listener for this topic. Events whose data is not a
+// T, which can only come from an untyped Dispatch, are reported as errors.
+func (t Topic[T]) Listener(fn func(context.Context, T) error) Listener {
+	return &wrapper{ListenerFunc(func(ctx context.Context, ev Event) error {
+		data, ok := ev.Data.(T)
+		if !ok && ev.Data != nil {
+			return fmt.Errorf("event %s: expected %T, got %T", ev.Name, data, ev.Data)
+		}
+		return fn(ctx, data)
+	})}
+}
+
+// Subscribe registers fn on d and returns the Listener to pass to
+// RemoveListener.
+func (t Topic[T]) Subscribe(d Dispatcher, fn func(context.Context, T) error) Listener {
+	l := t.Listener(fn)
+	d.Listen(t.Name, l)
+	return l
+}
+
+// Once registers fn on d to be called for the next event only.
+func (t Topic[T]) Once(d Dispatcher, fn func(context.Context, T) error) Listener {
+	l := t.Listener(fn)
+	d.Once(t.Name, l)
+	return l
+}
@@ -0,0 +1,51 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testData struct {
+	Value int
+}
+
+var testTopic = NewTopic[testData](testEvent)
+
+func TestTopic(t *testing.T) {
+	var received testData
+	bus := NewDispatcher()
+	l := testTopic.Subscribe(bus, func(_ context.Context, data testData) error {
+		received = data
+		return nil
+	})
+	ctx := NewContext(context.Background(), bus)
+	require.NoError(t, testTopic.Publish(ctx, testData{42}))
+	require.Equal(t, testData{42}, received)
+
+	bus.RemoveListener(testEvent, l)
+	require.NoError(t, testTopic.Publish(ctx, testData{7}))
+	require.Equal(t, testData{42}, received)
+}
+
+func TestTopicTypeMismatch(t *testing.T) {
+	var called bool
+	bus := NewDispatcher()
+	testTopic.Subscribe(bus, func(context.Context, testData) error {
+		called = true
+		return nil
+	})
+	err := bus.Dispatch(context.Background(), Event{testEvent, "forty-two"})
+	require.ErrorContains(t, err, "expected event.testData, got string")
+	require.False(t, called)
+
+	err = bus.Dispatch(context.Background(), Event{Name: testEvent})
+	require.NoError(t, err)
+	require.True(t, called)
+}
+
+func TestPublishWithoutDispatcher(t *testing.T) {
+	err := testTopic.Publish(context.Background(), testData{})
+	require.ErrorIs(t, err, ErrNoDispatcher)
+}
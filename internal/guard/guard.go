@@ -0,0 +1,164 @@
+// Package guard protects the telnet listener from clients that should not be
+// there, and from those guessing passwords.
+package guard
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Filter decides which addresses may connect. An address that matches Deny is
+// refused, and if Allow is not empty, so is one that does not match it.
+type Filter struct {
+	Allow, Deny []netip.Prefix
+}
+
+// ParsePrefixes parses CIDR prefixes, such as "10.0.0.0/8", or bare addresses.
+func ParsePrefixes(ss []string) ([]netip.Prefix, error) {
+	result := make([]netip.Prefix, 0, len(ss))
+	for _, s := range ss {
+		s = strings.TrimSpace(s)
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, prefix.Masked())
+	}
+	return result, nil
+}
+
+func (f Filter) Permits(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	contains := func(prefixes []netip.Prefix) bool {
+		for _, p := range prefixes {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+	if contains(f.Deny) {
+		return false
+	}
+	return len(f.Allow) == 0 || contains(f.Allow)
+}
+
+// LockoutConfig is how many failures a Lockout allows and for how long it then
+// locks a key out.
+type LockoutConfig struct {
+	// Threshold is how many failures in a row are allowed before the first
+	// lockout. Zero or less disables lockouts.
+	Threshold int
+	// Initial is how long the first lockout is. Each failure after it doubles
+	// the lockout, up to Max.
+	Initial time.Duration
+	// Max is the longest lockout. Failures are forgotten once a key has gone
+	// this long without one, counting from the end of its last lockout.
+	Max time.Duration
+}
+
+func (c LockoutConfig) Validate() error {
+	if c.Threshold > 0 && (c.Initial <= 0 || c.Max < c.Initial) {
+		return fmt.Errorf("lockout must be positive and no longer than its maximum: %v, %v", c.Initial, c.Max)
+	}
+	return nil
+}
+
+// Lockout counts failed attempts by key, such as a client address, and locks
+// out the keys that fail too often.
+type Lockout struct {
+	mux     sync.Mutex
+	config  LockoutConfig
+	entries map[string]*lockoutEntry
+}
+
+type lockoutEntry struct {
+	failures int
+	last     time.Time
+	until    time.Time
+}
+
+func NewLockout(config LockoutConfig) *Lockout {
+	return &Lockout{config: config, entries: make(map[string]*lockoutEntry)}
+}
+
+// Configure replaces the configuration, keeping the failures counted so far.
+func (l *Lockout) Configure(config LockoutConfig) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.config = config
+}
+
+// Locked returns how much longer key is locked out for, or zero if it is not.
+func (l *Lockout) Locked(key string, now time.Time) time.Duration {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if l.config.Threshold <= 0 {
+		return 0
+	}
+	if e, found := l.entries[key]; found && now.Before(e.until) {
+		return e.until.Sub(now)
+	}
+	return 0
+}
+
+// Fail records a failed attempt by key, and returns how long it is now locked
+// out for.
+func (l *Lockout) Fail(key string, now time.Time) time.Duration {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if l.config.Threshold <= 0 {
+		return 0
+	}
+	l.expire(now)
+	e, found := l.entries[key]
+	if !found {
+		e = &lockoutEntry{}
+		l.entries[key] = e
+	}
+	e.failures++
+	e.last = now
+	if e.failures < l.config.Threshold {
+		return 0
+	}
+	d := min(l.config.Initial, l.config.Max)
+	for range e.failures - l.config.Threshold {
+		if d >= l.config.Max/2 {
+			d = l.config.Max
+			break
+		}
+		d *= 2
+	}
+	e.until = now.Add(d)
+	return d
+}
+
+// Reset forgets the failures of key, after it succeeds.
+func (l *Lockout) Reset(key string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	delete(l.entries, key)
+}
+
+func (l *Lockout) expire(now time.Time) {
+	for key, e := range l.entries {
+		quiet := e.last
+		if e.until.After(quiet) {
+			quiet = e.until
+		}
+		if now.Sub(quiet) >= l.config.Max {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package guard
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	allow, err := ParsePrefixes([]string{"10.0.0.0/8", "192.168.1.5", "fd00::/8"})
+	require.NoError(t, err)
+	deny, err := ParsePrefixes([]string{"10.0.0.13"})
+	require.NoError(t, err)
+
+	var tests = []struct {
+		filter  Filter
+		addr    string
+		permits bool
+	}{
+		{Filter{}, "203.0.113.7", true},
+		{Filter{Allow: allow}, "10.1.2.3", true},
+		{Filter{Allow: allow}, "::ffff:10.1.2.3", true},
+		{Filter{Allow: allow}, "192.168.1.5", true},
+		{Filter{Allow: allow}, "192.168.1.6", false},
+		{Filter{Allow: allow}, "fd12::1", true},
+		{Filter{Allow: allow, Deny: deny}, "10.0.0.13", false},
+		{Filter{Deny: deny}, "10.0.0.13", false},
+		{Filter{Deny: deny}, "10.0.0.14", true},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.permits, test.filter.Permits(netip.MustParseAddr(test.addr)), test.addr)
+	}
+
+	for _, s := range []string{"10.0.0.0/33", "localhost", "10.0.0"} {
+		_, err := ParsePrefixes([]string{s})
+		require.Error(t, err, s)
+	}
+}
+
+func TestLockout(t *testing.T) {
+	l := NewLockout(LockoutConfig{Threshold: 3, Initial: time.Minute, Max: 5 * time.Minute})
+	now := time.Now()
+
+	require.Zero(t, l.Fail("a", now))
+	require.Zero(t, l.Fail("a", now))
+	require.Zero(t, l.Locked("a", now))
+	require.Equal(t, time.Minute, l.Fail("a", now))
+	require.Equal(t, time.Minute, l.Locked("a", now))
+	require.Equal(t, 30*time.Second, l.Locked("a", now.Add(30*time.Second)))
+	require.Zero(t, l.Locked("b", now))
+
+	now = now.Add(time.Minute)
+	require.Zero(t, l.Locked("a", now))
+	require.Equal(t, 2*time.Minute, l.Fail("a", now))
+	require.Equal(t, 4*time.Minute, l.Fail("a", now))
+	require.Equal(t, 5*time.Minute, l.Fail("a", now))
+	for range 100 {
+		l.Fail("a", now)
+	}
+	require.Equal(t, 5*time.Minute, l.Locked("a", now))
+
+	l.Reset("a")
+	require.Zero(t, l.Locked("a", now))
+	require.Zero(t, l.Fail("a", now))
+
+	// Failures are forgotten after Max without another.
+	now = now.Add(5 * time.Minute)
+	require.Zero(t, l.Fail("a", now))
+	require.Zero(t, l.Fail("a", now))
+
+	l.Configure(LockoutConfig{})
+	require.Zero(t, l.Fail("a", now))
+	require.Zero(t, l.Locked("a", now))
+}
+
+func TestLockoutBackoff(t *testing.T) {
+	config := LockoutConfig{Threshold: 5, Initial: 30 * time.Second, Max: time.Hour}
+	l := NewLockout(config)
+	now := time.Now()
+
+	for range 4 {
+		require.Zero(t, l.Fail("a", now))
+	}
+	d := l.Fail("a", now)
+	require.Equal(t, 30*time.Second, d)
+	for range 100 {
+		next := l.Fail("a", now)
+		require.True(t, next >= d && next <= time.Hour, next)
+		d = next
+	}
+	require.Equal(t, time.Hour, d)
+
+	// Once the lockout ends, the next failure locks the key out again rather
+	// than allowing Threshold more.
+	now = now.Add(time.Hour)
+	require.Zero(t, l.Locked("a", now))
+	require.Equal(t, time.Hour, l.Fail("a", now))
+
+	// The failures are forgotten Max after the lockout ends.
+	now = now.Add(2 * time.Hour)
+	require.Zero(t, l.Fail("a", now))
+}
+
+func TestLockoutConfig(t *testing.T) {
+	require.NoError(t, LockoutConfig{}.Validate())
+	require.NoError(t, LockoutConfig{Threshold: 1, Initial: time.Second, Max: time.Second}.Validate())
+	require.Error(t, LockoutConfig{Threshold: 1}.Validate())
+	require.Error(t, LockoutConfig{Threshold: 1, Initial: time.Minute, Max: time.Second}.Validate())
+}
@@ -0,0 +1,200 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatHTML Format = "html"
+	FormatJSON Format = "json"
+	FormatText Format = "txt"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatHTML, FormatJSON, FormatText:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format: %q", s)
+	}
+}
+
+type Exporter interface {
+	Begin() error
+	WriteLine(Line) error
+	End() error
+}
+
+func NewExporter(w io.Writer, format Format, title string) Exporter {
+	switch format {
+	case FormatHTML:
+		return &htmlExporter{w: w, title: title}
+	case FormatJSON:
+		return &jsonExporter{w: w}
+	default:
+		return &textExporter{w: w}
+	}
+}
+
+// Export writes every line in [from, to) that was received from the upstream,
+// dropping the separators that mark where the log was opened and closed.
+func Export(e Exporter, files []File, from, to time.Time) error {
+	if err := e.Begin(); err != nil {
+		return err
+	}
+	err := Read(Before(files, to), func(l Line) error {
+		if l.IsSeparator() || l.Time.Before(from) || (!to.IsZero() && !l.Time.Before(to)) {
+			return nil
+		}
+		return e.WriteLine(l)
+	})
+	if err != nil {
+		return err
+	}
+	return e.End()
+}
+
+// ExportScene writes the lines of the scene called name that started at from.
+// A scene that iris recorded has separators where it started and stopped, and
+// only the lines between them are written. A scene that was added afterwards
+// has none, so it is exported like any other range, from from to to.
+func ExportScene(e Exporter, files []File, name string, from, to time.Time) error {
+	start, stop := KindSceneStart+" "+name, KindSceneStop+" "+name
+	var exact, estimated []Line
+	var found, inScene bool
+	err := Read(Before(files, to), func(l Line) error {
+		switch {
+		case l.Kind == start && l.Time.Equal(from):
+			found, inScene = true, true
+		case inScene && l.Kind == stop:
+			inScene = false
+		case l.IsSeparator():
+		case inScene:
+			exact = append(exact, l)
+		case !found && !l.Time.Before(from) && (to.IsZero() || l.Time.Before(to)):
+			estimated = append(estimated, l)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	lines := estimated
+	if found {
+		lines = exact
+	}
+	if err := e.Begin(); err != nil {
+		return err
+	}
+	for _, l := range lines {
+		if err := e.WriteLine(l); err != nil {
+			return err
+		}
+	}
+	return e.End()
+}
+
+type textExporter struct {
+	w io.Writer
+}
+
+func (e *textExporter) Begin() error { return nil }
+func (e *textExporter) End() error   { return nil }
+
+func (e *textExporter) WriteLine(l Line) error {
+	_, err := fmt.Fprintln(e.w, l.Text)
+	return err
+}
+
+type jsonExporter struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonExporter) Begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonExporter) End() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+func (e *jsonExporter) WriteLine(l Line) error {
+	buf, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		buf = append([]byte{','}, buf...)
+	}
+	e.count++
+	_, err = e.w.Write(buf)
+	return err
+}
+
+type htmlExporter struct {
+	w     io.Writer
+	title string
+}
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+</head>
+<body>
+<pre>
+`
+
+const htmlFooter = `</pre>
+</body>
+</html>
+`
+
+func (e *htmlExporter) Begin() error {
+	_, err := fmt.Fprintf(e.w, htmlHeader, html.EscapeString(e.title))
+	return err
+}
+
+func (e *htmlExporter) End() error {
+	_, err := io.WriteString(e.w, htmlFooter)
+	return err
+}
+
+var ansiRegexp = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]")
+
+func StripANSI(s string) string {
+	return ansiRegexp.ReplaceAllString(s, "")
+}
+
+// StripANSIIndex returns s without ANSI escape codes, along with the offset in
+// s of each byte of the result.
+func StripANSIIndex(s string) (string, []int) {
+	var b strings.Builder
+	index := make([]int, 0, len(s))
+	last := 0
+	for _, m := range append(ansiRegexp.FindAllStringIndex(s, -1), []int{len(s), len(s)}) {
+		b.WriteString(s[last:m[0]])
+		for i := last; i < m[0]; i++ {
+			index = append(index, i)
+		}
+		last = m[1]
+	}
+	return b.String(), index
+}
+
+func (e *htmlExporter) WriteLine(l Line) error {
+	_, err := fmt.Fprintln(e.w, html.EscapeString(StripANSI(l.Text)))
+	return err
+}
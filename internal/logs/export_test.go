@@ -0,0 +1,164 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	day1 := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	writeLog(t, dir, "game", day1, func(buf *bytes.Buffer) {
+		WriteSeparator(buf, KindOpened, day1.Add(time.Hour))
+		buf.WriteString("one\n")
+		WriteSeparator(buf, KindClosed, day1.Add(2*time.Hour))
+	})
+	writeLog(t, dir, "game", day2, func(buf *bytes.Buffer) {
+		WriteSeparator(buf, KindOpened, day2.Add(time.Hour))
+		buf.WriteString("two\n")
+		WriteSeparator(buf, KindClosed, day2.Add(2*time.Hour))
+	})
+	index, err := Index(dir)
+	require.NoError(t, err)
+
+	var tests = []struct {
+		from, to time.Time
+		expected string
+	}{
+		{time.Time{}, time.Time{}, "one\ntwo\n"},
+		{day2, time.Time{}, "two\n"},
+		{time.Time{}, day2, "one\n"},
+		{day1.Add(90 * time.Minute), day2.Add(90 * time.Minute), "two\n"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		err := Export(NewExporter(&buf, FormatText, "game"), index["game"], test.from, test.to)
+		require.NoError(t, err, i)
+		require.Equal(t, test.expected, buf.String(), i)
+	}
+}
+
+func TestExportWithinSession(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	opened := day.Add(10 * time.Hour)
+	writeLog(t, dir, "game", day, func(buf *bytes.Buffer) {
+		WriteSeparator(buf, KindOpened, opened)
+		buf.WriteString("a\nb\nc\nd\n")
+		WriteSeparator(buf, KindClosed, opened.Add(4*time.Minute))
+	})
+	// A log that was never closed ends when it was last written.
+	day2 := day.AddDate(0, 0, 1)
+	opened2 := day2.Add(10 * time.Hour)
+	writeLog(t, dir, "game", day2, func(buf *bytes.Buffer) {
+		WriteSeparator(buf, KindOpened, opened2)
+		buf.WriteString("e\nf\ng\nh\n")
+	})
+	path := FileName(dir, "game", day2)
+	require.NoError(t, os.Chtimes(path, opened2.Add(4*time.Minute), opened2.Add(4*time.Minute)))
+	index, err := Index(dir)
+	require.NoError(t, err)
+
+	var tests = []struct {
+		from, to time.Time
+		expected string
+	}{
+		{opened.Add(time.Minute), opened.Add(3 * time.Minute), "b\nc\n"},
+		{opened.Add(3 * time.Minute), opened2.Add(time.Minute), "d\ne\n"},
+		{opened2.Add(2 * time.Minute), time.Time{}, "g\nh\n"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		err := Export(NewExporter(&buf, FormatText, "game"), index["game"], test.from, test.to)
+		require.NoError(t, err, i)
+		require.Equal(t, test.expected, buf.String(), i)
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	writeLog(t, dir, "game", day, func(buf *bytes.Buffer) {
+		WriteSeparator(buf, KindOpened, day.Add(time.Hour))
+		buf.WriteString("\x1b[1m<Bob>\x1b[0m & friends\n")
+	})
+	index, err := Index(dir)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = Export(NewExporter(&buf, FormatHTML, "game"), index["game"], time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.True(t, strings.Contains(buf.String(), "<title>game</title>"))
+	require.True(t, strings.Contains(buf.String(), "\n&lt;Bob&gt; &amp; friends\n"))
+
+	buf.Reset()
+	err = Export(NewExporter(&buf, FormatJSON, "game"), index["game"], time.Time{}, time.Time{})
+	require.NoError(t, err)
+	var lines []Line
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &lines))
+	require.Len(t, lines, 1)
+	require.Equal(t, "\x1b[1m<Bob>\x1b[0m & friends", lines[0].Text)
+
+	_, err = ParseFormat("pdf")
+	require.Error(t, err)
+}
+
+func TestStripANSIIndex(t *testing.T) {
+	plain, index := StripANSIIndex("\x1b[1mBob\x1b[0m: hi")
+	require.Equal(t, "Bob: hi", plain)
+	require.Equal(t, []int{4, 5, 6, 11, 12, 13, 14}, index)
+
+	plain, index = StripANSIIndex("")
+	require.Equal(t, "", plain)
+	require.Empty(t, index)
+}
+
+func TestExportScene(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	opened := day.Add(10 * time.Hour)
+	ball := opened.Add(time.Minute)
+	writeLog(t, dir, "game", day, func(buf *bytes.Buffer) {
+		WriteSeparator(buf, KindOpened, opened)
+		buf.WriteString("a\n")
+		WriteSeparator(buf, KindSceneStart+" Ball", opened)
+		buf.WriteString("b\n")
+		WriteSeparator(buf, KindSceneStop+" Ball", opened)
+		buf.WriteString("c\n")
+		// The scene starts and stops within a second, so only the separators
+		// can tell its lines apart.
+		WriteSeparator(buf, KindSceneStart+" Ball", ball)
+		buf.WriteString("d\n")
+		WriteSeparator(buf, "input alice: say hi", ball)
+		buf.WriteString("e\n")
+		WriteSeparator(buf, KindSceneStop+" Ball", ball)
+		buf.WriteString("f\ng\nh\n")
+		WriteSeparator(buf, KindClosed, opened.Add(5*time.Minute))
+	})
+	index, err := Index(dir)
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name     string
+		from, to time.Time
+		expected string
+	}{
+		{"Ball", opened, opened, "b\n"},
+		{"Ball", ball, ball, "d\ne\n"},
+		// A scene added afterwards is cut at the estimated times.
+		{"Added", opened.Add(2 * time.Minute), opened.Add(4 * time.Minute), "g\nh\n"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		err := ExportScene(NewExporter(&buf, FormatText, "game"), index["game"], test.name, test.from, test.to)
+		require.NoError(t, err, i)
+		require.Equal(t, test.expected, buf.String(), i)
+	}
+}
@@ -0,0 +1,225 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	DateFormat = "2006-01-02"
+	TimeFormat = "2006-01-02 15:04:05 -0700 MST"
+
+	KindOpened     = "opened"
+	KindClosed     = "closed"
+	KindSceneStart = "scene start"
+	KindSceneStop  = "scene stop"
+)
+
+const separatorDashes = "---------------"
+const separatorFormat = separatorDashes + " %s - %s " + separatorDashes + "\n"
+
+func FileName(dir, key string, t time.Time) string {
+	return path.Join(dir, fmt.Sprintf("%s-%s.log", t.Format(DateFormat), key))
+}
+
+func SeparatorPrefix(kind string) string {
+	return separatorDashes + " " + kind
+}
+
+func WriteSeparator(w io.Writer, kind string, t time.Time) error {
+	_, err := fmt.Fprintf(w, separatorFormat, kind, t.Format(TimeFormat))
+	return err
+}
+
+// ParseSeparator reports the kind and timestamp of a separator line written by
+// WriteSeparator. The kind may contain spaces, e.g. "scene start Foo".
+func ParseSeparator(line string) (kind string, t time.Time, ok bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, separatorDashes+" ") || !strings.HasSuffix(line, " "+separatorDashes) {
+		return
+	}
+	line = line[len(separatorDashes)+1 : len(line)-len(separatorDashes)-1]
+	i := strings.LastIndex(line, " - ")
+	if i < 0 {
+		return
+	}
+	t, err := time.Parse(TimeFormat, line[i+3:])
+	if err != nil {
+		return
+	}
+	return line[:i], t, true
+}
+
+type File struct {
+	Upstream string
+	Date     time.Time
+	Path     string
+}
+
+var fileNameRegexp = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)\.log$`)
+
+// Index returns the log files in dir grouped by upstream and sorted by date.
+func Index(dir string) (map[string][]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string][]File{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		date, err := time.ParseInLocation(DateFormat, m[1], time.Local)
+		if err != nil {
+			continue
+		}
+		result[m[2]] = append(result[m[2]], File{
+			Upstream: m[2],
+			Date:     date,
+			Path:     path.Join(dir, entry.Name()),
+		})
+	}
+	for _, files := range result {
+		slices.SortFunc(files, func(a, b File) int {
+			return a.Date.Compare(b.Date)
+		})
+	}
+	return result, nil
+}
+
+type Line struct {
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind,omitempty"`
+	Text string    `json:"text"`
+}
+
+// IsSeparator is true for lines that were written by WriteSeparator rather
+// than received from the upstream.
+func (l Line) IsSeparator() bool { return l.Kind != "" }
+
+// Read stitches together the files in order and calls fn for each line. A
+// separator carries its own time. Other lines only have the times of the
+// separators around them, so each is given an estimate, by how far it is
+// through the bytes between them. Lines after the last separator are placed
+// between it and when the file was last written.
+func Read(files []File, fn func(Line) error) error {
+	for _, f := range files {
+		if err := readFile(f, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mark is a known time in a log file, at a separator that runs from start to
+// end.
+type mark struct {
+	start, end int64
+	time       time.Time
+}
+
+// estimate returns the time of a line at offset, between the marks before and
+// after it.
+func estimate(prev, next mark, offset int64) time.Time {
+	span := next.start - prev.end
+	if span <= 0 || !next.time.After(prev.time) {
+		return prev.time
+	}
+	d := next.time.Sub(prev.time)
+	return prev.time.Add(time.Duration(float64(d) * float64(offset-prev.end) / float64(span)))
+}
+
+// readMarks returns the marks in a file, starting at its date and ending when
+// it was last written, along with how much of it they cover. A file that is
+// still being written is only read up to there.
+func readMarks(file *os.File, date time.Time) ([]mark, int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	size := info.Size()
+	marks := []mark{{time: date}}
+	r := bufio.NewReader(io.LimitReader(file, size))
+	var offset int64
+	for {
+		text, err := r.ReadString('\n')
+		if _, ts, ok := ParseSeparator(text); ok {
+			marks = append(marks, mark{start: offset, end: offset + int64(len(text)), time: ts})
+		}
+		offset += int64(len(text))
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, 0, err
+		}
+	}
+	last := marks[len(marks)-1].time
+	if modified := info.ModTime(); modified.After(last) {
+		last = modified
+	}
+	marks = append(marks, mark{start: size, end: size, time: last})
+	return marks, size, nil
+}
+
+func readFile(f File, fn func(Line) error) error {
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	marks, size, err := readMarks(file, f.Date)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	prev, next := marks[0], marks[1:]
+	r := bufio.NewReader(io.LimitReader(file, size))
+	var offset int64
+	for {
+		text, err := r.ReadString('\n')
+		if text != "" {
+			line := Line{Text: strings.TrimSuffix(text, "\n")}
+			if kind, ts, ok := ParseSeparator(text); ok {
+				line.Time, line.Kind = ts, kind
+				prev, next = next[0], next[1:]
+			} else {
+				line.Time = estimate(prev, next[0], offset)
+			}
+			offset += int64(len(text))
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// Before returns the files that may contain lines earlier than t. A file is
+// named for the day it was opened, but a long session can keep writing to it
+// past midnight, so nothing can be said about the end of a file.
+func Before(files []File, t time.Time) []File {
+	if t.IsZero() {
+		return files
+	}
+	return slices.DeleteFunc(slices.Clone(files), func(f File) bool {
+		return !f.Date.Before(t)
+	})
+}
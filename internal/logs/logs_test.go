@@ -0,0 +1,83 @@
+package logs
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeLog(t *testing.T, dir, key string, date time.Time, fn func(*bytes.Buffer)) {
+	var buf bytes.Buffer
+	fn(&buf)
+	err := os.WriteFile(FileName(dir, key, date), buf.Bytes(), 0644)
+	require.NoError(t, err)
+}
+
+func TestSeparator(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC)
+	require.NoError(t, WriteSeparator(&buf, "scene start The Ball", ts))
+	kind, parsed, ok := ParseSeparator(buf.String())
+	require.True(t, ok)
+	require.Equal(t, "scene start The Ball", kind)
+	require.True(t, ts.Equal(parsed))
+
+	_, _, ok = ParseSeparator("--------------- not a separator")
+	require.False(t, ok)
+	_, _, ok = ParseSeparator("Bob says, \"hi\"")
+	require.False(t, ok)
+}
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+	day1 := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	for _, d := range []time.Time{day2, day1} {
+		writeLog(t, dir, "my-game", d, func(*bytes.Buffer) {})
+	}
+	writeLog(t, dir, "other", day2, func(*bytes.Buffer) {})
+	require.NoError(t, os.WriteFile(path.Join(dir, "notes.txt"), nil, 0644))
+
+	index, err := Index(dir)
+	require.NoError(t, err)
+	require.Len(t, index, 2)
+	require.Len(t, index["my-game"], 2)
+	require.True(t, day1.Equal(index["my-game"][0].Date))
+	require.True(t, day2.Equal(index["my-game"][1].Date))
+	require.Equal(t, "other", index["other"][0].Upstream)
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	opened := day.Add(10 * time.Hour)
+	closed := opened.Add(time.Hour)
+	writeLog(t, dir, "game", day, func(buf *bytes.Buffer) {
+		buf.WriteString("before\n")
+		WriteSeparator(buf, KindOpened, opened)
+		buf.WriteString("hello\n")
+		WriteSeparator(buf, KindClosed, closed)
+		buf.WriteString("partial")
+	})
+	index, err := Index(dir)
+	require.NoError(t, err)
+
+	var lines []Line
+	err = Read(index["game"], func(l Line) error {
+		lines = append(lines, l)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 5)
+	require.Equal(t, Line{Time: day, Text: "before"}, lines[0])
+	require.Equal(t, KindOpened, lines[1].Kind)
+	require.True(t, opened.Equal(lines[2].Time))
+	require.Equal(t, "hello", lines[2].Text)
+	require.Equal(t, KindClosed, lines[3].Kind)
+	require.True(t, closed.Equal(lines[4].Time))
+	require.Equal(t, "partial", lines[4].Text)
+}
@@ -0,0 +1,246 @@
+// Package notify delivers the notifications raised by triggers to the people
+// who are not around to see them.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stesla/iris/internal/event"
+)
+
+type Notification struct {
+	Upstream string    `json:"upstream"`
+	Text     string    `json:"text"`
+	Time     time.Time `json:"time"`
+}
+
+// QuietHours is a daily period, in local time, during which webhooks are not
+// sent. The zero value is never quiet.
+type QuietHours struct {
+	start, end time.Duration
+}
+
+// ParseQuietHours parses a period such as "22:00-07:00". An empty string is
+// never quiet.
+func ParseQuietHours(s string) (q QuietHours, err error) {
+	if s == "" {
+		return
+	}
+	start, end, found := strings.Cut(s, "-")
+	if !found {
+		return q, fmt.Errorf("quiet hours must be START-END: %q", s)
+	}
+	if q.start, err = parseClock(start); err != nil {
+		return
+	}
+	q.end, err = parseClock(end)
+	return
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day: %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls in the quiet hours, which may run past
+// midnight.
+func (q QuietHours) Contains(t time.Time) bool {
+	t = t.Local()
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if q.start <= q.end {
+		return q.start <= now && now < q.end
+	}
+	return now >= q.start || now < q.end
+}
+
+// Webhook posts each notification as JSON to URL.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w *Webhook) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %s: %s", w.URL, resp.Status)
+	}
+	return nil
+}
+
+// Policy is when the notifications from an upstream are delivered.
+type Policy struct {
+	// Quiet is when the webhook is not sent notifications.
+	Quiet QuietHours
+	// Dedup is how long the same text from the same upstream is ignored for
+	// after it has been delivered.
+	Dedup time.Duration
+}
+
+// Config is how a Notifier delivers notifications.
+type Config struct {
+	Webhook *Webhook
+	// Policy is for the upstreams that do not have one in Upstreams.
+	Policy
+	Upstreams map[string]Policy
+}
+
+func (c Config) policy(upstream string) Policy {
+	if p, found := c.Upstreams[upstream]; found {
+		return p
+	}
+	return c.Policy
+}
+
+const (
+	digestSize     = 100
+	queueSize      = 64
+	webhookTimeout = 10 * time.Second
+)
+
+const (
+	eventNotification event.Name = "notify.notification"
+	eventWebhook      event.Name = "notify.webhook"
+)
+
+var (
+	topicNotification = event.NewTopic[Notification](eventNotification)
+	topicWebhook      = event.NewTopic[Notification](eventWebhook)
+)
+
+// Notifier delivers notifications to subscribers, to a webhook, and to a
+// digest for the next person to attach to an upstream.
+type Notifier struct {
+	dispatcher event.AsyncDispatcher
+
+	mux     sync.Mutex
+	config  Config
+	sent    map[Notification]time.Time
+	digests map[string][]Notification
+}
+
+// NewNotifier returns a Notifier, which reports the errors from the webhook
+// to onError.
+func NewNotifier(config Config, onError event.ErrorHook) *Notifier {
+	n := &Notifier{
+		sent:    make(map[Notification]time.Time),
+		digests: make(map[string][]Notification),
+	}
+	n.dispatcher = event.NewAsyncDispatcher(queueSize, event.DropOldest, event.WithErrorHook(onError))
+	topicWebhook.Subscribe(n.dispatcher, n.sendWebhook)
+	n.Configure(config)
+	return n
+}
+
+// Configure replaces the configuration, keeping the digests.
+func (n *Notifier) Configure(config Config) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	n.config = config
+}
+
+func (n *Notifier) Close() error {
+	return n.dispatcher.Close()
+}
+
+// Notify delivers a notification to the subscribers, and if idle, to the
+// digest and the webhook. It returns false if the notification was a
+// duplicate.
+func (n *Notifier) Notify(note Notification, idle bool) bool {
+	if note.Time.IsZero() {
+		note.Time = time.Now()
+	}
+	n.mux.Lock()
+	if n.duplicate(note) {
+		n.mux.Unlock()
+		return false
+	}
+	quiet := n.config.policy(note.Upstream).Quiet
+	webhook := idle && n.config.Webhook != nil && !quiet.Contains(note.Time)
+	if idle {
+		digest := append(n.digests[note.Upstream], note)
+		n.digests[note.Upstream] = digest[max(0, len(digest)-digestSize):]
+	}
+	n.mux.Unlock()
+
+	ctx := context.Background()
+	n.dispatcher.Dispatch(ctx, topicNotification.Event(note))
+	if webhook {
+		n.dispatcher.Dispatch(ctx, topicWebhook.Event(note))
+	}
+	return true
+}
+
+func (n *Notifier) duplicate(note Notification) bool {
+	key := Notification{Upstream: note.Upstream, Text: note.Text}
+	for k, t := range n.sent {
+		if note.Time.Sub(t) >= n.config.policy(k.Upstream).Dedup {
+			delete(n.sent, k)
+		}
+	}
+	if _, found := n.sent[key]; found {
+		return true
+	}
+	if n.config.policy(note.Upstream).Dedup > 0 {
+		n.sent[key] = note.Time
+	}
+	return false
+}
+
+func (n *Notifier) sendWebhook(ctx context.Context, note Notification) error {
+	n.mux.Lock()
+	webhook := n.config.Webhook
+	n.mux.Unlock()
+	if webhook == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	return webhook.Send(ctx, note)
+}
+
+// Subscribe calls fn with every notification that is delivered until the
+// returned function is called.
+func (n *Notifier) Subscribe(fn func(Notification)) (unsubscribe func()) {
+	l := topicNotification.Subscribe(n.dispatcher, func(_ context.Context, note Notification) error {
+		fn(note)
+		return nil
+	})
+	return func() { n.dispatcher.RemoveListener(eventNotification, l) }
+}
+
+// TakeDigest returns the notifications raised on upstream while nobody was
+// attached, and clears them.
+func (n *Notifier) TakeDigest(upstream string) []Notification {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	digest := n.digests[upstream]
+	delete(n.digests, upstream)
+	return digest
+}
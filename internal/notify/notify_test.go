@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stesla/iris/internal/event"
+)
+
+func TestQuietHours(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 19, hour, minute, 0, 0, time.Local)
+	}
+
+	q, err := ParseQuietHours("22:00-07:30")
+	require.NoError(t, err)
+	require.True(t, q.Contains(at(23, 0)))
+	require.True(t, q.Contains(at(3, 0)))
+	require.True(t, q.Contains(at(7, 29)))
+	require.False(t, q.Contains(at(7, 30)))
+	require.False(t, q.Contains(at(12, 0)))
+
+	q, err = ParseQuietHours("13:00-14:00")
+	require.NoError(t, err)
+	require.True(t, q.Contains(at(13, 30)))
+	require.False(t, q.Contains(at(14, 0)))
+
+	q, err = ParseQuietHours("")
+	require.NoError(t, err)
+	require.False(t, q.Contains(at(3, 0)))
+
+	for _, s := range []string{"22:00", "10pm-7am", "25:00-01:00"} {
+		_, err := ParseQuietHours(s)
+		require.Error(t, err, s)
+	}
+}
+
+func newWebhookServer(t *testing.T, status int) (*Webhook, chan Notification) {
+	received := make(chan Notification, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var n Notification
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		received <- n
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return &Webhook{URL: server.URL, Client: server.Client()}, received
+}
+
+func receive(t *testing.T, ch chan Notification) Notification {
+	t.Helper()
+	select {
+	case n := <-ch:
+		return n
+	case <-time.After(time.Second):
+		t.Fatal("no notification")
+		return Notification{}
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	webhook, received := newWebhookServer(t, http.StatusNoContent)
+	sent := Notification{Upstream: "game", Text: "Bob pages: hi", Time: time.Now().UTC().Truncate(time.Second)}
+	require.NoError(t, webhook.Send(context.Background(), sent))
+	require.Equal(t, sent, receive(t, received))
+
+	webhook, _ = newWebhookServer(t, http.StatusInternalServerError)
+	require.Error(t, webhook.Send(context.Background(), sent))
+}
+
+func TestNotifier(t *testing.T) {
+	webhook, received := newWebhookServer(t, http.StatusOK)
+	n := NewNotifier(Config{Webhook: webhook, Policy: Policy{Dedup: time.Minute}}, nil)
+	defer n.Close()
+
+	watched := make(chan Notification, 8)
+	unsubscribe := n.Subscribe(func(note Notification) { watched <- note })
+
+	now := time.Now()
+	require.True(t, n.Notify(Notification{Upstream: "game", Text: "attached", Time: now}, false))
+	require.Equal(t, "attached", receive(t, watched).Text)
+
+	require.True(t, n.Notify(Notification{Upstream: "game", Text: "idle", Time: now}, true))
+	require.Equal(t, "idle", receive(t, watched).Text)
+	require.Equal(t, "idle", receive(t, received).Text)
+
+	require.False(t, n.Notify(Notification{Upstream: "game", Text: "idle", Time: now.Add(30 * time.Second)}, true))
+	require.True(t, n.Notify(Notification{Upstream: "other", Text: "idle", Time: now.Add(30 * time.Second)}, true))
+	require.True(t, n.Notify(Notification{Upstream: "game", Text: "idle", Time: now.Add(time.Minute)}, true))
+	require.Equal(t, "other", receive(t, received).Upstream)
+	require.Equal(t, "game", receive(t, received).Upstream)
+	require.Equal(t, "other", receive(t, watched).Upstream)
+	require.Equal(t, "game", receive(t, watched).Upstream)
+
+	digest := n.TakeDigest("game")
+	require.Len(t, digest, 2)
+	require.Equal(t, "idle", digest[0].Text)
+	require.Empty(t, n.TakeDigest("game"))
+	require.Len(t, n.TakeDigest("other"), 1)
+
+	unsubscribe()
+	n.Configure(Config{Webhook: webhook, Policy: Policy{Quiet: QuietHours{start: 0, end: 24 * time.Hour}}})
+	require.True(t, n.Notify(Notification{Upstream: "game", Text: "quiet"}, true))
+	require.Len(t, n.TakeDigest("game"), 1)
+	require.Empty(t, watched)
+	select {
+	case note := <-received:
+		t.Fatalf("webhook sent during quiet hours: %v", note)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNotifierPolicies(t *testing.T) {
+	webhook, received := newWebhookServer(t, http.StatusOK)
+	n := NewNotifier(Config{
+		Webhook: webhook,
+		Policy:  Policy{Quiet: QuietHours{start: 0, end: 24 * time.Hour}},
+		Upstreams: map[string]Policy{
+			"loud": {Dedup: time.Minute},
+		},
+	}, nil)
+	defer n.Close()
+
+	now := time.Now()
+	require.True(t, n.Notify(Notification{Upstream: "game", Text: "hi", Time: now}, true))
+	require.True(t, n.Notify(Notification{Upstream: "game", Text: "hi", Time: now}, true))
+	require.True(t, n.Notify(Notification{Upstream: "loud", Text: "hi", Time: now}, true))
+	require.False(t, n.Notify(Notification{Upstream: "loud", Text: "hi", Time: now}, true))
+	require.Equal(t, "loud", receive(t, received).Upstream)
+	select {
+	case note := <-received:
+		t.Fatalf("webhook sent during quiet hours: %v", note)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNotifierErrors(t *testing.T) {
+	webhook, _ := newWebhookServer(t, http.StatusBadGateway)
+	errs := make(chan error, 1)
+	n := NewNotifier(Config{Webhook: webhook}, func(_ context.Context, _ event.Event, err error) {
+		if err != nil {
+			errs <- err
+		}
+	})
+	defer n.Close()
+	n.Notify(Notification{Upstream: "game", Text: "hi"}, true)
+	select {
+	case err := <-errs:
+		require.ErrorContains(t, err, "502")
+	case <-time.After(time.Second):
+		t.Fatal("no error")
+	}
+}
@@ -0,0 +1,173 @@
+// Package rewrite changes the lines of upstream output on their way to
+// clients and to the log.
+package rewrite
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stesla/iris/internal/logs"
+	"github.com/stesla/iris/internal/trigger"
+)
+
+type Action string
+
+const (
+	// ActionGag drops the line.
+	ActionGag Action = "gag"
+	// ActionSubstitute replaces each match with the argument, in which $1 or
+	// ${name} refer to capture groups.
+	ActionSubstitute Action = "substitute"
+	// ActionHighlight wraps each match in the ANSI style named by the argument.
+	ActionHighlight Action = "highlight"
+)
+
+func ParseAction(s string) (Action, error) {
+	switch a := Action(s); a {
+	case ActionGag, ActionSubstitute, ActionHighlight:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown rewrite action: %q", s)
+}
+
+// Rule rewrites the lines that Pattern matches.
+type Rule struct {
+	ID       int64
+	Upstream string
+	Pattern  string
+	Action   Action
+	Argument string
+	Log      bool
+}
+
+// Target is where a line is going.
+type Target int
+
+const (
+	// Downstream is a line sent to clients, to which every rule applies.
+	Downstream Target = iota
+	// Log is a line written to the log, to which the rules with Log set apply.
+	Log
+	// Replay is a line read back from the log for a client, to which the rules
+	// that were not applied to the log apply.
+	Replay
+)
+
+func (r Rule) appliesTo(target Target) bool {
+	switch target {
+	case Log:
+		return r.Log
+	case Replay:
+		return !r.Log
+	}
+	return true
+}
+
+type compiled struct {
+	Rule
+	re    *regexp.Regexp
+	style string
+}
+
+// Set is a compiled list of rules, which are applied in order.
+type Set struct {
+	rules []compiled
+}
+
+// Len returns how many rules are in the set.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rules)
+}
+
+func Compile(rules []Rule) (*Set, error) {
+	s := &Set{rules: make([]compiled, 0, len(rules))}
+	for _, r := range rules {
+		c, err := compile(r)
+		if err != nil {
+			return nil, err
+		}
+		s.rules = append(s.rules, c)
+	}
+	return s, nil
+}
+
+func compile(r Rule) (c compiled, err error) {
+	c.Rule = r
+	if _, err = ParseAction(string(r.Action)); err != nil {
+		return
+	}
+	if c.re, err = regexp.Compile(r.Pattern); err != nil {
+		return c, fmt.Errorf("rewrite pattern %q: %w", r.Pattern, err)
+	}
+	if r.Action == ActionHighlight {
+		c.style, err = trigger.Style(r.Argument)
+	}
+	return
+}
+
+// Validate reports whether r would compile.
+func Validate(r Rule) error {
+	_, err := compile(r)
+	return err
+}
+
+const reset = "\x1b[0m"
+
+// Apply rewrites line, which may end in a newline, for target. It returns
+// false if the line was gagged. Escape codes outside of a match are kept.
+func (s *Set) Apply(line string, target Target) (string, bool) {
+	if s == nil {
+		return line, true
+	}
+	text := strings.TrimRight(line, "\r\n")
+	eol := line[len(text):]
+	for _, r := range s.rules {
+		if !r.appliesTo(target) {
+			continue
+		}
+		var matched bool
+		if text, matched = r.rewrite(text); matched && r.Action == ActionGag {
+			return "", false
+		}
+	}
+	return text + eol, true
+}
+
+func (r compiled) rewrite(text string) (string, bool) {
+	plain, index := logs.StripANSIIndex(text)
+	matches := r.re.FindAllStringSubmatchIndex(plain, -1)
+	if matches == nil || r.Action == ActionGag {
+		return text, matches != nil
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := span(index, m[0], m[1], len(text))
+		b.WriteString(text[last:start])
+		switch r.Action {
+		case ActionSubstitute:
+			b.Write(r.re.ExpandString(nil, r.Argument, plain, m))
+		case ActionHighlight:
+			b.WriteString(r.style + text[start:end] + reset)
+		}
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String(), true
+}
+
+// span returns where the bytes from start to end of the plain text are in the
+// text, which has size bytes.
+func span(index []int, start, end, size int) (int, int) {
+	if start < end {
+		return index[start], index[end-1] + 1
+	}
+	if start < len(index) {
+		return index[start], index[start]
+	}
+	return size, size
+}
@@ -0,0 +1,66 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	set, err := Compile([]Rule{
+		{Pattern: `^\[Public\]`, Action: ActionGag},
+		{Pattern: `^\[OOC\]`, Action: ActionGag, Log: true},
+		{Pattern: `(\w+) pages: `, Action: ActionSubstitute, Argument: "[page] $1: "},
+		{Pattern: `Bob`, Action: ActionHighlight, Argument: "green"},
+		{Pattern: `secret: \S+`, Action: ActionSubstitute, Argument: "secret: ***", Log: true},
+	})
+	require.NoError(t, err)
+
+	var tests = []struct {
+		line   string
+		target Target
+		result string
+		ok     bool
+	}{
+		{"hello\r\n", Downstream, "hello\r\n", true},
+		{"[Public] Spam\r\n", Downstream, "", false},
+		{"[Public] Spam\r\n", Log, "[Public] Spam\r\n", true},
+		{"[Public] Spam\r\n", Replay, "", false},
+		{"[OOC] Chatter\n", Downstream, "", false},
+		{"[OOC] Chatter\n", Log, "", false},
+		{"[OOC] Chatter\n", Replay, "[OOC] Chatter\n", true},
+		{"Alice pages: hi\n", Downstream, "[page] Alice: hi\n", true},
+		{"Alice pages: hi\n", Log, "Alice pages: hi\n", true},
+		{"Bob and Bob\n", Downstream, "\x1b[32mBob\x1b[0m and \x1b[32mBob\x1b[0m\n", true},
+		{"B\x1b[1mob\x1b[0m!", Downstream, "\x1b[32mB\x1b[1mob\x1b[0m\x1b[0m!", true},
+		{"\x1b[1mAlice\x1b[0m pages: hi", Downstream, "\x1b[1m[page] Alice: hi", true},
+		{"\x1b[31mAlice\x1b[0m pages: \x1b[1mhi\x1b[0m", Downstream, "\x1b[31m[page] Alice: \x1b[1mhi\x1b[0m", true},
+		{"my secret: xyzzy\n", Log, "my secret: ***\n", true},
+		{"my secret: xyzzy\n", Replay, "my secret: xyzzy\n", true},
+	}
+	for _, test := range tests {
+		result, ok := set.Apply(test.line, test.target)
+		require.Equal(t, test.ok, ok, test.line)
+		require.Equal(t, test.result, result, test.line)
+	}
+}
+
+func TestNilSet(t *testing.T) {
+	var set *Set
+	result, ok := set.Apply("hello\n", Downstream)
+	require.True(t, ok)
+	require.Equal(t, "hello\n", result)
+}
+
+func TestCompileErrors(t *testing.T) {
+	var tests = []Rule{
+		{Pattern: `(`, Action: ActionGag},
+		{Pattern: `foo`, Action: "explode"},
+		{Pattern: `foo`, Action: ActionHighlight, Argument: "sparkly"},
+	}
+	for _, r := range tests {
+		require.Error(t, Validate(r), r.Pattern)
+		_, err := Compile([]Rule{r})
+		require.Error(t, err, r.Pattern)
+	}
+}
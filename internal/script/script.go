@@ -0,0 +1,525 @@
+// Package script runs Starlark scripts against an upstream session, which
+// they can only reach through these builtins:
+//
+//	send(text)                 send a line upstream
+//	expect(pattern, timeout=10)
+//	                           wait for a line matching pattern, and return its
+//	                           groups as a tuple, or None after timeout seconds
+//	on(pattern, fn)            call fn(groups) for every line matching pattern
+//	gmcp(package, data=None)   send a GMCP message, with data encoded as JSON
+//	on_gmcp(package, fn)       call fn(package, data) for every GMCP message in
+//	                           package, or in any package if it ends with "*"
+//	store.get(key, default=None), store.set(key, value), store.delete(key),
+//	store.keys()               a key-value store that lasts as long as the
+//	                           session
+//	json.encode, json.decode   from the Starlark json module
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+
+	"github.com/stesla/iris/internal/event"
+	"github.com/stesla/iris/internal/logs"
+)
+
+// Host is the session that scripts run against.
+type Host interface {
+	Send(line string) error
+	SendGMCP(pkg string, data []byte) error
+	Print(msg string)
+	// Error is called with the errors from handlers.
+	Error(err error)
+}
+
+const (
+	// maxSteps bounds the work one script, or one call to a handler, can do.
+	maxSteps         = 10_000_000
+	maxLines         = 1000
+	handlerQueueSize = 64
+	scriptQueueSize  = 64
+	scriptWorkers    = 4
+	defaultTimeout   = 10 * time.Second
+)
+
+// Scripts may use if and for outside of functions.
+var fileOptions = &syntax.FileOptions{
+	Set:             true,
+	GlobalReassign:  true,
+	TopLevelControl: true,
+}
+
+var (
+	// ErrSessionClosed is returned when the session closes while waiting.
+	ErrSessionClosed = errors.New("session closed")
+	// ErrBusy is returned by RunLater when too many scripts are waiting.
+	ErrBusy = errors.New("too many scripts waiting to run")
+)
+
+const (
+	eventLine event.Name = "script.line"
+	eventGMCP event.Name = "script.gmcp"
+)
+
+var (
+	topicLine = event.NewTopic[lineData](eventLine)
+	topicGMCP = event.NewTopic[gmcpMessage](eventGMCP)
+)
+
+// lineData is a line and the cursor for the line after it.
+type lineData struct {
+	text string
+	next int
+}
+
+type gmcpMessage struct {
+	pkg  string
+	data []byte
+	next int
+}
+
+// Runtime holds the state that scripts for one session share.
+type Runtime struct {
+	host       Host
+	ctx        context.Context
+	cancel     context.CancelFunc
+	dispatcher event.AsyncDispatcher
+	pending    chan pendingScript
+	workers    sync.WaitGroup
+
+	mux     sync.Mutex
+	lines   []string
+	base    int
+	changed chan struct{}
+	store   map[string]starlark.Value
+}
+
+func New(host Host) *Runtime {
+	r := &Runtime{
+		host:    host,
+		changed: make(chan struct{}),
+		store:   make(map[string]starlark.Value),
+		pending: make(chan pendingScript, scriptQueueSize),
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.dispatcher = event.NewAsyncDispatcher(handlerQueueSize, event.DropOldest, event.WithErrorHook(
+		func(_ context.Context, _ event.Event, err error) { host.Error(err) },
+	))
+	r.workers.Add(scriptWorkers)
+	for range scriptWorkers {
+		go r.work()
+	}
+	return r
+}
+
+// Close stops any running scripts and handlers.
+func (r *Runtime) Close() error {
+	r.cancel()
+	r.workers.Wait()
+	return r.dispatcher.Close()
+}
+
+// Check reports whether src would compile, given the names in vars.
+func Check(filename, src string, vars ...string) error {
+	predeclared := (&Runtime{}).builtins()
+	_, _, err := starlark.SourceProgramOptions(fileOptions, filename, src, func(name string) bool {
+		_, found := predeclared[name]
+		return found || slices.Contains(vars, name) || starlark.Universe.Has(name)
+	})
+	return err
+}
+
+// Run runs a script to completion in the calling goroutine, with vars
+// predeclared alongside the builtins.
+func (r *Runtime) Run(filename, src string, vars starlark.StringDict) error {
+	return r.run(filename, src, vars, r.cursor(), false)
+}
+
+// RunConnect runs a connect script, in which expect also sees the lines that
+// arrived before it started, and which may register handlers.
+func (r *Runtime) RunConnect(filename, src string, vars starlark.StringDict) error {
+	return r.run(filename, src, vars, 0, true)
+}
+
+type pendingScript struct {
+	filename, src string
+	vars          starlark.StringDict
+}
+
+// RunLater queues a script to be run by one of a few goroutines of the
+// Runtime's own, which report its errors to the host. It returns ErrBusy if
+// too many scripts are already waiting.
+func (r *Runtime) RunLater(filename, src string, vars starlark.StringDict) error {
+	select {
+	case <-r.ctx.Done():
+		return ErrSessionClosed
+	default:
+	}
+	select {
+	case r.pending <- pendingScript{filename, src, vars}:
+		return nil
+	default:
+		return ErrBusy
+	}
+}
+
+func (r *Runtime) work() {
+	defer r.workers.Done()
+	for {
+		select {
+		case p := <-r.pending:
+			if err := r.Run(p.filename, p.src, p.vars); err != nil {
+				r.host.Error(err)
+			}
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Runtime) run(filename, src string, vars starlark.StringDict, cursor int, handlers bool) error {
+	thread := r.newThread(filename, cursor)
+	thread.Local(stateKey).(*threadState).handlers = handlers
+	defer r.release(thread)
+	predeclared := r.builtins()
+	for name, value := range vars {
+		predeclared[name] = value
+	}
+	_, err := starlark.ExecFileOptions(fileOptions, thread, filename, src, predeclared)
+	return err
+}
+
+// Line passes a line from the upstream to expect and to the handlers.
+func (r *Runtime) Line(line string) {
+	line = logs.StripANSI(strings.TrimRight(line, "\r\n"))
+	r.mux.Lock()
+	r.lines = append(r.lines, line)
+	if len(r.lines) > maxLines {
+		drop := len(r.lines) - maxLines
+		r.lines = r.lines[drop:]
+		r.base += drop
+	}
+	close(r.changed)
+	r.changed = make(chan struct{})
+	next := r.base + len(r.lines)
+	r.mux.Unlock()
+	r.dispatcher.Dispatch(r.ctx, topicLine.Event(lineData{line, next}))
+}
+
+// GMCP passes a GMCP message from the upstream to the handlers.
+func (r *Runtime) GMCP(pkg string, data []byte) {
+	r.dispatcher.Dispatch(r.ctx, topicGMCP.Event(gmcpMessage{pkg, data, r.cursor()}))
+}
+
+type threadState struct {
+	cursor int
+	stop   func() bool
+	// handlers is whether the thread may call on and on_gmcp.
+	handlers bool
+}
+
+const stateKey = "iris.state"
+
+func (r *Runtime) newThread(name string, cursor int) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name:  name,
+		Print: func(_ *starlark.Thread, msg string) { r.host.Print(msg) },
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+	state := &threadState{cursor: cursor}
+	state.stop = context.AfterFunc(r.ctx, func() { thread.Cancel("session closed") })
+	thread.SetLocal(stateKey, state)
+	return thread
+}
+
+func (r *Runtime) release(thread *starlark.Thread) {
+	thread.Local(stateKey).(*threadState).stop()
+}
+
+func (r *Runtime) cursor() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.base + len(r.lines)
+}
+
+func (r *Runtime) builtins() starlark.StringDict {
+	return starlark.StringDict{
+		"send":    starlark.NewBuiltin("send", r.send),
+		"expect":  starlark.NewBuiltin("expect", r.expect),
+		"on":      starlark.NewBuiltin("on", r.on),
+		"gmcp":    starlark.NewBuiltin("gmcp", r.gmcp),
+		"on_gmcp": starlark.NewBuiltin("on_gmcp", r.onGMCP),
+		"json":    json.Module,
+		"store": &starlarkstruct.Module{
+			Name: "store",
+			Members: starlark.StringDict{
+				"get":    starlark.NewBuiltin("store.get", r.storeGet),
+				"set":    starlark.NewBuiltin("store.set", r.storeSet),
+				"delete": starlark.NewBuiltin("store.delete", r.storeDelete),
+				"keys":   starlark.NewBuiltin("store.keys", r.storeKeys),
+			},
+		},
+	}
+}
+
+func (r *Runtime) send(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var text string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &text); err != nil {
+		return nil, err
+	}
+	return starlark.None, r.host.Send(text)
+}
+
+func (r *Runtime) expect(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern string
+	var timeout starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pattern", &pattern, "timeout?", &timeout); err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	wait := defaultTimeout
+	if timeout != starlark.None {
+		seconds, ok := starlark.AsFloat(timeout)
+		if !ok {
+			return nil, fmt.Errorf("%s: timeout must be a number, not %s", b.Name(), timeout.Type())
+		}
+		wait = time.Duration(seconds * float64(time.Second))
+	}
+	state := thread.Local(stateKey).(*threadState)
+	line, m, err := r.wait(r.ctx, &state.cursor, re, wait)
+	if err != nil || m == nil {
+		return starlark.None, err
+	}
+	return groups(line, m), nil
+}
+
+// Cursor returns the position of the next line to arrive, for Expect.
+func (r *Runtime) Cursor() int {
+	return r.cursor()
+}
+
+// Expect is expect for callers outside of a script, which returns the line
+// and its groups, or ErrTimeout.
+func (r *Runtime) Expect(ctx context.Context, cursor int, re *regexp.Regexp, timeout time.Duration) (string, []string, error) {
+	line, m, err := r.wait(ctx, &cursor, re, timeout)
+	if err == nil && m == nil {
+		err = ErrTimeout
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	groups := make([]string, len(m)/2-1)
+	for i := range groups {
+		if start, end := m[2*i+2], m[2*i+3]; start >= 0 {
+			groups[i] = line[start:end]
+		}
+	}
+	return line, groups, nil
+}
+
+// wait returns the first line at or after cursor that matches re, and moves
+// cursor past it. If no line matches before timeout, m is nil.
+func (r *Runtime) wait(ctx context.Context, cursor *int, re *regexp.Regexp, timeout time.Duration) (line string, m []int, err error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		r.mux.Lock()
+		*cursor = max(*cursor, r.base)
+		for ; *cursor < r.base+len(r.lines); *cursor++ {
+			line := r.lines[*cursor-r.base]
+			if m := re.FindStringSubmatchIndex(line); m != nil {
+				*cursor++
+				r.mux.Unlock()
+				return line, m, nil
+			}
+		}
+		changed := r.changed
+		r.mux.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return "", nil, nil
+		case <-ctx.Done():
+			if r.ctx.Err() != nil {
+				return "", nil, ErrSessionClosed
+			}
+			return "", nil, ctx.Err()
+		case <-r.ctx.Done():
+			return "", nil, ErrSessionClosed
+		}
+	}
+}
+
+// groups turns a match into a tuple, with None for groups that did not take
+// part in it.
+func groups(line string, m []int) starlark.Tuple {
+	result := make(starlark.Tuple, len(m)/2)
+	for i := range result {
+		if start, end := m[2*i], m[2*i+1]; start < 0 {
+			result[i] = starlark.None
+		} else {
+			result[i] = starlark.String(line[start:end])
+		}
+	}
+	return result
+}
+
+func (r *Runtime) on(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := checkHandlers(thread, b); err != nil {
+		return nil, err
+	}
+	var pattern string
+	var fn starlark.Callable
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &pattern, &fn); err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	topicLine.Subscribe(r.dispatcher, func(_ context.Context, line lineData) error {
+		m := re.FindStringSubmatchIndex(line.text)
+		if m == nil {
+			return nil
+		}
+		return r.call(fn, line.next, groups(line.text, m))
+	})
+	return starlark.None, nil
+}
+
+func (r *Runtime) gmcp(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pkg string
+	var data starlark.Value = starlark.None
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &pkg, &data); err != nil {
+		return nil, err
+	}
+	var encoded []byte
+	if data != starlark.None {
+		v, err := starlark.Call(thread, json.Module.Members["encode"], starlark.Tuple{data}, nil)
+		if err != nil {
+			return nil, err
+		}
+		encoded = []byte(v.(starlark.String))
+	}
+	return starlark.None, r.host.SendGMCP(pkg, encoded)
+}
+
+func (r *Runtime) onGMCP(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := checkHandlers(thread, b); err != nil {
+		return nil, err
+	}
+	var pattern string
+	var fn starlark.Callable
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &pattern, &fn); err != nil {
+		return nil, err
+	}
+	topicGMCP.Subscribe(r.dispatcher, func(_ context.Context, msg gmcpMessage) error {
+		if !event.Name(pattern).Match(event.Name(msg.pkg)) {
+			return nil
+		}
+		return r.call(fn, msg.next, starlark.String(msg.pkg), starlark.Bytes(msg.data))
+	})
+	return starlark.None, nil
+}
+
+func checkHandlers(thread *starlark.Thread, b *starlark.Builtin) error {
+	if !thread.Local(stateKey).(*threadState).handlers {
+		return fmt.Errorf("%s: only a connect script can register handlers", b.Name())
+	}
+	return nil
+}
+
+// call runs a handler in a thread of its own.
+func (r *Runtime) call(fn starlark.Callable, cursor int, args ...starlark.Value) error {
+	thread := r.newThread(fn.Name(), cursor)
+	defer r.release(thread)
+	for i, arg := range args {
+		data, ok := arg.(starlark.Bytes)
+		if !ok {
+			continue
+		}
+		if len(data) == 0 {
+			args[i] = starlark.None
+			continue
+		}
+		v, err := starlark.Call(thread, json.Module.Members["decode"], starlark.Tuple{starlark.String(data)}, nil)
+		if err != nil {
+			return err
+		}
+		args[i] = v
+	}
+	_, err := starlark.Call(thread, fn, args, nil)
+	return err
+}
+
+func (r *Runtime) storeGet(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key string
+	var def starlark.Value = starlark.None
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &key, &def); err != nil {
+		return nil, err
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if v, found := r.store[key]; found {
+		return v, nil
+	}
+	return def, nil
+}
+
+func (r *Runtime) storeSet(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key string
+	var value starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &key, &value); err != nil {
+		return nil, err
+	}
+	// Values are shared between threads, so they cannot change once stored.
+	value.Freeze()
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.store[key] = value
+	return starlark.None, nil
+}
+
+func (r *Runtime) storeDelete(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &key); err != nil {
+		return nil, err
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	delete(r.store, key)
+	return starlark.None, nil
+}
+
+func (r *Runtime) storeKeys(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	keys := make([]string, 0, len(r.store))
+	for key := range r.store {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	result := make([]starlark.Value, len(keys))
+	for i, key := range keys {
+		result[i] = starlark.String(key)
+	}
+	return starlark.NewList(result), nil
+}
@@ -0,0 +1,271 @@
+package script
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.starlark.net/starlark"
+)
+
+type testHost struct {
+	mux    sync.Mutex
+	sent   chan string
+	gmcp   []string
+	output []string
+	errs   []error
+}
+
+func newTestHost() *testHost {
+	return &testHost{sent: make(chan string, 16)}
+}
+
+func (h *testHost) Send(line string) error {
+	h.sent <- line
+	return nil
+}
+
+func (h *testHost) SendGMCP(pkg string, data []byte) error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.gmcp = append(h.gmcp, pkg+" "+string(data))
+	return nil
+}
+
+func (h *testHost) Print(msg string) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.output = append(h.output, msg)
+}
+
+func (h *testHost) Error(err error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.errs = append(h.errs, err)
+}
+
+func (h *testHost) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case line := <-h.sent:
+		return line
+	case <-time.After(time.Second):
+		t.Fatal("nothing sent")
+		return ""
+	}
+}
+
+func TestConnectScript(t *testing.T) {
+	host := newTestHost()
+	r := New(host)
+	defer r.Close()
+
+	src := `
+send("connect " + login)
+m = expect("^Choose a character: (\\w+)(, (\\w+))?")
+send("play " + m[1])
+if m[3] == None:
+    send("only one")
+print(expect("never", timeout=0.01))
+`
+	done := make(chan error)
+	go func() {
+		done <- r.Run("connect.star", src, starlark.StringDict{"login": starlark.String("bob")})
+	}()
+	require.Equal(t, "connect bob", host.next(t))
+	r.Line("Welcome!\r\n")
+	r.Line("\x1b[1mChoose a character:\x1b[0m Alice\r\n")
+	require.Equal(t, "play Alice", host.next(t))
+	require.Equal(t, "only one", host.next(t))
+	require.NoError(t, <-done)
+	require.Equal(t, []string{"None"}, host.output)
+}
+
+func TestExpectSeesEarlierLines(t *testing.T) {
+	host := newTestHost()
+	r := New(host)
+	defer r.Close()
+
+	done := make(chan error)
+	go func() {
+		done <- r.Run("test.star", `
+send("go")
+expect("one", timeout=1)
+send(expect("two|three", timeout=1)[0])
+`, nil)
+	}()
+	require.Equal(t, "go", host.next(t))
+	r.Line("one\n")
+	r.Line("two\n")
+	r.Line("three\n")
+	require.Equal(t, "two", host.next(t))
+	require.NoError(t, <-done)
+}
+
+func TestExpect(t *testing.T) {
+	r := New(newTestHost())
+	defer r.Close()
+
+	r.Line("before\n")
+	cursor := r.Cursor()
+	r.Line("\x1b[1mYou have 3 new messages.\x1b[0m\r\n")
+	line, groups, err := r.Expect(context.Background(), cursor, regexp.MustCompile(`(\d+) new (mail)?`), time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "You have 3 new messages.", line)
+	require.Equal(t, []string{"3", ""}, groups)
+
+	_, _, err = r.Expect(context.Background(), cursor, regexp.MustCompile("before"), 10*time.Millisecond)
+	require.ErrorIs(t, err, ErrTimeout)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = r.Expect(ctx, r.Cursor(), regexp.MustCompile("after"), time.Second)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRunConnect(t *testing.T) {
+	host := newTestHost()
+	r := New(host)
+	defer r.Close()
+
+	r.Line("Welcome!\n")
+	r.Line("Login:\n")
+	require.NoError(t, r.RunConnect("connect.star", `
+expect("Welcome", timeout=0.01)
+send(expect("Log(in)", timeout=0.01)[1])
+`, nil))
+	require.Equal(t, "in", host.next(t))
+	require.Error(t, r.Run("test.star", `expect("Login", timeout=0.01)[0]`, nil))
+}
+
+func TestOn(t *testing.T) {
+	host := newTestHost()
+	r := New(host)
+	defer r.Close()
+
+	require.NoError(t, r.RunConnect("connect.star", `
+def page(m):
+    store.set("pages", store.get("pages", 0) + 1)
+    send("page %s=Away." % m[1])
+on("^(\\w+) pages: ", page)
+`, nil))
+	r.Line("Bob pages: hi\n")
+	require.Equal(t, "page Bob=Away.", host.next(t))
+	r.Line("Alice says hi\n")
+	r.Line("Alice pages: hi\n")
+	require.Equal(t, "page Alice=Away.", host.next(t))
+
+	require.NoError(t, r.Run("count.star", `send(str(store.get("pages")))`, nil))
+	require.Equal(t, "2", host.next(t))
+}
+
+func TestOnOnlyInConnect(t *testing.T) {
+	host := newTestHost()
+	r := New(host)
+	defer r.Close()
+
+	err := r.Run("trigger.star", `on("x", print)`, nil)
+	require.ErrorContains(t, err, "only a connect script")
+	err = r.Run("trigger.star", `on_gmcp("Char.*", print)`, nil)
+	require.ErrorContains(t, err, "only a connect script")
+
+	require.NoError(t, r.RunConnect("connect.star", `
+def nested(m):
+    on("y", print)
+on("x", nested)
+`, nil))
+	r.Line("x\n")
+	require.Eventually(t, func() bool {
+		host.mux.Lock()
+		defer host.mux.Unlock()
+		return len(host.errs) == 1
+	}, time.Second, time.Millisecond)
+	require.ErrorContains(t, host.errs[0], "only a connect script")
+}
+
+func TestRunLater(t *testing.T) {
+	host := newTestHost()
+	r := New(host)
+
+	require.NoError(t, r.RunLater("later.star", `send(match)`, starlark.StringDict{"match": starlark.String("hi")}))
+	require.Equal(t, "hi", host.next(t))
+
+	for range scriptWorkers {
+		require.NoError(t, r.RunLater("wait.star", `expect("never", timeout=60)`, nil))
+	}
+	time.Sleep(10 * time.Millisecond)
+	for range scriptQueueSize {
+		require.NoError(t, r.RunLater("queued.star", `send("queued")`, nil))
+	}
+	require.ErrorIs(t, r.RunLater("full.star", `send("full")`, nil), ErrBusy)
+
+	r.Close()
+	require.ErrorIs(t, r.RunLater("closed.star", `send("closed")`, nil), ErrSessionClosed)
+}
+
+func TestGMCP(t *testing.T) {
+	host := newTestHost()
+	r := New(host)
+	defer r.Close()
+
+	require.NoError(t, r.RunConnect("connect.star", `
+gmcp("Core.Hello", {"client": "iris"})
+gmcp("Core.Ping")
+def vitals(pkg, data):
+    send("%s %d" % (pkg, data["hp"]))
+on_gmcp("Char.*", vitals)
+`, nil))
+	require.Equal(t, []string{`Core.Hello {"client":"iris"}`, "Core.Ping "}, host.gmcp)
+
+	r.GMCP("Room.Info", []byte(`{}`))
+	r.GMCP("Char.Vitals", []byte(`{"hp": 10}`))
+	require.Equal(t, "Char.Vitals 10", host.next(t))
+}
+
+func TestStore(t *testing.T) {
+	host := newTestHost()
+	r := New(host)
+	defer r.Close()
+
+	require.NoError(t, r.Run("test.star", `
+store.set("b", [1, 2])
+store.set("a", "x")
+store.delete("missing")
+send(str(store.keys()))
+`, nil))
+	require.Equal(t, `["a", "b"]`, host.next(t))
+	err := r.Run("test.star", `store.get("b").append(3)`, nil)
+	require.ErrorContains(t, err, "frozen")
+}
+
+func TestLimits(t *testing.T) {
+	host := newTestHost()
+	r := New(host)
+
+	err := r.Run("loop.star", `
+for i in range(1000000000):
+    pass
+`, nil)
+	require.ErrorContains(t, err, "too many steps")
+
+	done := make(chan error)
+	go func() { done <- r.Run("wait.star", `expect("never", timeout=60)`, nil) }()
+	time.Sleep(10 * time.Millisecond)
+	r.Close()
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("script still running after Close")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	require.NoError(t, Check("ok.star", `send("hi " + match[1])`, "match"))
+	require.Error(t, Check("bad.star", `send("hi" + match[1])`))
+	require.Error(t, Check("bad.star", `send(`))
+	require.Error(t, Check("bad.star", `while True: pass`))
+}
@@ -0,0 +1,156 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Step is one line of a step script, which is one of
+//
+//	wait "PATTERN" [TIMEOUT]   wait for a line matching PATTERN
+//	send "TEXT"                send a line upstream
+//	sleep DURATION             pause
+//
+// Strings are quoted as in Go. Durations are seconds or Go durations.
+type Step struct {
+	// Line is the line number of the step in the script.
+	Line    int
+	Text    string
+	Op      string
+	Arg     string
+	Pattern *regexp.Regexp
+	Timeout time.Duration
+}
+
+func (s Step) String() string {
+	return fmt.Sprintf("step %d (%s)", s.Line, s.Text)
+}
+
+// StepError is the error from the step that failed.
+type StepError struct {
+	Step Step
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// ErrTimeout is returned when nothing matched in time.
+var ErrTimeout = errors.New("timed out")
+
+// ParseSteps parses a step script.
+func ParseSteps(src string) ([]Step, error) {
+	var steps []Step
+	for i, text := range strings.Split(src, "\n") {
+		text = strings.TrimSpace(text)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		step, err := parseStep(i+1, text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func parseStep(line int, text string) (step Step, err error) {
+	step = Step{Line: line, Text: text, Timeout: defaultTimeout}
+	op, rest, _ := strings.Cut(text, " ")
+	step.Op, rest = op, strings.TrimSpace(rest)
+	switch op {
+	case "send", "wait":
+		if step.Arg, rest, err = unquote(rest); err != nil {
+			return
+		}
+		if op == "send" {
+			break
+		}
+		if step.Pattern, err = regexp.Compile(step.Arg); err != nil {
+			return
+		}
+		if rest != "" {
+			step.Timeout, err = parseDuration(rest)
+			rest = ""
+		}
+	case "sleep":
+		step.Timeout, err = parseDuration(rest)
+		rest = ""
+	default:
+		return step, fmt.Errorf("unknown step: %q", op)
+	}
+	if err == nil && rest != "" {
+		err = fmt.Errorf("unexpected %q", rest)
+	}
+	return
+}
+
+func unquote(s string) (value, rest string, err error) {
+	quoted, err := strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", "", fmt.Errorf("expected a quoted string: %s", s)
+	}
+	value, err = strconv.Unquote(quoted)
+	return value, strings.TrimSpace(s[len(quoted):]), err
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %q", s)
+	}
+	return d, nil
+}
+
+// RunSteps runs a step script as a connect script, passing the text of each
+// send through expand if it is not nil. If a step fails, the error is a
+// *StepError.
+func (r *Runtime) RunSteps(steps []Step, expand func(string) string) error {
+	cursor := 0
+	for _, step := range steps {
+		if err := r.runStep(&cursor, step, expand); err != nil {
+			return &StepError{Step: step, Err: err}
+		}
+	}
+	return nil
+}
+
+func (r *Runtime) runStep(cursor *int, step Step, expand func(string) string) error {
+	switch step.Op {
+	case "send":
+		text := step.Arg
+		if expand != nil {
+			text = expand(text)
+		}
+		return r.host.Send(text)
+	case "wait":
+		_, m, err := r.wait(r.ctx, cursor, step.Pattern, step.Timeout)
+		if err == nil && m == nil {
+			err = ErrTimeout
+		}
+		return err
+	case "sleep":
+		timer := time.NewTimer(step.Timeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return nil
+		case <-r.ctx.Done():
+			return ErrSessionClosed
+		}
+	}
+	return fmt.Errorf("unknown step: %q", step.Op)
+}
@@ -0,0 +1,87 @@
+package script
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSteps(t *testing.T) {
+	steps, err := ParseSteps(`
+# skip the splash screen
+wait "Press ENTER" 5
+send ""
+wait ` + "`^\\d+\\) Login`" + ` 500ms
+sleep 0.25
+send "connect %LOGIN% %PASSWORD%"
+`)
+	require.NoError(t, err)
+	require.Len(t, steps, 5)
+	require.Equal(t, "wait", steps[0].Op)
+	require.Equal(t, 3, steps[0].Line)
+	require.Equal(t, 5*time.Second, steps[0].Timeout)
+	require.Equal(t, "", steps[1].Arg)
+	require.True(t, steps[2].Pattern.MatchString("1) Login"))
+	require.Equal(t, 500*time.Millisecond, steps[2].Timeout)
+	require.Equal(t, 250*time.Millisecond, steps[3].Timeout)
+	require.Equal(t, defaultTimeout, steps[4].Timeout)
+
+	for _, src := range []string{
+		`jump "x"`,
+		`send connect`,
+		`send "a" "b"`,
+		`wait "(" 1`,
+		`wait "x" soon`,
+		`sleep`,
+	} {
+		_, err := ParseSteps(src)
+		require.Error(t, err, src)
+	}
+}
+
+func TestRunSteps(t *testing.T) {
+	host := newTestHost()
+	r := New(host)
+	defer r.Close()
+
+	steps, err := ParseSteps(`
+wait "^Press ENTER"
+send ""
+wait "^Login:"
+send "connect %LOGIN%"
+wait "never" 0.01
+send "unreachable"
+`)
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		done <- r.RunSteps(steps, strings.NewReplacer("%LOGIN%", "bob").Replace)
+	}()
+	r.Line("Welcome to the splash screen\r\n")
+	r.Line("Press ENTER to continue\r\n")
+	require.Equal(t, "", host.next(t))
+	r.Line("Login: \r\n")
+	require.Equal(t, "connect bob", host.next(t))
+
+	err = <-done
+	var stepErr *StepError
+	require.ErrorAs(t, err, &stepErr)
+	require.Equal(t, 6, stepErr.Step.Line)
+	require.ErrorIs(t, err, ErrTimeout)
+	require.Equal(t, `step 6 (wait "never" 0.01): timed out`, err.Error())
+	require.Empty(t, host.sent)
+}
+
+func TestRunStepsClosed(t *testing.T) {
+	for _, src := range []string{`sleep 10`, `wait "never" 3600`} {
+		r := New(newTestHost())
+		steps, err := ParseSteps(src)
+		require.NoError(t, err)
+		done := make(chan error)
+		go func() { done <- r.RunSteps(steps, nil) }()
+		r.Close()
+		require.ErrorIs(t, <-done, ErrSessionClosed, src)
+	}
+}
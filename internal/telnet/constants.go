@@ -30,6 +30,7 @@ const (
 	TerminalType    = 24 // RFC 930
 	NAWS            = 31 // RFC 1073
 	EndOfRecord     = 25 // RFC 885
+	GMCP            = 201
 )
 
 const (
@@ -22,7 +22,8 @@ func setEncoding(ctx context.Context, enc encoding.Encoding) {
 }
 
 type TransmitBinaryHandler struct {
-	ctx context.Context
+	ctx      context.Context
+	listener event.Listener
 }
 
 func (h *TransmitBinaryHandler) Register(ctx context.Context) {
@@ -30,13 +31,13 @@ func (h *TransmitBinaryHandler) Register(ctx context.Context) {
 
 	getOption(ctx, TransmitBinary).Allow(true, true)
 
-	d := ctx.Value(KeyDispatcher).(event.Dispatcher)
-	d.Listen(EventOption, h)
+	d, _ := event.FromContext(ctx)
+	h.listener = TopicOption.Subscribe(d, h.handleOption)
 }
 
 func (h *TransmitBinaryHandler) Unregister() {
-	d := h.ctx.Value(KeyDispatcher).(event.Dispatcher)
-	d.RemoveListener(EventOption, h)
+	d, _ := event.FromContext(h.ctx)
+	d.RemoveListener(EventOption, h.listener)
 
 	opt := getOption(h.ctx, TransmitBinary)
 	opt.Allow(false, false)
@@ -46,26 +47,23 @@ func (h *TransmitBinaryHandler) Unregister() {
 	setEncoding(h.ctx, ASCII)
 }
 
-func (h *TransmitBinaryHandler) Listen(ctx context.Context, ev event.Event) error {
-	switch opt := ev.Data.(type) {
-	case OptionData:
-		switch opt.OptionState.Option() {
-		case TransmitBinary:
-			encodable := ctx.Value(KeyEncodable).(Encodable)
-			if opt.ResolvedUs {
-				if opt.EnabledForUs() {
-					encodable.SetWriteEncoding(encoding.Nop)
-				} else {
-					encodable.SetWriteEncoding(ASCII)
-				}
-
+func (h *TransmitBinaryHandler) handleOption(ctx context.Context, opt OptionData) error {
+	switch opt.OptionState.Option() {
+	case TransmitBinary:
+		encodable := ctx.Value(KeyEncodable).(Encodable)
+		if opt.ResolvedUs {
+			if opt.EnabledForUs() {
+				encodable.SetWriteEncoding(encoding.Nop)
+			} else {
+				encodable.SetWriteEncoding(ASCII)
 			}
-			if opt.ResolvedThem {
-				if opt.EnabledForThem() {
-					encodable.SetReadEncoding(encoding.Nop)
-				} else {
-					encodable.SetReadEncoding(ASCII)
-				}
+
+		}
+		if opt.ResolvedThem {
+			if opt.EnabledForThem() {
+				encodable.SetReadEncoding(encoding.Nop)
+			} else {
+				encodable.SetReadEncoding(ASCII)
 			}
 		}
 	}
@@ -79,6 +77,7 @@ type CharsetHandler struct {
 	ctx                context.Context
 	enc                encoding.Encoding
 	requestedEncodings []encoding.Encoding
+	listeners          map[event.Name]event.Listener
 }
 
 func (h *CharsetHandler) Register(ctx context.Context) {
@@ -86,11 +85,12 @@ func (h *CharsetHandler) Register(ctx context.Context) {
 
 	getOption(ctx, Charset).Allow(true, true)
 
-	d := ctx.Value(KeyDispatcher).(event.Dispatcher)
-	d.Listen(EventOption, h)
-	d.Listen(EventSubnegotiation, h)
-	d.Listen(EventCharsetAccepted, h)
-	d.Listen(EventCharsetRejected, h)
+	d, _ := event.FromContext(ctx)
+	h.listeners = map[event.Name]event.Listener{
+		EventOption:          TopicOption.Subscribe(d, h.handleOption),
+		EventSubnegotiation:  TopicSubnegotiation.Subscribe(d, h.handleSubnegotiation),
+		EventCharsetAccepted: TopicCharsetAccepted.Subscribe(d, h.handleCharsetAccepted),
+	}
 }
 
 func (h *CharsetHandler) RequestEncoding(encodings ...encoding.Encoding) error {
@@ -107,55 +107,57 @@ func (h *CharsetHandler) RequestEncoding(encodings ...encoding.Encoding) error {
 	}
 	output = append(output, IAC, SE)
 	h.requestedEncodings = encodings
-	return dispatch(h.ctx, event.Event{Name: EventSend, Data: output})
+	return TopicSend.Publish(h.ctx, output)
 }
 
 func (h *CharsetHandler) Unregister() {
 	getOption(h.ctx, Charset).Allow(false, false)
 
-	d := h.ctx.Value(KeyDispatcher).(event.Dispatcher)
-	d.RemoveListener(EventCharsetRejected, h)
-	d.RemoveListener(EventCharsetAccepted, h)
-	d.RemoveListener(EventSubnegotiation, h)
-	d.RemoveListener(EventOption, h)
+	d, _ := event.FromContext(h.ctx)
+	for name, l := range h.listeners {
+		d.RemoveListener(name, l)
+	}
 }
 
-func (h *CharsetHandler) Listen(ctx context.Context, ev event.Event) error {
-	switch t := ev.Data.(type) {
-	case CharsetData:
-		h.enc = t.Encoding
-		opt := getOption(ctx, TransmitBinary)
-		if h.shouldSetEncoding(opt) {
-			setEncoding(ctx, h.enc)
-		}
-	case OptionData:
-		switch t.Option() {
-		case TransmitBinary:
-			if h.enc != nil {
-				if h.shouldSetEncoding(t) {
-					setEncoding(ctx, h.enc)
-				} else {
-					setEncoding(ctx, ASCII)
-				}
+func (h *CharsetHandler) handleCharsetAccepted(ctx context.Context, data CharsetData) error {
+	h.enc = data.Encoding
+	opt := getOption(ctx, TransmitBinary)
+	if h.shouldSetEncoding(opt) {
+		setEncoding(ctx, h.enc)
+	}
+	return nil
+}
+
+func (h *CharsetHandler) handleOption(ctx context.Context, opt OptionData) error {
+	switch opt.Option() {
+	case TransmitBinary:
+		if h.enc != nil {
+			if h.shouldSetEncoding(opt) {
+				setEncoding(ctx, h.enc)
+			} else {
+				setEncoding(ctx, ASCII)
 			}
 		}
-	case Subnegotiation:
-		switch t.Opt {
-		case Charset:
-			if getOption(ctx, Charset).EnabledForUs() {
-				switch cmd, data := t.Data[0], t.Data[1:]; cmd {
-				case CharsetAccepted:
-					h.requestedEncodings = nil
-					enc := h.getEncoding(data)
-					dispatch(ctx, event.Event{Name: EventCharsetAccepted, Data: CharsetData{Encoding: enc}})
-				case CharsetRejected:
-					h.requestedEncodings = nil
-					dispatch(ctx, event.Event{Name: EventCharsetRejected})
-				case CharsetRequest:
-					return h.handleCharsetRequest(ctx, data)
-				case CharsetTTableIs:
-					dispatch(ctx, event.Event{Name: EventSend, Data: []byte{IAC, SB, Charset, CharsetTTableRejected, IAC, SE}})
-				}
+	}
+	return nil
+}
+
+func (h *CharsetHandler) handleSubnegotiation(ctx context.Context, sub Subnegotiation) error {
+	switch sub.Opt {
+	case Charset:
+		if getOption(ctx, Charset).EnabledForUs() && len(sub.Data) > 0 {
+			switch cmd, data := sub.Data[0], sub.Data[1:]; cmd {
+			case CharsetAccepted:
+				h.requestedEncodings = nil
+				enc := h.getEncoding(data)
+				TopicCharsetAccepted.Publish(ctx, CharsetData{Encoding: enc})
+			case CharsetRejected:
+				h.requestedEncodings = nil
+				TopicCharsetRejected.Publish(ctx, struct{}{})
+			case CharsetRequest:
+				return h.handleCharsetRequest(ctx, data)
+			case CharsetTTableIs:
+				TopicSend.Publish(ctx, []byte{IAC, SB, Charset, CharsetTTableRejected, IAC, SE})
 			}
 		}
 	}
@@ -171,7 +173,7 @@ func (h *CharsetHandler) shouldSetEncoding(opt OptionState) bool {
 
 func (h *CharsetHandler) handleCharsetRequest(ctx context.Context, data []byte) error {
 	reject := func() error {
-		return dispatch(ctx, event.Event{Name: EventSend, Data: []byte{IAC, SB, Charset, CharsetRejected, IAC, SE}})
+		return TopicSend.Publish(ctx, []byte{IAC, SB, Charset, CharsetRejected, IAC, SE})
 	}
 
 	var charset []byte
@@ -203,8 +205,8 @@ func (h *CharsetHandler) handleCharsetRequest(ctx context.Context, data []byte)
 		out := []byte{IAC, SB, Charset, CharsetAccepted}
 		out = append(out, charset...)
 		out = append(out, IAC, SE)
-		dispatch(ctx, event.Event{Name: EventSend, Data: out})
-		dispatch(ctx, event.Event{Name: EventCharsetAccepted, Data: CharsetData{Encoding: enc}})
+		TopicSend.Publish(ctx, out)
+		TopicCharsetAccepted.Publish(ctx, CharsetData{Encoding: enc})
 	}
 	return nil
 }
@@ -3,6 +3,7 @@ package telnet
 import (
 	"bytes"
 	"context"
+	"io"
 	"testing"
 	"unicode"
 	"unicode/utf8"
@@ -99,6 +100,50 @@ func TestTransmitBinary(t *testing.T) {
 	require.Equal(t, []byte{encoding.ASCIISub, encoding.ASCIISub, encoding.ASCIISub}, output.Bytes())
 }
 
+// chunkReader returns one chunk for each Read, as a connection would for data
+// that arrives separately.
+type chunkReader [][]byte
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(*r) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, (*r)[0])
+	*r = (*r)[1:]
+	return n, nil
+}
+
+func TestEncodingChangesBetweenReads(t *testing.T) {
+	var output bytes.Buffer
+	in := chunkReader{
+		{IAC, WILL, TransmitBinary},
+		{IAC, DO, TransmitBinary},
+		[]byte("w\xf6rld"),
+	}
+	tcp := &mockConn{Reader: &in, Writer: &output}
+	telnet := Wrap(context.Background(), tcp)
+	telnet.RegisterHandler(&TransmitBinaryHandler{})
+
+	data, err := io.ReadAll(telnet)
+	require.NoError(t, err)
+	require.Equal(t, []byte("w\xf6rld"), data)
+}
+
+func TestEncodingChangesWithinRead(t *testing.T) {
+	var output bytes.Buffer
+	in := chunkReader{
+		[]byte("hi\xf6"),
+		{'!', IAC, WILL, TransmitBinary, IAC, DO, TransmitBinary, 'w', 0xf6, 'r', 'l', 'd'},
+	}
+	tcp := &mockConn{Reader: &in, Writer: &output}
+	telnet := Wrap(context.Background(), tcp)
+	telnet.RegisterHandler(&TransmitBinaryHandler{})
+
+	data, err := io.ReadAll(telnet)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hi\uFFFD!w\xf6rld"), data)
+}
+
 func (h *CharsetHandler) reset() {
 	*h = CharsetHandler{ctx: h.ctx}
 }
@@ -107,9 +152,9 @@ func TestCharsetSubnegotiation(t *testing.T) {
 	options := NewOptionMap()
 	options.set(&optionState{opt: Charset, them: qYes, us: qYes})
 	dispatcher := event.NewDispatcher()
-	dispatcher.Listen(EventNegotation, options)
+	TopicNegotiation.Subscribe(dispatcher, options.Receive)
 	ctx := context.Background()
-	ctx = context.WithValue(ctx, KeyDispatcher, dispatcher)
+	ctx = event.NewContext(ctx, dispatcher)
 	ctx = context.WithValue(ctx, KeyOptionMap, options)
 
 	var charset CharsetHandler
@@ -179,7 +224,7 @@ func TestCharsetSubnegotiation(t *testing.T) {
 				require.True(t, charset.Waiting())
 			},
 			data:  []byte{CharsetRejected},
-			event: event.Event{Name: EventCharsetRejected},
+			event: TopicCharsetRejected.Event(struct{}{}),
 			assert: func() {
 				require.Nil(t, charset.requestedEncodings)
 				require.False(t, charset.Waiting())
@@ -274,7 +319,7 @@ func TestCharsetSetsEncoding(t *testing.T) {
 	})
 	encodable := &mockEncodable{t: t}
 	ctx := context.Background()
-	ctx = context.WithValue(ctx, KeyDispatcher, dispatcher)
+	ctx = event.NewContext(ctx, dispatcher)
 	ctx = context.WithValue(ctx, KeyOptionMap, options)
 	ctx = context.WithValue(ctx, KeyEncodable, encodable)
 	tests := []struct {
@@ -341,7 +386,7 @@ func TestCharsetRequestEncoding(t *testing.T) {
 	options := NewOptionMap()
 	options.set(&optionState{opt: Charset, them: qYes, us: qYes})
 	ctx := context.Background()
-	ctx = context.WithValue(ctx, KeyDispatcher, dispatcher)
+	ctx = event.NewContext(ctx, dispatcher)
 	ctx = context.WithValue(ctx, KeyOptionMap, options)
 
 	handler := &CharsetHandler{}
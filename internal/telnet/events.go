@@ -5,13 +5,45 @@ import (
 	"golang.org/x/text/encoding"
 )
 
+// EventAll matches every event dispatched by a telnet connection.
+const EventAll event.Name = "telnet.*"
+
+// ProtocolEvents match every telnet event except the raw data read and
+// written, which listeners such as loggers rarely want. Nothing copies the
+// data read unless something listens for EventReceive.
+var ProtocolEvents = []event.Name{
+	EventOption,
+	EventNegotation,
+	EventSubnegotiation,
+	EventEndOfRecord,
+	EventGoAhead,
+	"telnet.charset.*",
+	EventGMCP,
+	"telnet.naws.*",
+}
+
 const EventSend event.Name = "telnet.send-data"
 
+var TopicSend = event.NewTopic[[]byte](EventSend)
+
+// EventReceive carries the raw bytes read from the connection, before any
+// telnet decoding, so that a recording of it can be replayed.
+const EventReceive event.Name = "telnet.reader.receive"
+
+var TopicReceive = event.NewTopic[[]byte](EventReceive)
+
 const EventEndOfRecord event.Name = "telnet.end-of-record"
 const EventGoAhead event.Name = "telnet.go-ahead"
 
+var (
+	TopicEndOfRecord = event.NewTopic[struct{}](EventEndOfRecord)
+	TopicGoAhead     = event.NewTopic[struct{}](EventGoAhead)
+)
+
 const EventOption event.Name = "telnet.option"
 
+var TopicOption = event.NewTopic[OptionData](EventOption)
+
 type OptionData struct {
 	OptionState
 	ResolvedThem bool
@@ -20,6 +52,8 @@ type OptionData struct {
 
 const EventNegotation event.Name = "telnet.reader.negotiation"
 
+var TopicNegotiation = event.NewTopic[Negotiation](EventNegotation)
+
 type Negotiation struct {
 	Opt byte
 	Cmd byte
@@ -27,6 +61,8 @@ type Negotiation struct {
 
 const EventSubnegotiation event.Name = "telnet.reader.subnegotiation"
 
+var TopicSubnegotiation = event.NewTopic[Subnegotiation](EventSubnegotiation)
+
 type Subnegotiation struct {
 	Opt  byte
 	Data []byte
@@ -35,6 +71,22 @@ type Subnegotiation struct {
 const EventCharsetAccepted event.Name = "telnet.charset.accepted"
 const EventCharsetRejected event.Name = "telnet.charset.rejected"
 
+var (
+	TopicCharsetAccepted = event.NewTopic[CharsetData](EventCharsetAccepted)
+	TopicCharsetRejected = event.NewTopic[struct{}](EventCharsetRejected)
+)
+
 type CharsetData struct {
 	encoding.Encoding
 }
+
+const EventGMCP event.Name = "telnet.gmcp"
+
+var TopicGMCP = event.NewTopic[GMCPMessage](EventGMCP)
+
+// GMCPMessage is a Generic MUD Communication Protocol message, such as
+// "Char.Vitals" with a JSON body. Data is empty for messages without one.
+type GMCPMessage struct {
+	Package string
+	Data    []byte
+}
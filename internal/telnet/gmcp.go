@@ -0,0 +1,60 @@
+package telnet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/stesla/iris/internal/event"
+)
+
+// GMCPHandler lets the server enable GMCP and dispatches the messages it
+// sends as EventGMCP.
+type GMCPHandler struct {
+	ctx      context.Context
+	listener event.Listener
+}
+
+func (h *GMCPHandler) Register(ctx context.Context) {
+	h.ctx = ctx
+
+	getOption(ctx, GMCP).Allow(true, false)
+
+	d, _ := event.FromContext(ctx)
+	h.listener = TopicSubnegotiation.Subscribe(d, h.handleSubnegotiation)
+}
+
+func (h *GMCPHandler) Unregister() {
+	d, _ := event.FromContext(h.ctx)
+	d.RemoveListener(EventSubnegotiation, h.listener)
+
+	opt := getOption(h.ctx, GMCP)
+	opt.Allow(false, false)
+	opt.DisableThem(h.ctx)
+}
+
+func (h *GMCPHandler) handleSubnegotiation(ctx context.Context, sub Subnegotiation) error {
+	if sub.Opt != GMCP || !getOption(ctx, GMCP).EnabledForThem() {
+		return nil
+	}
+	pkg, data, _ := bytes.Cut(sub.Data, []byte{' '})
+	return TopicGMCP.Publish(ctx, GMCPMessage{
+		Package: string(pkg),
+		Data:    bytes.Clone(bytes.TrimSpace(data)),
+	})
+}
+
+// Send sends a GMCP message to the server, once it has enabled GMCP.
+func (h *GMCPHandler) Send(msg GMCPMessage) error {
+	if !getOption(h.ctx, GMCP).EnabledForThem() {
+		return errors.New("gmcp option not enabled")
+	}
+	out := []byte{IAC, SB, GMCP}
+	out = append(out, msg.Package...)
+	if len(msg.Data) > 0 {
+		out = append(out, ' ')
+		out = append(out, bytes.ReplaceAll(msg.Data, []byte{IAC}, []byte{IAC, IAC})...)
+	}
+	out = append(out, IAC, SE)
+	return TopicSend.Publish(h.ctx, out)
+}
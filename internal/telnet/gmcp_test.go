@@ -0,0 +1,49 @@
+package telnet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGMCP(t *testing.T) {
+	var output bytes.Buffer
+	var in []byte
+	in = append(in, IAC, SB, GMCP)
+	in = append(in, "Core.Hello {}"...)
+	in = append(in, IAC, SE, IAC, WILL, GMCP, IAC, SB, GMCP)
+	in = append(in, `Char.Vitals {"hp": 10}`...)
+	in = append(in, IAC, SE, IAC, SB, GMCP)
+	in = append(in, "Core.Ping"...)
+	in = append(in, IAC, SE)
+	tcp := &mockConn{Reader: bytes.NewReader(in), Writer: &output}
+	telnet := Wrap(context.Background(), tcp)
+
+	handler := &GMCPHandler{}
+	telnet.RegisterHandler(handler)
+	require.Error(t, handler.Send(GMCPMessage{Package: "Core.Hello"}))
+	var received []GMCPMessage
+	TopicGMCP.Subscribe(telnet, func(_ context.Context, msg GMCPMessage) error {
+		received = append(received, msg)
+		return nil
+	})
+
+	_, err := io.ReadAll(telnet)
+	require.NoError(t, err)
+	require.Equal(t, []GMCPMessage{
+		{Package: "Char.Vitals", Data: []byte(`{"hp": 10}`)},
+		{Package: "Core.Ping"},
+	}, received)
+	require.Equal(t, []byte{IAC, DO, GMCP}, output.Bytes())
+
+	output.Reset()
+	require.NoError(t, handler.Send(GMCPMessage{Package: "Core.Hello", Data: []byte{'"', IAC, '"'}}))
+	require.Equal(t, []byte{IAC, SB, GMCP, 'C', 'o', 'r', 'e', '.', 'H', 'e', 'l', 'l', 'o', ' ', '"', IAC, IAC, '"', IAC, SE}, output.Bytes())
+
+	output.Reset()
+	handler.Unregister()
+	require.Equal(t, []byte{IAC, DONT, GMCP}, output.Bytes())
+}
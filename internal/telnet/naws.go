@@ -0,0 +1,102 @@
+package telnet
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+
+	"github.com/stesla/iris/internal/event"
+)
+
+const EventWindowSize event.Name = "telnet.naws.window-size"
+
+var TopicWindowSize = event.NewTopic[WindowSize](EventWindowSize)
+
+// WindowSize is the size of the client's window, in characters. Either may be
+// zero if the client does not know it.
+type WindowSize struct {
+	Width, Height uint16
+}
+
+// NAWSHandler negotiates the size of the client's window (RFC 1073). A client
+// accepts NAWS and sends the size given to SetSize once it is enabled. A server
+// accepts NAWS and dispatches each size the client sends as EventWindowSize.
+type NAWSHandler struct {
+	IsServer bool
+
+	ctx      context.Context
+	listener event.Listener
+	mux      sync.Mutex
+	size     WindowSize
+}
+
+func (h *NAWSHandler) Register(ctx context.Context) {
+	h.ctx = ctx
+
+	d, _ := event.FromContext(ctx)
+	if h.IsServer {
+		getOption(ctx, NAWS).Allow(true, false)
+		h.listener = TopicSubnegotiation.Subscribe(d, h.handleSubnegotiation)
+	} else {
+		getOption(ctx, NAWS).Allow(false, true)
+		h.listener = TopicOption.Subscribe(d, h.handleOption)
+	}
+}
+
+func (h *NAWSHandler) Unregister() {
+	d, _ := event.FromContext(h.ctx)
+	opt := getOption(h.ctx, NAWS)
+	opt.Allow(false, false)
+	if h.IsServer {
+		d.RemoveListener(EventSubnegotiation, h.listener)
+		opt.DisableThem(h.ctx)
+	} else {
+		d.RemoveListener(EventOption, h.listener)
+		opt.DisableUs(h.ctx)
+	}
+}
+
+func (h *NAWSHandler) handleOption(ctx context.Context, opt OptionData) error {
+	if opt.Option() != NAWS || !opt.ResolvedUs || !opt.EnabledForUs() {
+		return nil
+	}
+	h.mux.Lock()
+	size := h.size
+	h.mux.Unlock()
+	if size == (WindowSize{}) {
+		return nil
+	}
+	return h.send(size)
+}
+
+func (h *NAWSHandler) handleSubnegotiation(ctx context.Context, sub Subnegotiation) error {
+	if sub.Opt != NAWS || !getOption(ctx, NAWS).EnabledForThem() || len(sub.Data) != 4 {
+		return nil
+	}
+	return TopicWindowSize.Publish(ctx, WindowSize{
+		Width:  binary.BigEndian.Uint16(sub.Data[0:2]),
+		Height: binary.BigEndian.Uint16(sub.Data[2:4]),
+	})
+}
+
+// SetSize sets the size of the client's window, and sends it to the server if
+// it has enabled NAWS.
+func (h *NAWSHandler) SetSize(size WindowSize) error {
+	h.mux.Lock()
+	h.size = size
+	h.mux.Unlock()
+	if !getOption(h.ctx, NAWS).EnabledForUs() {
+		return nil
+	}
+	return h.send(size)
+}
+
+func (h *NAWSHandler) send(size WindowSize) error {
+	data := binary.BigEndian.AppendUint16(nil, size.Width)
+	data = binary.BigEndian.AppendUint16(data, size.Height)
+	out := []byte{IAC, SB, NAWS}
+	out = append(out, bytes.ReplaceAll(data, []byte{IAC}, []byte{IAC, IAC})...)
+	out = append(out, IAC, SE)
+	return TopicSend.Publish(h.ctx, out)
+}
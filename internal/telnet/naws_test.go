@@ -0,0 +1,57 @@
+package telnet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNAWSServer(t *testing.T) {
+	var output bytes.Buffer
+	var in []byte
+	in = append(in, IAC, SB, NAWS, 0, 80, 0, 24, IAC, SE)
+	in = append(in, IAC, WILL, NAWS)
+	in = append(in, IAC, SB, NAWS, 0, 132, 0, IAC, IAC, IAC, SE)
+	in = append(in, IAC, SB, NAWS, 0, 80, IAC, SE)
+	tcp := &mockConn{Reader: bytes.NewReader(in), Writer: &output}
+	telnet := Wrap(context.Background(), tcp)
+
+	handler := &NAWSHandler{IsServer: true}
+	telnet.RegisterHandler(handler)
+	var received []WindowSize
+	TopicWindowSize.Subscribe(telnet, func(_ context.Context, size WindowSize) error {
+		received = append(received, size)
+		return nil
+	})
+
+	_, err := io.ReadAll(telnet)
+	require.NoError(t, err)
+	require.Equal(t, []WindowSize{{Width: 132, Height: 255}}, received)
+	require.Equal(t, []byte{IAC, DO, NAWS}, output.Bytes())
+
+	output.Reset()
+	handler.Unregister()
+	require.Equal(t, []byte{IAC, DONT, NAWS}, output.Bytes())
+}
+
+func TestNAWSClient(t *testing.T) {
+	var output bytes.Buffer
+	tcp := &mockConn{Reader: bytes.NewReader([]byte{IAC, DO, NAWS}), Writer: &output}
+	telnet := Wrap(context.Background(), tcp)
+
+	handler := &NAWSHandler{}
+	telnet.RegisterHandler(handler)
+	require.NoError(t, handler.SetSize(WindowSize{Width: 80, Height: 24}))
+	require.Empty(t, output.Bytes())
+
+	_, err := io.ReadAll(telnet)
+	require.NoError(t, err)
+	require.Equal(t, []byte{IAC, WILL, NAWS, IAC, SB, NAWS, 0, 80, 0, 24, IAC, SE}, output.Bytes())
+
+	output.Reset()
+	require.NoError(t, handler.SetSize(WindowSize{Width: 255, Height: 50}))
+	require.Equal(t, []byte{IAC, SB, NAWS, 0, IAC, IAC, 0, 50, IAC, SE}, output.Bytes())
+}
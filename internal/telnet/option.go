@@ -3,8 +3,6 @@ package telnet
 import (
 	"context"
 	"math"
-
-	"github.com/stesla/iris/internal/event"
 )
 
 type OptionState interface {
@@ -26,8 +24,8 @@ type OptionState interface {
 }
 
 type OptionMap interface {
-	event.Listener
 	Get(opt byte) OptionState
+	Receive(ctx context.Context, negotiation Negotiation) error
 	set(OptionState)
 }
 
@@ -49,8 +47,7 @@ func (m *optionMap) Get(opt byte) OptionState {
 	return m.m[opt]
 }
 
-func (m *optionMap) Listen(ctx context.Context, ev event.Event) error {
-	negotiation := ev.Data.(Negotiation)
+func (m *optionMap) Receive(ctx context.Context, negotiation Negotiation) error {
 	opt := m.m[negotiation.Opt]
 	opt.receive(ctx, negotiation.Cmd)
 	return nil
@@ -142,7 +139,7 @@ func (o *optionState) disable(ctx context.Context, state *qState, b byte) {
 		// ignore
 	case qYes:
 		*state = qWantNoEmpty
-		dispatch(ctx, event.Event{Name: EventSend, Data: o.sendCmd(b)})
+		TopicSend.Publish(ctx, o.sendCmd(b))
 	case qWantNoEmpty:
 		// ignore
 	case qWantNoOpposite:
@@ -158,7 +155,7 @@ func (o *optionState) enable(ctx context.Context, state *qState, b byte) {
 	switch *state {
 	case qNo:
 		*state = qWantYesEmpty
-		dispatch(ctx, event.Event{Name: EventSend, Data: o.sendCmd(b)})
+		TopicSend.Publish(ctx, o.sendCmd(b))
 	case qYes:
 		// ignore
 	case qWantNoEmpty:
@@ -196,9 +193,9 @@ func (o *optionState) receive(ctx context.Context, b byte) {
 		case qNo:
 			if *allow {
 				*state = qYes
-				dispatch(ctx, event.Event{Name: EventSend, Data: o.sendCmd(accept)})
+				TopicSend.Publish(ctx, o.sendCmd(accept))
 			} else {
-				dispatch(ctx, event.Event{Name: EventSend, Data: o.sendCmd(reject)})
+				TopicSend.Publish(ctx, o.sendCmd(reject))
 			}
 		case qYes:
 			// ignore
@@ -210,7 +207,7 @@ func (o *optionState) receive(ctx context.Context, b byte) {
 			*state = qYes
 		case qWantYesOpposite:
 			*state = qWantNoEmpty
-			dispatch(ctx, event.Event{Name: EventSend, Data: o.sendCmd(reject)})
+			TopicSend.Publish(ctx, o.sendCmd(reject))
 		}
 	case DONT, WONT:
 		switch *state {
@@ -218,12 +215,12 @@ func (o *optionState) receive(ctx context.Context, b byte) {
 			// ignore
 		case qYes:
 			*state = qNo
-			dispatch(ctx, event.Event{Name: EventSend, Data: o.sendCmd(reject)})
+			TopicSend.Publish(ctx, o.sendCmd(reject))
 		case qWantNoEmpty:
 			*state = qNo
 		case qWantNoOpposite:
 			*state = qWantYesEmpty
-			dispatch(ctx, event.Event{Name: EventSend, Data: o.sendCmd(accept)})
+			TopicSend.Publish(ctx, o.sendCmd(accept))
 		case qWantYesEmpty:
 			*state = qNo
 		case qWantYesOpposite:
@@ -233,15 +230,15 @@ func (o *optionState) receive(ctx context.Context, b byte) {
 	resolvedThem := themBefore != o.them && o.them <= qYes
 	resolvedUs := usBefore != o.us && o.us <= qYes
 	if resolvedThem || resolvedUs {
-		dispatch(ctx, event.Event{Name: EventOption, Data: OptionData{
+		TopicOption.Publish(ctx, OptionData{
 			OptionState:  o,
 			ResolvedThem: resolvedThem,
 			ResolvedUs:   resolvedUs,
-		}})
+		})
 	}
 
 }
 
-func (o *optionState) sendCmd(b byte) any {
+func (o *optionState) sendCmd(b byte) []byte {
 	return []byte{IAC, b, o.opt}
 }
@@ -53,7 +53,7 @@ func TestOptionStateReceive(t *testing.T) {
 			eventReceived = ev.Data
 			return nil
 		})
-		ctx := context.WithValue(context.Background(), KeyDispatcher, d)
+		ctx := event.NewContext(context.Background(), d)
 		state := test.start
 		state.opt = Echo
 		expected := test.end
@@ -76,7 +76,7 @@ func TestOptionEnableOrDisable(t *testing.T) {
 		eventReceived = ev.Data
 		return nil
 	})
-	ctx := context.WithValue(context.Background(), KeyDispatcher, d)
+	ctx := event.NewContext(context.Background(), d)
 
 	disableThem := func(os *optionState) { os.DisableThem(ctx) }
 	disableUs := func(os *optionState) { os.DisableUs(ctx) }
@@ -170,7 +170,7 @@ func TestOptionMapHandleNegotiation(t *testing.T) {
 		actual = ev.Data.([]byte)
 		return nil
 	})
-	ctx := context.WithValue(context.Background(), KeyDispatcher, d)
+	ctx := event.NewContext(context.Background(), d)
 	m := NewOptionMap()
 	m.Get(Echo).Allow(true, true)
 	var tests = []struct {
@@ -184,7 +184,7 @@ func TestOptionMapHandleNegotiation(t *testing.T) {
 	}
 	for _, test := range tests {
 		actual = nil
-		m.Listen(ctx, event.Event{Name: EventNegotation, Data: test.data})
+		m.Receive(ctx, test.data)
 		require.Equal(t, test.expected, actual)
 	}
 }
@@ -196,7 +196,7 @@ func TestOptionEvent(t *testing.T) {
 		actual = ev.Data.(OptionData)
 		return nil
 	})
-	ctx := context.WithValue(context.Background(), KeyDispatcher, d)
+	ctx := event.NewContext(context.Background(), d)
 	var tests = []struct {
 		state    optionState
 		cmd      byte
@@ -0,0 +1,93 @@
+package telnet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/stesla/iris/internal/event"
+)
+
+// Replayer feeds the raw bytes in a recording made by an event.Recorder back
+// through a Conn over a net.Pipe, so a captured session can be used as a test.
+// Each chunk is only written once the Conn has read all of the one before it,
+// and the pipe is closed after the last, so reading the Conn to io.EOF replays
+// the whole session.
+type Replayer struct {
+	Conn
+
+	peer net.Conn
+	sent bytes.Buffer
+	done chan struct{}
+}
+
+func NewReplayer(ctx context.Context, r io.Reader) (*Replayer, error) {
+	records, err := event.ReadRecords(r)
+	if err != nil {
+		return nil, err
+	}
+	var chunks [][]byte
+	for i, rec := range records {
+		if rec.Name != EventReceive {
+			continue
+		}
+		var data []byte
+		if err := json.Unmarshal(rec.Data, &data); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		if len(data) > 0 {
+			chunks = append(chunks, data)
+		}
+	}
+	local, peer := net.Pipe()
+	rp := &Replayer{peer: peer, done: make(chan struct{})}
+	rp.Conn = Wrap(ctx, &replayConn{Conn: local, peer: peer, chunks: chunks})
+	go rp.drain()
+	return rp, nil
+}
+
+// Sent returns everything the Conn wrote back to the recorded peer. It blocks
+// until the replay has finished.
+func (r *Replayer) Sent() []byte {
+	<-r.done
+	return r.sent.Bytes()
+}
+
+func (r *Replayer) Close() error {
+	r.peer.Close()
+	return r.Conn.Close()
+}
+
+func (r *Replayer) drain() {
+	defer close(r.done)
+	io.Copy(&r.sent, r.peer)
+}
+
+// replayConn writes the next chunk to the pipe when a Read finds the last one
+// used up. The telnet reader only reads again once it has decoded everything
+// it read before, so anything it sends in reply has been sent by then.
+type replayConn struct {
+	net.Conn
+	peer    net.Conn
+	chunks  [][]byte
+	pending int
+}
+
+func (c *replayConn) Read(p []byte) (int, error) {
+	if c.pending == 0 {
+		if len(c.chunks) == 0 {
+			c.peer.Close()
+		} else {
+			chunk := c.chunks[0]
+			c.chunks = c.chunks[1:]
+			c.pending = len(chunk)
+			go c.peer.Write(chunk)
+		}
+	}
+	n, err := c.Conn.Read(p)
+	c.pending -= n
+	return n, err
+}
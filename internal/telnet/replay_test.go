@@ -0,0 +1,62 @@
+package telnet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stesla/iris/internal/event"
+	"github.com/stretchr/testify/require"
+	unicoding "golang.org/x/text/encoding/unicode"
+)
+
+func TestReplayCharset(t *testing.T) {
+	f, err := os.Open("testdata/charset.jsonl")
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, err := NewReplayer(context.Background(), f)
+	require.NoError(t, err)
+	defer r.Close()
+	r.RegisterHandler(&CharsetHandler{AllowWithoutTransmitBinary: true})
+	var accepted []CharsetData
+	TopicCharsetAccepted.Subscribe(r, func(_ context.Context, data CharsetData) error {
+		accepted = append(accepted, data)
+		return nil
+	})
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", string(data))
+	require.Equal(t, []CharsetData{{Encoding: unicoding.UTF8}}, accepted)
+	expected := []byte{IAC, WILL, Charset}
+	expected = append(expected, IAC, SB, Charset, CharsetAccepted, 'U', 'T', 'F', '-', '8', IAC, SE)
+	require.Equal(t, expected, r.Sent())
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	in := [][]byte{
+		[]byte("hello\r"),
+		{'\n', IAC, DO, Echo},
+		[]byte("world"),
+	}
+	var recording bytes.Buffer
+	recorder := event.NewRecorder(&recording)
+	for _, chunk := range in {
+		telnet := Wrap(context.Background(), &mockConn{Reader: bytes.NewReader(chunk), Writer: io.Discard})
+		recorder.Attach(telnet)
+		_, err := io.ReadAll(telnet)
+		require.NoError(t, err)
+		recorder.Detach()
+	}
+
+	r, err := NewReplayer(context.Background(), &recording)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "hello\nworld", string(data))
+	require.Equal(t, []byte{IAC, WONT, Echo}, r.Sent())
+}
@@ -1,9 +1,12 @@
 package telnet
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net"
+	"sync/atomic"
 
 	"github.com/stesla/iris/internal/event"
 	"golang.org/x/text/encoding"
@@ -17,6 +20,7 @@ type Conn interface {
 	Context() context.Context
 	GetOption(byte) OptionState
 	RegisterHandler(Handler)
+	SetErrorHandler(ErrorHandler)
 	SendGoAhead() error
 	SendEndOfRecord() error
 	SuppressGoAhead(bool)
@@ -27,8 +31,10 @@ type conn struct {
 	event.Dispatcher
 
 	ctx             context.Context
+	onError         ErrorHandler
 	options         OptionMap
 	readNoEnc       *reader
+	readSource      *decoderSource
 	read            io.Reader
 	suppressGoAhead bool
 	writeNoEnc      *writer
@@ -42,38 +48,57 @@ func Wrap(ctx context.Context, c net.Conn) Conn {
 type contextKey int
 
 const (
-	KeyDispatcher contextKey = 0 + iota
-	KeyOptionMap
+	KeyOptionMap contextKey = 0 + iota
 	KeyEncodable
 )
 
 func dispatch(ctx context.Context, ev event.Event) error {
-	d := ctx.Value(KeyDispatcher).(event.Dispatcher)
+	d, ok := event.FromContext(ctx)
+	if !ok {
+		return event.ErrNoDispatcher
+	}
 	return d.Dispatch(ctx, ev)
 }
 
+func listening(ctx context.Context, name event.Name) bool {
+	d, ok := event.FromContext(ctx)
+	return ok && d.Listening(name)
+}
+
 func getOption(ctx context.Context, opt byte) OptionState {
 	options := ctx.Value(KeyOptionMap).(OptionMap)
 	return options.Get(opt)
 }
 
+// ErrorHandler is called with the errors returned by listeners for the
+// events dispatched while reading. If it returns true the error is fatal, and
+// Read returns it.
+type ErrorHandler func(ctx context.Context, ev event.Event, err error) (fatal bool)
+
+// DefaultErrorHandler treats a panic in a listener as fatal, because the
+// state of the connection can no longer be trusted, and ignores other errors.
+func DefaultErrorHandler(_ context.Context, _ event.Event, err error) bool {
+	return event.IsPanic(err)
+}
+
 func wrap(ctx context.Context, c net.Conn) *conn {
-	dispatcher := event.NewDispatcher()
+	dispatcher := event.NewDispatcher(event.JoinErrors())
 	options := NewOptionMap()
 	cc := &conn{
 		Conn:       c,
 		Dispatcher: dispatcher,
+		onError:    DefaultErrorHandler,
 		options:    options,
 		ctx:        ctx,
 	}
-	cc.ctx = context.WithValue(cc.ctx, KeyDispatcher, dispatcher)
+	cc.ctx = event.NewContext(cc.ctx, dispatcher)
 	cc.ctx = context.WithValue(cc.ctx, KeyOptionMap, options)
 	cc.ctx = context.WithValue(cc.ctx, KeyEncodable, cc)
-	cc.readNoEnc = &reader{in: c, ctx: cc.ctx}
+	cc.readNoEnc = &reader{in: c, ctx: cc.ctx, onError: cc.handleError}
 	cc.writeNoEnc = &writer{out: c, ctx: cc.ctx}
 	setEncoding(cc.ctx, ASCII)
-	dispatcher.Listen(EventNegotation, options)
-	dispatcher.ListenFunc(EventSend, cc.handleSend)
+	TopicNegotiation.Subscribe(dispatcher, options.Receive)
+	TopicSend.Subscribe(dispatcher, cc.handleSend)
 	return cc
 }
 
@@ -88,15 +113,22 @@ const (
 	decodeOptionNegotation
 )
 
-func (c *conn) handleSend(_ context.Context, ev event.Event) error {
-	_, err := c.Conn.Write(ev.Data.([]byte))
+func (c *conn) handleSend(_ context.Context, data []byte) error {
+	_, err := c.Conn.Write(data)
 	return err
 }
 
 func (c *conn) Context() context.Context { return c.ctx }
 
 func (c *conn) Read(p []byte) (n int, err error) {
-	return c.read.Read(p)
+	for {
+		n, err = c.read.Read(p)
+		if err != errStaleDecoder {
+			return
+		} else if n > 0 {
+			return n, nil
+		}
+	}
 }
 
 type Handler interface {
@@ -112,6 +144,14 @@ func (c *conn) RegisterHandler(h Handler) {
 	h.Register(c.ctx)
 }
 
+func (c *conn) SetErrorHandler(h ErrorHandler) {
+	c.onError = h
+}
+
+func (c *conn) handleError(ev event.Event, err error) bool {
+	return c.onError(c.ctx, ev, err)
+}
+
 func (c *conn) SendGoAhead() error {
 	if !(c.suppressGoAhead || c.GetOption(SuppressGoAhead).EnabledForUs()) {
 		if _, err := c.Conn.Write([]byte{IAC, GA}); err != nil {
@@ -131,7 +171,33 @@ func (c *conn) SendEndOfRecord() error {
 }
 
 func (c *conn) SetReadEncoding(enc encoding.Encoding) {
-	c.read = enc.NewDecoder().Reader(c.readNoEnc)
+	if c.readSource != nil {
+		c.readSource.stale.Store(true)
+		c.readNoEnc.changed.Store(true)
+	}
+	c.readSource = &decoderSource{reader: c.readNoEnc}
+	c.read = enc.NewDecoder().Reader(c.readSource)
+}
+
+// errStaleDecoder ends a Read through a decoder that has been replaced.
+var errStaleDecoder = errors.New("telnet: read encoding changed")
+
+// decoderSource feeds the reader to a decoder until the encoding changes,
+// which a decoder reading only negotiation would not otherwise notice.
+type decoderSource struct {
+	*reader
+	stale atomic.Bool
+}
+
+func (s *decoderSource) Read(p []byte) (n int, err error) {
+	if s.stale.Load() {
+		return 0, errStaleDecoder
+	}
+	n, err = s.reader.Read(p)
+	if n == 0 && err == nil && s.stale.Load() {
+		err = errStaleDecoder
+	}
+	return
 }
 
 func (c *conn) SetWriteEncoding(enc encoding.Encoding) {
@@ -148,27 +214,59 @@ func (c *conn) Write(p []byte) (n int, err error) {
 }
 
 type reader struct {
-	in  io.Reader
-	ctx context.Context
+	in      io.Reader
+	ctx     context.Context
+	onError func(event.Event, error) bool
 
 	cmd    byte
 	ds     decodeState
 	eof    bool
+	err    error
 	sbdata []byte
+
+	// changed is set when a negotiation replaces the decoder, so that what
+	// was read after it is kept in pending for the new one.
+	changed atomic.Bool
+	pending []byte
+}
+
+func (r *reader) dispatch(ev event.Event) {
+	if err := dispatch(r.ctx, ev); err != nil && r.err == nil && r.onError(ev, err) {
+		r.err = err
+	}
 }
 
 func (r *reader) Read(p []byte) (n int, err error) {
-	if r.eof {
-		return 0, io.EOF
+	if r.err != nil {
+		return 0, r.err
 	}
 	if len(p) == 0 {
 		return 0, nil
 	}
 
-	buf := make([]byte, len(p))
-	nr, err := r.in.Read(buf)
-	buf = buf[:nr]
+	var buf []byte
+	if len(r.pending) > 0 {
+		k := min(len(p), len(r.pending))
+		buf, r.pending = r.pending[:k], r.pending[k:]
+	} else {
+		if r.eof {
+			return 0, io.EOF
+		}
+		buf = make([]byte, len(p))
+		var nr int
+		nr, err = r.in.Read(buf)
+		buf = buf[:nr]
+		if nr > 0 && listening(r.ctx, EventReceive) {
+			r.dispatch(TopicReceive.Event(bytes.Clone(buf)))
+		}
+		if err == io.EOF {
+			r.eof = true
+			err = nil
+		}
+	}
 
+	// Only a negotiation in what is being read here can leave anything over.
+	r.changed.Store(false)
 	copy := func() {
 		p[n] = buf[0]
 		n++
@@ -200,10 +298,10 @@ func (r *reader) Read(p []byte) (n int, err error) {
 			case DO, DONT, WILL, WONT:
 				r.ds = decodeOptionNegotation
 			case EOR:
-				dispatch(r.ctx, event.Event{Name: EventEndOfRecord})
+				r.dispatch(TopicEndOfRecord.Event(struct{}{}))
 				r.ds = decodeByte
 			case GA:
-				dispatch(r.ctx, event.Event{Name: EventGoAhead})
+				r.dispatch(TopicGoAhead.Event(struct{}{}))
 				r.ds = decodeByte
 			case SB:
 				r.ds = decodeSB
@@ -215,7 +313,7 @@ func (r *reader) Read(p []byte) (n int, err error) {
 				r.ds = decodeByte
 			}
 		case decodeOptionNegotation:
-			dispatch(r.ctx, event.Event{Name: EventNegotation, Data: Negotiation{Cmd: r.cmd, Opt: buf[0]}})
+			r.dispatch(TopicNegotiation.Event(Negotiation{Cmd: r.cmd, Opt: buf[0]}))
 			r.ds = decodeByte
 		case decodeSB:
 			switch buf[0] {
@@ -230,18 +328,23 @@ func (r *reader) Read(p []byte) (n int, err error) {
 				r.sbdata = append(r.sbdata, IAC)
 				r.ds = decodeSB
 			case SE:
-				dispatch(r.ctx, event.Event{Name: EventSubnegotiation, Data: Subnegotiation{
-					Opt:  r.sbdata[0],
-					Data: r.sbdata[1:],
-				}})
+				if len(r.sbdata) > 0 {
+					r.dispatch(TopicSubnegotiation.Event(Subnegotiation{
+						Opt:  r.sbdata[0],
+						Data: r.sbdata[1:],
+					}))
+				}
 				r.ds = decodeByte
 			}
 		}
 		buf = buf[1:]
-	}
-	if err == io.EOF {
-		r.eof = true
-		err = nil
+		if r.err != nil {
+			return n, r.err
+		}
+		if r.changed.Swap(false) {
+			r.pending = append(bytes.Clone(buf), r.pending...)
+			return n, err
+		}
 	}
 	return
 }
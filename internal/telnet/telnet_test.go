@@ -222,3 +222,59 @@ func TestLargeWrite(t *testing.T) {
 	require.Equal(t, len(data), n)
 	require.Equal(t, data, output.Bytes())
 }
+
+func TestReceive(t *testing.T) {
+	var in bytes.Buffer
+	telnet := wrap(context.Background(), &mockConn{Reader: &in, Writer: io.Discard})
+	var received [][]byte
+	l := TopicReceive.Subscribe(telnet.Dispatcher, func(_ context.Context, data []byte) error {
+		received = append(received, data)
+		return nil
+	})
+	buf := make([]byte, bufsize)
+	in.Write([]byte{'a', IAC, GA, 'b'})
+	n, err := telnet.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "ab", string(buf[:n]))
+	require.Equal(t, [][]byte{{'a', IAC, GA, 'b'}}, received)
+
+	telnet.Dispatcher.RemoveListener(EventReceive, l)
+	in.Write([]byte("cd"))
+	n, err = telnet.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "cd", string(buf[:n]))
+	require.Len(t, received, 1)
+}
+
+func TestReadListenerErrors(t *testing.T) {
+	boom := errors.New("boom")
+	fatal := func(context.Context, event.Event, error) bool { return true }
+	var tests = []struct {
+		listener event.ListenerFunc
+		handler  ErrorHandler
+		expected string
+		isFatal  func(error) bool
+	}{
+		{func(context.Context, event.Event) error { return boom }, DefaultErrorHandler, "abcd", nil},
+		{func(context.Context, event.Event) error { panic("oops") }, DefaultErrorHandler, "ab", event.IsPanic},
+		{func(context.Context, event.Event) error { return boom }, fatal, "ab", func(err error) bool {
+			return errors.Is(err, boom)
+		}},
+	}
+	for i, test := range tests {
+		tcp := &mockConn{Reader: bytes.NewReader([]byte{'a', 'b', IAC, GA, 'c', 'd'}), Writer: io.Discard}
+		telnet := wrap(context.Background(), tcp)
+		telnet.SetErrorHandler(test.handler)
+		telnet.Dispatcher.ListenFunc(EventGoAhead, test.listener)
+		buf := make([]byte, bufsize)
+		n, err := telnet.Read(buf)
+		require.Equal(t, test.expected, string(buf[:n]), i)
+		if test.isFatal == nil {
+			require.NoError(t, err, i)
+			continue
+		}
+		require.True(t, test.isFatal(err), i)
+		_, err = telnet.Read(buf)
+		require.True(t, test.isFatal(err), i)
+	}
+}
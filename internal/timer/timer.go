@@ -0,0 +1,95 @@
+// Package timer runs commands on a schedule.
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Timer sends Command on Schedule, which is either a five-field cron
+// expression such as "0 9 * * *", a descriptor such as "@daily", or an
+// interval such as "@every 5m". If Script is set, Command is a script to run
+// instead.
+type Timer struct {
+	ID       int64
+	Upstream string
+	Schedule string
+	Command  string
+	Enabled  bool
+	Script   bool
+}
+
+func Validate(t Timer) error {
+	if strings.TrimSpace(t.Command) == "" {
+		return errors.New("timer command is empty")
+	}
+	_, err := parse(t.Schedule)
+	return err
+}
+
+func parse(schedule string) (cron.Schedule, error) {
+	s, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("timer schedule %q: %w", schedule, err)
+	}
+	return s, nil
+}
+
+// Scheduler runs the enabled timers for one upstream until it is stopped.
+type Scheduler struct {
+	cron    *cron.Cron
+	entries map[int64]cron.EntryID
+}
+
+func NewScheduler(timers []Timer, run func(Timer)) (*Scheduler, error) {
+	s := &Scheduler{
+		cron:    cron.New(),
+		entries: make(map[int64]cron.EntryID),
+	}
+	for _, t := range timers {
+		if !t.Enabled {
+			continue
+		}
+		schedule, err := parse(t.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		s.entries[t.ID] = s.cron.Schedule(schedule, cron.FuncJob(func() { run(t) }))
+	}
+	return s, nil
+}
+
+func (s *Scheduler) Start() {
+	if s != nil {
+		s.cron.Start()
+	}
+}
+
+// Stop stops the timers without waiting for any that are running.
+func (s *Scheduler) Stop() {
+	if s != nil {
+		s.cron.Stop()
+	}
+}
+
+// Next returns when the timer with the given ID will next run, if it is
+// scheduled.
+func (s *Scheduler) Next(id int64) (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+	entry, found := s.entries[id]
+	if !found {
+		return time.Time{}, false
+	}
+	e := s.cron.Entry(entry)
+	if e.Next.IsZero() && e.Schedule != nil {
+		// The scheduler has not worked out the first run yet.
+		return e.Schedule.Next(time.Now()), true
+	}
+	return e.Next, !e.Next.IsZero()
+}
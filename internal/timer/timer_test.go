@@ -0,0 +1,52 @@
+package timer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(Timer{Schedule: "@every 5m", Command: "think"}))
+	require.NoError(t, Validate(Timer{Schedule: "0 9 * * *", Command: "+vote"}))
+	require.NoError(t, Validate(Timer{Schedule: "@hourly", Command: "who"}))
+	require.Error(t, Validate(Timer{Schedule: "every five minutes", Command: "think"}))
+	require.Error(t, Validate(Timer{Schedule: "0 9 * *", Command: "+vote"}))
+	require.Error(t, Validate(Timer{Schedule: "@every 5m", Command: " "}))
+}
+
+func TestScheduler(t *testing.T) {
+	ran := make(chan Timer, 1)
+	s, err := NewScheduler([]Timer{
+		{ID: 1, Schedule: "@every 1s", Command: "think", Enabled: true},
+		{ID: 2, Schedule: "@every 10ms", Command: "sleep", Enabled: false},
+		{ID: 3, Schedule: "0 9 * * *", Command: "+vote", Enabled: true},
+	}, func(t Timer) {
+		select {
+		case ran <- t:
+		default:
+		}
+	})
+	require.NoError(t, err)
+	s.Start()
+	defer s.Stop()
+
+	select {
+	case timer := <-ran:
+		require.Equal(t, "think", timer.Command)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timer did not run")
+	}
+
+	next, ok := s.Next(3)
+	require.True(t, ok)
+	require.Equal(t, 9, next.Hour())
+	_, ok = s.Next(2)
+	require.False(t, ok)
+}
+
+func TestSchedulerError(t *testing.T) {
+	_, err := NewScheduler([]Timer{{ID: 1, Schedule: "bogus", Command: "x", Enabled: true}}, func(Timer) {})
+	require.Error(t, err)
+}
@@ -0,0 +1,204 @@
+// Package trigger matches lines of upstream output against regular
+// expressions and works out what should happen to them.
+package trigger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stesla/iris/internal/logs"
+)
+
+type Action string
+
+const (
+	// ActionSend sends the argument upstream as a command.
+	ActionSend Action = "send"
+	// ActionGag hides the line from downstreams and logs.
+	ActionGag Action = "gag"
+	// ActionHighlight wraps the line in the ANSI style named by the argument.
+	ActionHighlight Action = "highlight"
+	// ActionNotify raises a notification with the argument, or the line.
+	ActionNotify Action = "notify"
+	// ActionScript runs the argument as a script.
+	ActionScript Action = "script"
+)
+
+func ParseAction(s string) (Action, error) {
+	switch a := Action(s); a {
+	case ActionSend, ActionGag, ActionHighlight, ActionNotify, ActionScript:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown trigger action: %q", s)
+}
+
+// Trigger fires its action for each line that Pattern matches. Arguments can
+// refer to capture groups as $1 or ${name}, and to the whole match as $0,
+// except for scripts, which are given the groups instead.
+type Trigger struct {
+	ID       int64
+	Pattern  string
+	Action   Action
+	Argument string
+}
+
+type compiled struct {
+	Trigger
+	re    *regexp.Regexp
+	style string
+}
+
+// Set is a compiled list of triggers, which are tried in order.
+type Set struct {
+	triggers []compiled
+}
+
+// Len returns how many triggers are in the set.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.triggers)
+}
+
+func Compile(triggers []Trigger) (*Set, error) {
+	s := &Set{triggers: make([]compiled, 0, len(triggers))}
+	for _, t := range triggers {
+		c, err := compile(t)
+		if err != nil {
+			return nil, err
+		}
+		s.triggers = append(s.triggers, c)
+	}
+	return s, nil
+}
+
+func compile(t Trigger) (c compiled, err error) {
+	c.Trigger = t
+	if _, err = ParseAction(string(t.Action)); err != nil {
+		return
+	}
+	if c.re, err = regexp.Compile(t.Pattern); err != nil {
+		return c, fmt.Errorf("trigger pattern %q: %w", t.Pattern, err)
+	}
+	if t.Action == ActionHighlight {
+		c.style, err = Style(t.Argument)
+	}
+	return
+}
+
+// Validate reports whether t would compile.
+func Validate(t Trigger) error {
+	_, err := compile(t)
+	return err
+}
+
+// Result is what the triggers matching a line asked for.
+type Result struct {
+	// Line is the line to pass on, which may have been highlighted.
+	Line    string
+	Gag     bool
+	Send    []string
+	Notify  []string
+	Scripts []Script
+}
+
+// Script is a script to run for a matching line.
+type Script struct {
+	TriggerID int64
+	Source    string
+	// Match holds the whole match and then each group, which is empty if it
+	// did not take part in the match.
+	Match []string
+}
+
+// Apply runs every trigger against line, which may end in a newline. Patterns
+// are matched against the text without ANSI escape codes.
+func (s *Set) Apply(line string) Result {
+	result := Result{Line: line}
+	if s == nil {
+		return result
+	}
+	text, eol := splitEOL(line)
+	plain := logs.StripANSI(text)
+	for _, t := range s.triggers {
+		m := t.re.FindStringSubmatchIndex(plain)
+		if m == nil {
+			continue
+		}
+		expand := func(template string) string {
+			return string(t.re.ExpandString(nil, template, plain, m))
+		}
+		switch t.Action {
+		case ActionSend:
+			result.Send = append(result.Send, expand(t.Argument))
+		case ActionGag:
+			result.Gag = true
+		case ActionHighlight:
+			text = t.style + text + reset
+		case ActionScript:
+			result.Scripts = append(result.Scripts, Script{
+				TriggerID: t.ID,
+				Source:    t.Argument,
+				Match:     t.re.FindStringSubmatch(plain),
+			})
+		case ActionNotify:
+			if t.Argument == "" {
+				result.Notify = append(result.Notify, plain)
+			} else {
+				result.Notify = append(result.Notify, expand(t.Argument))
+			}
+		}
+	}
+	result.Line = text + eol
+	return result
+}
+
+func splitEOL(line string) (text, eol string) {
+	text = strings.TrimRight(line, "\r\n")
+	return text, line[len(text):]
+}
+
+const reset = "\x1b[0m"
+
+var styles = map[string]int{
+	"bold":      1,
+	"dim":       2,
+	"italic":    3,
+	"underline": 4,
+	"blink":     5,
+	"reverse":   7,
+	"black":     30,
+	"red":       31,
+	"green":     32,
+	"yellow":    33,
+	"blue":      34,
+	"magenta":   35,
+	"cyan":      36,
+	"white":     37,
+}
+
+// Style returns the ANSI escape code for a space-separated list of
+// attributes and colors, such as "bold red" or "white on blue". An empty
+// style is bold.
+func Style(s string) (string, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		fields = []string{"bold"}
+	}
+	var codes []string
+	for i := 0; i < len(fields); i++ {
+		name, offset := fields[i], 0
+		if name == "on" && i+1 < len(fields) {
+			i++
+			name, offset = fields[i], 10
+		}
+		code, ok := styles[name]
+		if !ok || (offset > 0 && code < 30) {
+			return "", fmt.Errorf("unknown highlight style: %q", s)
+		}
+		codes = append(codes, fmt.Sprint(code+offset))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m", nil
+}
@@ -0,0 +1,82 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	set, err := Compile([]Trigger{
+		{Pattern: `^(\w+) pages: (.*)$`, Action: ActionNotify, Argument: "page from $1"},
+		{Pattern: `^(?P<who>\w+) pages: `, Action: ActionSend, Argument: "page ${who}=I'm away"},
+		{Pattern: `idle check`, Action: ActionSend, Argument: "look"},
+		{Pattern: `^Spam`, Action: ActionGag},
+		{Pattern: `danger`, Action: ActionHighlight, Argument: "bold red"},
+		{Pattern: `^GAME:`, Action: ActionNotify},
+		{ID: 7, Pattern: `^(\w+) has arrived\.$`, Action: ActionScript, Argument: "send('wave ' + match[1])"},
+	})
+	require.NoError(t, err)
+
+	var tests = []struct {
+		line     string
+		expected Result
+	}{
+		{"hello\r\n", Result{Line: "hello\r\n"}},
+		{"Bob pages: hi\n", Result{
+			Line:   "Bob pages: hi\n",
+			Send:   []string{"page Bob=I'm away"},
+			Notify: []string{"page from Bob"},
+		}},
+		{"\x1b[1mBob\x1b[0m pages: hi", Result{
+			Line:   "\x1b[1mBob\x1b[0m pages: hi",
+			Send:   []string{"page Bob=I'm away"},
+			Notify: []string{"page from Bob"},
+		}},
+		{"This is an idle check.\n", Result{Line: "This is an idle check.\n", Send: []string{"look"}}},
+		{"Spam, spam, spam\n", Result{Line: "Spam, spam, spam\n", Gag: true}},
+		{"There is danger here.\r\n", Result{Line: "\x1b[1;31mThere is danger here.\x1b[0m\r\n"}},
+		{"GAME: Rebooting\n", Result{Line: "GAME: Rebooting\n", Notify: []string{"GAME: Rebooting"}}},
+		{"Bob has arrived.\n", Result{Line: "Bob has arrived.\n", Scripts: []Script{{
+			TriggerID: 7,
+			Source:    "send('wave ' + match[1])",
+			Match:     []string{"Bob has arrived.", "Bob"},
+		}}}},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, set.Apply(test.line), test.line)
+	}
+}
+
+func TestNilSet(t *testing.T) {
+	var set *Set
+	require.Equal(t, Result{Line: "hello\n"}, set.Apply("hello\n"))
+}
+
+func TestCompileErrors(t *testing.T) {
+	var tests = []Trigger{
+		{Pattern: `(`, Action: ActionGag},
+		{Pattern: `foo`, Action: "explode"},
+		{Pattern: `foo`, Action: ActionHighlight, Argument: "sparkly"},
+		{Pattern: `foo`, Action: ActionHighlight, Argument: "on bold"},
+	}
+	for _, test := range tests {
+		require.Error(t, Validate(test), test)
+	}
+}
+
+func TestStyle(t *testing.T) {
+	var tests = []struct {
+		style, expected string
+	}{
+		{"", "\x1b[1m"},
+		{"red", "\x1b[31m"},
+		{"bold underline green", "\x1b[1;4;32m"},
+		{"white on blue", "\x1b[37;44m"},
+	}
+	for _, test := range tests {
+		style, err := Style(test.style)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, style, test.style)
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"os"
+
 	"github.com/stesla/iris/cmd"
 )
 
 func main() {
-	cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
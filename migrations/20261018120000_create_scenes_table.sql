@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE scenes (
+       id         INTEGER PRIMARY KEY,
+       upstream   TEXT NOT NULL,
+       name       TEXT NOT NULL,
+       started_at DATETIME NOT NULL,
+       stopped_at DATETIME
+);
+
+CREATE INDEX scenes_upstream_name ON scenes (upstream, name);
+
+-- +goose Down
+DROP TABLE scenes;
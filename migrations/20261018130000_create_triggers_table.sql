@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE triggers (
+       id         INTEGER PRIMARY KEY,
+       upstream   TEXT NOT NULL,
+       pattern    TEXT NOT NULL,
+       action     TEXT NOT NULL,
+       argument   TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX triggers_upstream ON triggers (upstream);
+
+-- +goose Down
+DROP TABLE triggers;
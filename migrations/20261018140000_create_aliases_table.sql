@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE aliases (
+       id         INTEGER PRIMARY KEY,
+       upstream   TEXT NOT NULL DEFAULT '',
+       name       TEXT NOT NULL,
+       expansion  TEXT NOT NULL,
+       UNIQUE (upstream, name)
+);
+
+-- +goose Down
+DROP TABLE aliases;
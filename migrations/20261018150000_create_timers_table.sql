@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE timers (
+       id         INTEGER PRIMARY KEY,
+       upstream   TEXT NOT NULL,
+       schedule   TEXT NOT NULL,
+       command    TEXT NOT NULL,
+       enabled    BOOLEAN NOT NULL DEFAULT 1
+);
+
+CREATE INDEX timers_upstream ON timers (upstream);
+
+-- +goose Down
+DROP TABLE timers;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE upstreams ADD COLUMN script_type TEXT NOT NULL DEFAULT 'text';
+ALTER TABLE timers ADD COLUMN script BOOLEAN NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE timers DROP COLUMN script;
+ALTER TABLE upstreams DROP COLUMN script_type;
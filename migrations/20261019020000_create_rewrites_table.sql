@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE rewrites (
+       id         INTEGER PRIMARY KEY,
+       upstream   TEXT NOT NULL,
+       pattern    TEXT NOT NULL,
+       action     TEXT NOT NULL,
+       argument   TEXT NOT NULL DEFAULT '',
+       log        BOOLEAN NOT NULL DEFAULT 0
+);
+
+CREATE INDEX rewrites_upstream ON rewrites (upstream);
+
+-- +goose Down
+DROP TABLE rewrites;
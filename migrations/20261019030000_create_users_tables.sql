@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE users (
+       id         INTEGER PRIMARY KEY,
+       name       TEXT NOT NULL UNIQUE,
+       bcrypt     TEXT NOT NULL,
+       created_at DATETIME NOT NULL
+);
+
+CREATE TABLE grants (
+       user_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+       upstream   TEXT NOT NULL,
+       role       TEXT NOT NULL,
+       granted_at DATETIME NOT NULL,
+       PRIMARY KEY (user_id, upstream)
+);
+
+-- +goose Down
+DROP TABLE grants;
+DROP TABLE users;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE tokens (
+       id         INTEGER PRIMARY KEY,
+       name       TEXT NOT NULL UNIQUE,
+       hash       TEXT NOT NULL UNIQUE,
+       created_at DATETIME NOT NULL
+);
+
+-- +goose Down
+DROP TABLE tokens;
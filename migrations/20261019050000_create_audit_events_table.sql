@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE audit_events (
+       id       INTEGER PRIMARY KEY,
+       time     DATETIME NOT NULL,
+       kind     TEXT NOT NULL,
+       user     TEXT NOT NULL DEFAULT '',
+       remote   TEXT NOT NULL DEFAULT '',
+       upstream TEXT NOT NULL DEFAULT '',
+       detail   TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX audit_events_time ON audit_events (time);
+
+-- +goose Down
+DROP TABLE audit_events;
@@ -0,0 +1,5 @@
+-- +goose Up
+-- Renaming an upstream used to leave its grants behind under the old name.
+DELETE FROM grants WHERE upstream NOT IN (SELECT name FROM upstreams);
+
+-- +goose Down